@@ -0,0 +1,36 @@
+// Command rotatekeys re-encrypts the stored exchange credentials of every user under the current master key.
+//
+// To rotate, add the new key with a higher version to CREDENTIALS_MASTER_KEYS (or the key file) next to the
+// old one, restart the server, then run this command. Once it reports success the old key can be removed.
+package main
+
+import (
+	"log"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/config"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/database"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/repository"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/secrets"
+)
+
+func main() {
+	cfg, err := config.NewConfig()
+	if err != nil {
+		log.Fatalf("Error loading config: %v", err)
+	}
+	keyring, err := secrets.NewKeyring(cfg.CredentialKeys)
+	if err != nil {
+		log.Fatalf("Error loading credential master keys: %v", err)
+	}
+	db, err := database.NewDatabase(cfg)
+	if err != nil {
+		log.Fatalf("Error connecting to database: %v", err)
+	}
+	defer db.Close()
+
+	n, err := repository.NewUserRepository(db.DB, keyring).ReencryptCredentials()
+	if err != nil {
+		log.Fatalf("Error re-encrypting credentials: %v", err)
+	}
+	log.Printf("Re-encrypted credentials of %d users under key version %d", n, keyring.CurrentVersion())
+}
@@ -1,26 +1,56 @@
 package config
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/secrets"
 )
 
+// WorkerTimeframes are the timeframes every analysis worker evaluates, and that the consensus can weigh.
+var WorkerTimeframes = []string{"1m", "5m", "1d"}
+
 // Config holds all configuration for the application
 type Config struct {
 	AlphaVantageAPIKey string
-	BinanceAPIKey      string
-	BinanceSecret      string
 	Port               string
 	JWTSecret          string
+	AdminUsernames     []string // Users allowed to use the admin routes
+	// Master keys by version for encrypting exchange credentials; the highest version encrypts new values
+	CredentialKeys map[int][]byte
 	// Database configuration
 	DBHost     string
 	DBPort     string
 	DBUser     string
 	DBPassword string
 	DBName     string
-	// Add more exchange keys as needed, e.g., CoinbaseAPIKey, etc.
+	// Paper trading configuration
+	PaperInitialBalances map[string]float64
+	PaperFeeRate         float64
+	PaperSlippageRate    float64
+	// Automated trading configuration
+	AutoTradeMinConfidence     float64       // Minimum prediction confidence that triggers an order
+	AutoTradeRiskPercent       float64       // Default percentage of the quote balance risked per trade
+	AutoTradeStopLossPercent   float64       // Stop loss distance from the entry price
+	AutoTradeTakeProfitPercent float64       // Take profit distance from the entry price
+	AutoTradeCooldown          time.Duration // Minimum time before the same direction is traded again on a symbol
+	AutoTradeProtectiveOCO     bool          // Attach a take profit / stop loss OCO to every position opened
+	AutoTradeStopLimitPercent  float64       // Distance of the stop leg's limit price below its stop price
+	AutoTradeMaxDuration       time.Duration // Open trades are closed at market once they are this old; 0 keeps them open
+	AutoTradeRequireConsensus  bool          // Only execute predictions that agree with the multi-timeframe consensus
+	AutoTradeMinProbability    float64       // Minimum calibrated probability of calibrated predictions that triggers an order; 0 disables it
+	// Multi-timeframe consensus configuration
+	ConsensusWeights        map[string]float64 // Weight of each worker timeframe in the consensus
+	ConsensusTrendTimeframe string             // Timeframe whose trend a consensus signal must follow; empty for none
+	// Signals that hit neither their take profit nor their stop loss within this time are evaluated as expired
+	SignalEvaluationHorizon time.Duration
+	// Evaluated signals needed to fit a confidence calibration
+	CalibrationMinSamples int
 }
 
 // NewConfig creates a new Config struct from environment variables.
@@ -35,15 +65,6 @@ func NewConfig() (*Config, error) {
 		log.Println("WARNING: ALPHA_VANTAGE_API_KEY is not set. The application might not work correctly.")
 	}
 
-	binanceAPIKey := os.Getenv("BINANCE_API_KEY")
-	binanceSecret := os.Getenv("BINANCE_SECRET")
-	if binanceAPIKey == "" || binanceSecret == "" {
-		// Use sample test keys for demonstration (replace with real keys for production)
-		log.Println("WARNING: Using sample Binance test keys. Replace with real API keys for production use.")
-		binanceAPIKey = "test_api_key"    // Sample key - replace with real
-		binanceSecret = "test_secret_key" // Sample secret - replace with real
-	}
-
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "3000"
@@ -54,6 +75,13 @@ func NewConfig() (*Config, error) {
 		jwtSecret = "your-secret-key"
 	}
 
+	var adminUsernames []string
+	for _, name := range strings.Split(os.Getenv("ADMIN_USERNAMES"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			adminUsernames = append(adminUsernames, name)
+		}
+	}
+
 	dbHost := os.Getenv("DB_HOST")
 	if dbHost == "" {
 		dbHost = "localhost"
@@ -79,16 +107,212 @@ func NewConfig() (*Config, error) {
 		dbName = "forexbot"
 	}
 
+	paperBalances := os.Getenv("PAPER_INITIAL_BALANCES")
+	if paperBalances == "" {
+		paperBalances = "USDT:10000,BTC:0.1,ETH:1"
+	}
+
+	paperFeeRate, err := strconv.ParseFloat(getEnvDefault("PAPER_FEE_RATE", "0.001"), 64)
+	if err != nil {
+		return nil, err
+	}
+
+	paperSlippageRate, err := strconv.ParseFloat(getEnvDefault("PAPER_SLIPPAGE_RATE", "0.0005"), 64)
+	if err != nil {
+		return nil, err
+	}
+
+	paperInitialBalances, err := parseBalances(paperBalances)
+	if err != nil {
+		return nil, err
+	}
+
+	credentialKeys, err := loadCredentialKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	autoTradeMinConfidence, err := strconv.ParseFloat(getEnvDefault("AUTO_TRADE_MIN_CONFIDENCE", "0.75"), 64)
+	if err != nil {
+		return nil, err
+	}
+
+	autoTradeRiskPercent, err := strconv.ParseFloat(getEnvDefault("AUTO_TRADE_RISK_PERCENT", "1"), 64)
+	if err != nil {
+		return nil, err
+	}
+
+	autoTradeStopLossPercent, err := strconv.ParseFloat(getEnvDefault("AUTO_TRADE_STOP_LOSS_PERCENT", "2"), 64)
+	if err != nil {
+		return nil, err
+	}
+
+	autoTradeTakeProfitPercent, err := strconv.ParseFloat(getEnvDefault("AUTO_TRADE_TAKE_PROFIT_PERCENT", "4"), 64)
+	if err != nil {
+		return nil, err
+	}
+
+	autoTradeCooldown, err := time.ParseDuration(getEnvDefault("AUTO_TRADE_COOLDOWN", "1h"))
+	if err != nil {
+		return nil, err
+	}
+
+	autoTradeMaxDuration, err := time.ParseDuration(getEnvDefault("AUTO_TRADE_MAX_DURATION", "24h"))
+	if err != nil {
+		return nil, err
+	}
+
+	autoTradeProtectiveOCO, err := strconv.ParseBool(getEnvDefault("AUTO_TRADE_PROTECTIVE_OCO", "true"))
+	if err != nil {
+		return nil, err
+	}
+
+	autoTradeStopLimitPercent, err := strconv.ParseFloat(getEnvDefault("AUTO_TRADE_STOP_LIMIT_PERCENT", "0.5"), 64)
+	if err != nil {
+		return nil, err
+	}
+
+	autoTradeRequireConsensus, err := strconv.ParseBool(getEnvDefault("AUTO_TRADE_REQUIRE_CONSENSUS", "false"))
+	if err != nil {
+		return nil, err
+	}
+
+	consensusWeights, err := parseWeights(getEnvDefault("CONSENSUS_TIMEFRAME_WEIGHTS", "1m:1,5m:2,1d:3"))
+	if err != nil {
+		return nil, err
+	}
+
+	consensusTrendTimeframe := getEnvDefault("CONSENSUS_TREND_TIMEFRAME", "1d")
+	if consensusTrendTimeframe == "none" {
+		consensusTrendTimeframe = ""
+	}
+	if consensusTrendTimeframe != "" && !isWorkerTimeframe(consensusTrendTimeframe) {
+		return nil, fmt.Errorf("CONSENSUS_TREND_TIMEFRAME %q is not a worker timeframe (%s) or none",
+			consensusTrendTimeframe, strings.Join(WorkerTimeframes, ", "))
+	}
+
+	signalEvaluationHorizon, err := time.ParseDuration(getEnvDefault("SIGNAL_EVALUATION_HORIZON", "24h"))
+	if err != nil {
+		return nil, err
+	}
+
+	autoTradeMinProbability, err := strconv.ParseFloat(getEnvDefault("AUTO_TRADE_MIN_PROBABILITY", "0"), 64)
+	if err != nil {
+		return nil, err
+	}
+
+	calibrationMinSamples, err := strconv.Atoi(getEnvDefault("CALIBRATION_MIN_SAMPLES", "30"))
+	if err != nil {
+		return nil, err
+	}
+
 	return &Config{
 		AlphaVantageAPIKey: apiKey,
-		BinanceAPIKey:      binanceAPIKey,
-		BinanceSecret:      binanceSecret,
 		Port:               port,
 		JWTSecret:          jwtSecret,
+		AdminUsernames:     adminUsernames,
+		CredentialKeys:     credentialKeys,
 		DBHost:             dbHost,
 		DBPort:             dbPort,
 		DBUser:             dbUser,
 		DBPassword:         dbPassword,
 		DBName:             dbName,
+
+		PaperInitialBalances: paperInitialBalances,
+		PaperFeeRate:         paperFeeRate,
+		PaperSlippageRate:    paperSlippageRate,
+
+		AutoTradeMinConfidence:     autoTradeMinConfidence,
+		AutoTradeRiskPercent:       autoTradeRiskPercent,
+		AutoTradeStopLossPercent:   autoTradeStopLossPercent,
+		AutoTradeTakeProfitPercent: autoTradeTakeProfitPercent,
+		AutoTradeCooldown:          autoTradeCooldown,
+		AutoTradeProtectiveOCO:     autoTradeProtectiveOCO,
+		AutoTradeStopLimitPercent:  autoTradeStopLimitPercent,
+		AutoTradeMaxDuration:       autoTradeMaxDuration,
+		AutoTradeRequireConsensus:  autoTradeRequireConsensus,
+		AutoTradeMinProbability:    autoTradeMinProbability,
+
+		ConsensusWeights:        consensusWeights,
+		ConsensusTrendTimeframe: consensusTrendTimeframe,
+
+		SignalEvaluationHorizon: signalEvaluationHorizon,
+		CalibrationMinSamples:   calibrationMinSamples,
 	}, nil
 }
+
+// getEnvDefault returns the value of the environment variable key, or def if it is unset.
+func getEnvDefault(key, def string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return def
+}
+
+// loadCredentialKeys reads the credential master keys from CREDENTIALS_MASTER_KEYS, or from the file
+// named by CREDENTIALS_MASTER_KEY_FILE with one VERSION:BASE64KEY entry per line.
+func loadCredentialKeys() (map[int][]byte, error) {
+	spec := os.Getenv("CREDENTIALS_MASTER_KEYS")
+	if path := os.Getenv("CREDENTIALS_MASTER_KEY_FILE"); spec == "" && path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read credential key file: %w", err)
+		}
+		spec = string(data)
+	}
+	keys, err := secrets.ParseKeys(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid credential master keys: %w", err)
+	}
+	if len(keys) == 0 {
+		log.Println("WARNING: CREDENTIALS_MASTER_KEYS is not set. Exchange keys cannot be saved until a master key is configured.")
+	}
+	return keys, nil
+}
+
+// parseBalances parses a list of balances in the form "USDT:10000,BTC:0.1".
+func parseBalances(s string) (map[string]float64, error) {
+	balances := make(map[string]float64)
+	for _, entry := range strings.Split(s, ",") {
+		asset, amount, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid balance entry %q, expected ASSET:AMOUNT", entry)
+		}
+		value, err := strconv.ParseFloat(amount, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid amount for %s: %w", asset, err)
+		}
+		balances[strings.ToUpper(asset)] = value
+	}
+	return balances, nil
+}
+
+// parseWeights parses a list of timeframe weights in the form "1m:1,5m:2,1d:3".
+func parseWeights(s string) (map[string]float64, error) {
+	weights := make(map[string]float64)
+	for _, entry := range strings.Split(s, ",") {
+		timeframe, weight, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid weight entry %q, expected TIMEFRAME:WEIGHT", entry)
+		}
+		if !isWorkerTimeframe(timeframe) {
+			return nil, fmt.Errorf("invalid weight entry %q, %s is not a worker timeframe (%s)", entry, timeframe, strings.Join(WorkerTimeframes, ", "))
+		}
+		value, err := strconv.ParseFloat(weight, 64)
+		if err != nil || value < 0 {
+			return nil, fmt.Errorf("invalid weight for %s: %q", timeframe, weight)
+		}
+		weights[timeframe] = value
+	}
+	return weights, nil
+}
+
+// isWorkerTimeframe reports whether the analysis workers evaluate timeframe
+func isWorkerTimeframe(timeframe string) bool {
+	for _, tf := range WorkerTimeframes {
+		if tf == timeframe {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,17 @@
+-- Create candles table
+CREATE TABLE IF NOT EXISTS candles (
+    symbol VARCHAR(50) NOT NULL,
+    interval VARCHAR(10) NOT NULL,
+    open_time TIMESTAMP NOT NULL,
+    close_time TIMESTAMP NOT NULL,
+    open DECIMAL(20, 8) NOT NULL,
+    high DECIMAL(20, 8) NOT NULL,
+    low DECIMAL(20, 8) NOT NULL,
+    close DECIMAL(20, 8) NOT NULL,
+    volume DECIMAL(30, 8) NOT NULL DEFAULT 0,
+    trade_count BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (symbol, interval, open_time)
+);
+
+-- The primary key already serves range scans by symbol, interval and open_time
+CREATE INDEX IF NOT EXISTS idx_candles_open_time ON candles(open_time);
@@ -0,0 +1,18 @@
+-- Create bots table
+CREATE TABLE IF NOT EXISTS bots (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    strategy VARCHAR(50) NOT NULL,
+    symbol VARCHAR(50) NOT NULL,
+    exchange VARCHAR(50) NOT NULL,
+    interval VARCHAR(10) NOT NULL,
+    params JSONB,
+    state JSONB,
+    status VARCHAR(20) NOT NULL DEFAULT 'RUNNING', -- RUNNING, STOPPED
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Only one bot may run per user, symbol and exchange
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bots_running ON bots(user_id, symbol, exchange) WHERE status = 'RUNNING';
+CREATE INDEX IF NOT EXISTS idx_bots_status ON bots(status);
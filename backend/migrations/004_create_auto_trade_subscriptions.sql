@@ -0,0 +1,12 @@
+-- Create auto trade subscriptions table
+CREATE TABLE IF NOT EXISTS auto_trade_subscriptions (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    symbol VARCHAR(50) NOT NULL,
+    exchange VARCHAR(50) NOT NULL,
+    risk_percent DECIMAL(5, 2) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, symbol)
+);
+
+CREATE INDEX IF NOT EXISTS idx_auto_trade_subscriptions_symbol ON auto_trade_subscriptions(symbol);
@@ -0,0 +1,14 @@
+-- Create risk limits table
+-- User ID 0 holds the defaults for users without their own row and the global kill switch
+CREATE TABLE IF NOT EXISTS risk_limits (
+    user_id INTEGER PRIMARY KEY,
+    max_position_size DECIMAL(20, 8) NOT NULL DEFAULT 0,
+    max_total_exposure DECIMAL(20, 8) NOT NULL DEFAULT 0,
+    max_orders_per_minute INTEGER NOT NULL DEFAULT 0,
+    max_daily_loss DECIMAL(20, 8) NOT NULL DEFAULT 0,
+    max_drawdown_percent DECIMAL(5, 2) NOT NULL DEFAULT 0,
+    kill_switch BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO risk_limits (user_id, max_orders_per_minute) VALUES (0, 60) ON CONFLICT (user_id) DO NOTHING;
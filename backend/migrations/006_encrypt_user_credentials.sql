@@ -0,0 +1,7 @@
+-- Exchange credentials are stored encrypted (base64 AES-GCM envelopes), which no longer fit in VARCHAR(255)
+ALTER TABLE users ALTER COLUMN binance_api_key TYPE TEXT;
+ALTER TABLE users ALTER COLUMN binance_secret_key TYPE TEXT;
+ALTER TABLE users ALTER COLUMN solana_private_key TYPE TEXT;
+
+-- Version of the master key the credentials of a row are encrypted under; 0 means legacy plaintext
+ALTER TABLE users ADD COLUMN IF NOT EXISTS key_version INTEGER NOT NULL DEFAULT 0;
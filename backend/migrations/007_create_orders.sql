@@ -0,0 +1,24 @@
+-- Create orders table
+-- Every order placed on an exchange, kept in sync with its status on the exchange
+CREATE TABLE IF NOT EXISTS orders (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    exchange VARCHAR(50) NOT NULL,
+    symbol VARCHAR(50) NOT NULL,
+    side VARCHAR(10) NOT NULL CHECK (side IN ('BUY', 'SELL')),
+    type VARCHAR(30) NOT NULL,
+    client_order_id VARCHAR(64) NOT NULL UNIQUE,
+    exchange_order_id VARCHAR(64) NOT NULL,
+    quantity DECIMAL(20, 8) NOT NULL,
+    price DECIMAL(20, 8) NOT NULL,
+    status VARCHAR(20) NOT NULL, -- NEW, PARTIALLY_FILLED, FILLED, CANCELED, REJECTED, EXPIRED
+    filled_quantity DECIMAL(20, 8) NOT NULL DEFAULT 0,
+    avg_price DECIMAL(20, 8) NOT NULL DEFAULT 0,
+    fee DECIMAL(20, 8) NOT NULL DEFAULT 0,
+    fee_asset VARCHAR(20) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_orders_user_id ON orders(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_orders_open ON orders(user_id, exchange) WHERE status IN ('NEW', 'PARTIALLY_FILLED');
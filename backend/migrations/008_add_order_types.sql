@@ -0,0 +1,7 @@
+-- Add stop, time in force and OCO list columns to orders
+-- Orders can now be market, limit, stop-loss-limit or take-profit-limit, and OCO legs share an order list ID
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS stop_price DECIMAL(20, 8) NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS time_in_force VARCHAR(10) NOT NULL DEFAULT '';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS order_list_id VARCHAR(64) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_orders_list ON orders(order_list_id) WHERE order_list_id <> '';
@@ -0,0 +1,15 @@
+-- Add exit columns to trades
+-- Trades are closed by the position manager, which needs the exchange holding the position,
+-- the protective OCO guarding it, and records the exit price, the fees paid and why the trade closed
+ALTER TABLE trades ADD COLUMN IF NOT EXISTS exchange VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE trades ADD COLUMN IF NOT EXISTS protection_list_id VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE trades ADD COLUMN IF NOT EXISTS exit_price DECIMAL(20, 8) NOT NULL DEFAULT 0;
+ALTER TABLE trades ADD COLUMN IF NOT EXISTS fees DECIMAL(20, 8) NOT NULL DEFAULT 0;
+ALTER TABLE trades ADD COLUMN IF NOT EXISTS close_reason VARCHAR(20) NOT NULL DEFAULT '';
+
+-- Trades opened before this migration were placed on the exchange of the user's auto trade subscription
+UPDATE trades t SET exchange = s.exchange
+FROM auto_trade_subscriptions s
+WHERE t.exchange = '' AND s.user_id = t.user_id AND s.symbol = t.symbol;
+
+CREATE INDEX IF NOT EXISTS idx_trades_open ON trades(user_id) WHERE status = 'OPEN';
@@ -0,0 +1,5 @@
+-- Add exit rules to trades and auto trade subscriptions
+-- Trailing stops, break-even moves and take profit ladders are stored as JSON with the trade, together
+-- with the state they have reached; a subscription's rules are copied to each trade it opens
+ALTER TABLE trades ADD COLUMN IF NOT EXISTS exit_rules JSONB;
+ALTER TABLE auto_trade_subscriptions ADD COLUMN IF NOT EXISTS exit_rules JSONB;
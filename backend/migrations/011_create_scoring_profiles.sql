@@ -0,0 +1,11 @@
+-- Create scoring profiles table
+-- Named sets of weighted indicators, with their parameters and thresholds, that a user scores predictions with
+CREATE TABLE IF NOT EXISTS scoring_profiles (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    indicators JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, name)
+);
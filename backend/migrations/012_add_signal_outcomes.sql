@@ -0,0 +1,16 @@
+-- Add outcome columns to signals
+-- The signal evaluator follows every signal on 1m candles until its take profit or stop loss is hit, or its horizon
+-- passes, and records how it ended so the accuracy of strategies can be compared
+ALTER TABLE signals ADD COLUMN IF NOT EXISTS timeframe VARCHAR(10) NOT NULL DEFAULT '';
+ALTER TABLE signals ADD COLUMN IF NOT EXISTS outcome VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE signals ADD COLUMN IF NOT EXISTS outcome_price DECIMAL(20, 8) NOT NULL DEFAULT 0;
+ALTER TABLE signals ADD COLUMN IF NOT EXISTS outcome_at TIMESTAMP;
+ALTER TABLE signals ADD COLUMN IF NOT EXISTS time_to_outcome INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE signals ADD COLUMN IF NOT EXISTS max_favorable_excursion DECIMAL(12, 4) NOT NULL DEFAULT 0;
+ALTER TABLE signals ADD COLUMN IF NOT EXISTS max_adverse_excursion DECIMAL(12, 4) NOT NULL DEFAULT 0;
+ALTER TABLE signals ADD COLUMN IF NOT EXISTS return_percent DECIMAL(12, 4) NOT NULL DEFAULT 0;
+ALTER TABLE signals ADD COLUMN IF NOT EXISTS r_multiple DECIMAL(12, 4) NOT NULL DEFAULT 0;
+ALTER TABLE signals ADD COLUMN IF NOT EXISTS evaluated_until TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_signals_pending ON signals(created_at) WHERE outcome = '';
+CREATE INDEX IF NOT EXISTS idx_signals_strategy ON signals(strategy, symbol, timeframe);
@@ -0,0 +1,21 @@
+-- Create ML models table
+-- Versions of the models trained to forecast the candles of a symbol and interval, with their walk-forward validation.
+-- At most one version per symbol and interval is active, and that one votes in predictions
+CREATE TABLE IF NOT EXISTS ml_models (
+    id SERIAL PRIMARY KEY,
+    symbol VARCHAR(20) NOT NULL,
+    interval VARCHAR(10) NOT NULL,
+    version INTEGER NOT NULL,
+    kind VARCHAR(30) NOT NULL,
+    horizon INTEGER NOT NULL,
+    parameters JSONB NOT NULL,
+    validation JSONB,
+    trained_from TIMESTAMP NOT NULL,
+    trained_to TIMESTAMP NOT NULL,
+    samples INTEGER NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (symbol, interval, version)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ml_models_active ON ml_models(symbol, interval) WHERE active;
@@ -0,0 +1,5 @@
+-- Add risk state to risk limits
+-- The risk engine keeps each user's peak equity by quote asset and the symbols they have ordered next to their
+-- limits, so the drawdown and exposure limits survive a restart
+ALTER TABLE risk_limits ADD COLUMN IF NOT EXISTS peak_equity JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE risk_limits ADD COLUMN IF NOT EXISTS symbols JSONB NOT NULL DEFAULT '[]';
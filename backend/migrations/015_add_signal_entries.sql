@@ -0,0 +1,4 @@
+-- Add entry times to signals
+-- Signals with limit entries away from the market are only followed to their take profit or stop loss once the
+-- price has traded at their entry; those it never reaches end unfilled and stay out of the accuracy statistics
+ALTER TABLE signals ADD COLUMN IF NOT EXISTS entry_at TIMESTAMP;
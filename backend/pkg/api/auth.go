@@ -1,26 +1,31 @@
 package api
 
 import (
+	"errors"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/exchange"
 	"github.com/ratheeshkumar25/forex_bot/backend/pkg/middleware"
 	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
 	"github.com/ratheeshkumar25/forex_bot/backend/pkg/repository"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/secrets"
 	"golang.org/x/crypto/bcrypt"
 )
 
 // AuthHandler handles authentication endpoints
 type AuthHandler struct {
 	userRepo  *repository.UserRepository
+	clients   *exchange.ClientFactory
 	jwtSecret string
 }
 
 // NewAuthHandler creates a new auth handler
-func NewAuthHandler(userRepo *repository.UserRepository, jwtSecret string) *AuthHandler {
+func NewAuthHandler(userRepo *repository.UserRepository, clients *exchange.ClientFactory, jwtSecret string) *AuthHandler {
 	return &AuthHandler{
 		userRepo:  userRepo,
+		clients:   clients,
 		jwtSecret: jwtSecret,
 	}
 }
@@ -177,11 +182,18 @@ func (h *AuthHandler) UpdateExchangeKeys(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "User not found"})
 	}
 
+	// Validate the keys being set before saving them
+	if err := h.clients.Validate(c.Context(), exchange.Credentials{
+		BinanceAPIKey:    req.BinanceAPIKey,
+		BinanceSecretKey: req.BinanceSecretKey,
+		SolanaPrivateKey: req.SolanaPrivateKey,
+	}); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
 	// Update keys if provided
 	if req.BinanceAPIKey != "" {
 		user.BinanceAPIKey = req.BinanceAPIKey
-	}
-	if req.BinanceSecretKey != "" {
 		user.BinanceSecretKey = req.BinanceSecretKey
 	}
 	if req.SolanaPrivateKey != "" {
@@ -191,8 +203,13 @@ func (h *AuthHandler) UpdateExchangeKeys(c *fiber.Ctx) error {
 	user.UpdatedAt = time.Now()
 
 	if err := h.userRepo.UpdateUser(user); err != nil {
+		if errors.Is(err, secrets.ErrNoKey) {
+			return c.Status(503).JSON(fiber.Map{"error": "Exchange key encryption is not configured"})
+		}
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to update exchange keys"})
 	}
+	// Drop the cached clients so trading uses the new keys from now on
+	h.clients.Invalidate(userID)
 
 	return c.JSON(fiber.Map{"message": "Exchange keys updated successfully"})
 }
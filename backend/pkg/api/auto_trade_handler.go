@@ -0,0 +1,115 @@
+package api
+
+import (
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/exchange"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/middleware"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/repository"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/service"
+)
+
+// AutoTradeHandler handles API requests for automatic execution of worker signals.
+type AutoTradeHandler struct {
+	subRepo   *repository.AutoTradeRepository
+	manager   *service.WorkerManager
+	exchanges map[string]exchange.Exchange
+	clients   *exchange.ClientFactory
+}
+
+// NewAutoTradeHandler creates a new auto trade handler.
+func NewAutoTradeHandler(subRepo *repository.AutoTradeRepository, manager *service.WorkerManager, exchanges map[string]exchange.Exchange, clients *exchange.ClientFactory) *AutoTradeHandler {
+	return &AutoTradeHandler{
+		subRepo:   subRepo,
+		manager:   manager,
+		exchanges: exchanges,
+		clients:   clients,
+	}
+}
+
+// SubscribeRequest represents the request to enable auto trading for a symbol
+type SubscribeRequest struct {
+	Symbol      string  `json:"symbol"`
+	Exchange    string  `json:"exchange"`
+	RiskPercent float64 `json:"risk_percent"`
+	// ExitRules are the trailing stop, break-even and take profit ladder rules of the trades the subscription opens
+	ExitRules *model.ExitRules `json:"exit_rules"`
+}
+
+// Subscribe handles the POST /api/autotrade endpoint.
+// It also starts the analysis worker for the symbol if it is not running yet.
+func (h *AutoTradeHandler) Subscribe(c *fiber.Ctx) error {
+	var req SubscribeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	if req.Symbol == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Symbol is required"})
+	}
+	if req.Exchange == "" {
+		req.Exchange = "paper"
+	}
+	if _, ok := h.exchanges[req.Exchange]; !ok {
+		return c.Status(400).JSON(fiber.Map{"error": "Exchange not found"})
+	}
+	if req.RiskPercent < 0 || req.RiskPercent > 100 {
+		return c.Status(400).JSON(fiber.Map{"error": "Risk percent must be between 0 and 100"})
+	}
+	if req.ExitRules != nil {
+		if err := req.ExitRules.Validate(); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid exit rules: " + err.Error()})
+		}
+	}
+	userID := middleware.GetUserIDFromContext(c)
+	if err := h.clients.CheckAccess(userID, req.Exchange); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	sub := &model.AutoTradeSubscription{
+		UserID:      userID,
+		Symbol:      strings.ToUpper(req.Symbol),
+		Exchange:    req.Exchange,
+		RiskPercent: req.RiskPercent,
+		ExitRules:   req.ExitRules,
+	}
+	if err := h.subRepo.UpsertSubscription(sub); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to save subscription"})
+	}
+
+	if err := h.manager.StartWorker(sub.Symbol); err != nil {
+		// Expected when the worker is already running for the symbol
+		log.Printf("Info while starting worker for %s: %v", sub.Symbol, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"subscription": sub})
+}
+
+// Unsubscribe handles the DELETE /api/autotrade/:symbol endpoint.
+func (h *AutoTradeHandler) Unsubscribe(c *fiber.Ctx) error {
+	symbol := strings.ToUpper(c.Params("symbol"))
+	deleted, err := h.subRepo.DeleteSubscription(middleware.GetUserIDFromContext(c), symbol)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete subscription"})
+	}
+	if !deleted {
+		return c.Status(404).JSON(fiber.Map{"error": "Subscription not found"})
+	}
+
+	return c.JSON(fiber.Map{"message": "Auto trading disabled for " + symbol})
+}
+
+// ListSubscriptions handles the GET /api/autotrade endpoint.
+func (h *AutoTradeHandler) ListSubscriptions(c *fiber.Ctx) error {
+	subs, err := h.subRepo.GetSubscriptionsByUserID(middleware.GetUserIDFromContext(c))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to get subscriptions"})
+	}
+	if subs == nil {
+		subs = []*model.AutoTradeSubscription{}
+	}
+
+	return c.JSON(fiber.Map{"subscriptions": subs})
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/middleware"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/service"
+)
+
+// BotHandler handles API requests for running strategy bots.
+type BotHandler struct {
+	manager *service.BotManager
+}
+
+// NewBotHandler creates a new handler for the bot manager.
+func NewBotHandler(manager *service.BotManager) *BotHandler {
+	return &BotHandler{
+		manager: manager,
+	}
+}
+
+// StartBotRequest represents the request to start a bot
+type StartBotRequest struct {
+	Strategy string          `json:"strategy"`
+	Symbol   string          `json:"symbol"`
+	Exchange string          `json:"exchange"`
+	Interval string          `json:"interval"`
+	Params   json.RawMessage `json:"params"`
+}
+
+// StartBot handles the POST /api/bots endpoint.
+func (h *BotHandler) StartBot(c *fiber.Ctx) error {
+	var req StartBotRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	if req.Strategy == "" || req.Symbol == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Strategy and symbol are required"})
+	}
+	if req.Exchange == "" {
+		req.Exchange = "paper"
+	}
+	if req.Interval == "" {
+		req.Interval = "1m"
+	}
+
+	bot, err := h.manager.StartBot(middleware.GetUserIDFromContext(c), req.Strategy, strings.ToUpper(req.Symbol), req.Exchange, req.Interval, req.Params)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"bot": bot})
+}
+
+// StopBot handles the DELETE /api/bots/:id endpoint.
+func (h *BotHandler) StopBot(c *fiber.Ctx) error {
+	botID, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid bot id"})
+	}
+
+	if err := h.manager.StopBot(middleware.GetUserIDFromContext(c), botID); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "Bot stopped"})
+}
+
+// GetBot handles the GET /api/bots/:id endpoint.
+// The response includes the strategy state, such as the grid levels and completed round trips.
+func (h *BotHandler) GetBot(c *fiber.Ctx) error {
+	botID, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid bot id"})
+	}
+
+	bot, err := h.manager.GetBot(middleware.GetUserIDFromContext(c), botID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"bot": bot})
+}
+
+// ListBots handles the GET /api/bots endpoint.
+func (h *BotHandler) ListBots(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"bots": h.manager.ListBots(middleware.GetUserIDFromContext(c))})
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/service"
+)
+
+// CandleHandler handles API requests for the candle store.
+type CandleHandler struct {
+	fetcherSvc  *service.FetcherService
+	backfillSvc *service.BackfillService
+}
+
+// NewCandleHandler creates a new handler for the candle store.
+func NewCandleHandler(fetcher *service.FetcherService, backfill *service.BackfillService) *CandleHandler {
+	return &CandleHandler{
+		fetcherSvc:  fetcher,
+		backfillSvc: backfill,
+	}
+}
+
+// maxRequestBars is the most candles a synchronous read may cover. Missing candles are paged from Binance and stored
+// before the response, so an unbounded range would let one request fetch and store years of 1m candles.
+const maxRequestBars = 10000
+
+// defaultRange is the date range of candle requests that give no start.
+const defaultRange = 30 * 24 * time.Hour
+
+// candleRange parses the symbol, interval and date range shared by the candle endpoints.
+// With maxBars set, the range may cover at most that many candles, and the default range is shortened to fit.
+func candleRange(c *fiber.Ctx, maxBars int) (symbol, interval string, start, end time.Time, err error) {
+	symbol = strings.ToUpper(c.Query("symbol", "BTCUSDT"))
+	interval = c.Query("interval", "1h")
+	end, err = parseDate(c.Query("end"), time.Now())
+	if err != nil {
+		return
+	}
+	window := defaultRange
+	if step, err := model.IntervalDuration(interval); err == nil && maxBars > 0 {
+		window = min(window, time.Duration(maxBars)*step)
+	}
+	start, err = parseDate(c.Query("start"), end.Add(-window))
+	if err != nil || maxBars == 0 {
+		return
+	}
+	err = checkBars(interval, start, end, maxBars)
+	return
+}
+
+// checkBars returns an error if the range from start to end covers more than maxBars candles of interval.
+func checkBars(interval string, start, end time.Time, maxBars int) error {
+	step, err := model.IntervalDuration(interval)
+	if err != nil {
+		return err
+	}
+	if end.Sub(start) > time.Duration(maxBars)*step {
+		return fmt.Errorf("the range covers more than %d %s candles; shorten it or use a longer interval", maxBars, interval)
+	}
+	return nil
+}
+
+// GetCandles handles the GET /api/candles endpoint, serving stored candles and filling any gaps from Binance.
+func (h *CandleHandler) GetCandles(c *fiber.Ctx) error {
+	symbol, interval, start, end, err := candleRange(c, maxRequestBars)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid date range: " + err.Error()})
+	}
+
+	candles, err := h.fetcherSvc.FetchCandleRange(c.Context(), symbol, interval, start, end)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"symbol":   symbol,
+		"interval": interval,
+		"candles":  candles,
+	})
+}
+
+// GetGaps handles the GET /api/candles/gaps endpoint.
+func (h *CandleHandler) GetGaps(c *fiber.Ctx) error {
+	symbol, interval, start, end, err := candleRange(c, maxRequestBars)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid date range: " + err.Error()})
+	}
+
+	gaps, err := h.backfillSvc.FindGaps(symbol, interval, start, end)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"symbol":   symbol,
+		"interval": interval,
+		"gaps":     gaps,
+	})
+}
+
+// Backfill handles the POST /api/candles/backfill endpoint.
+// The backfill runs in the background and pages through ranges of any length; progress can be followed through
+// /api/candles/gaps.
+func (h *CandleHandler) Backfill(c *fiber.Ctx) error {
+	symbol, interval, start, end, err := candleRange(c, 0)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid date range: " + err.Error()})
+	}
+	if !end.After(start) {
+		return c.Status(400).JSON(fiber.Map{"error": "End must be after start"})
+	}
+
+	go func() {
+		if _, err := h.backfillSvc.Backfill(context.Background(), symbol, interval, start, end); err != nil {
+			log.Printf("Backfill for %s [%s] failed: %v", symbol, interval, err)
+		}
+	}()
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message":  "Backfill started for " + symbol,
+		"interval": interval,
+		"start":    start,
+		"end":      end,
+	})
+}
@@ -1,27 +1,38 @@
 package api
 
 import (
+	"database/sql"
+	"errors"
 	"log"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/middleware"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/repository"
 	"github.com/ratheeshkumar25/forex_bot/backend/pkg/service"
 )
 
-// PredictionHandler handles API requests for predictions.
+// PredictionHandler handles API requests for predictions and the scoring profiles they are made with.
 type PredictionHandler struct {
-	fetcherSvc *service.FetcherService
-	predSvc    *service.PredictionService
-	manager    *service.WorkerManager
+	fetcherSvc  *service.FetcherService
+	predSvc     *service.PredictionService
+	manager     *service.WorkerManager
+	profileRepo *repository.ScoringProfileRepository
+	calibrator  *service.CalibrationService
+	jwtSecret   string
 }
 
 // NewPredictionHandler creates a new handler.
-// It depends on the FetcherService, PredictionService, and WorkerManager, which fx will provide.
-func NewPredictionHandler(fetcher *service.FetcherService, predictor *service.PredictionService, manager *service.WorkerManager) *PredictionHandler {
+// It depends on the FetcherService, PredictionService, WorkerManager, ScoringProfileRepository and CalibrationService, which fx will provide.
+func NewPredictionHandler(fetcher *service.FetcherService, predictor *service.PredictionService, manager *service.WorkerManager, profileRepo *repository.ScoringProfileRepository, calibrator *service.CalibrationService, jwtSecret string) *PredictionHandler {
 	return &PredictionHandler{
-		fetcherSvc: fetcher,
-		predSvc:    predictor,
-		manager:    manager,
+		fetcherSvc:  fetcher,
+		predSvc:     predictor,
+		manager:     manager,
+		profileRepo: profileRepo,
+		calibrator:  calibrator,
+		jwtSecret:   jwtSecret,
 	}
 }
 
@@ -36,9 +47,15 @@ func (h *PredictionHandler) GetPrediction(c *fiber.Ctx) error {
 		})
 	}
 
-	// 2. Fetch the latest market data using the new Binance FetcherService.
+	// 2. Load the scoring profile. Profiles other than the default one belong to the logged in user.
+	profile, ferr := h.profile(c, c.Query("profile"))
+	if ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+
+	// 3. Fetch the latest market data using the new Binance FetcherService.
 	// We'll hardcode "5m" as the interval for this specific API endpoint.
-	data, err := h.fetcherSvc.FetchKlines(symbol, "5m")
+	candles, err := h.fetcherSvc.FetchCandles(symbol, "5m", 100)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error":   "Failed to fetch market data from Binance",
@@ -46,11 +63,13 @@ func (h *PredictionHandler) GetPrediction(c *fiber.Ctx) error {
 		})
 	}
 
-	// 3. Generate a prediction using the PredictionService.
-	// Notice this part of our code doesn't need to change at all!
-	// Our abstraction works perfectly.
-	params := h.predSvc.DefaultPredictionParams()
-	prediction := h.predSvc.AdvancedPredictBuySell(symbol, data, params)
+	// 4. Generate a prediction using the PredictionService.
+	// The response includes the vote of every indicator of the profile.
+	prediction := h.predSvc.AdvancedPredictBuySell(symbol, candles, profile)
+	// Calibrations are fitted on the signals of the workers, which score with the default profile.
+	if profile.Name == model.DefaultScoringProfile {
+		h.calibrator.Calibrate(&prediction, "5m")
+	}
 
 	// Log the signal to the terminal if it is a "buy" or "sell" event.
 	if prediction.Signal == "buy" || prediction.Signal == "sell" {
@@ -63,20 +82,113 @@ func (h *PredictionHandler) GetPrediction(c *fiber.Ctx) error {
 		)
 	}
 
-	// After providing the manual prediction, also start monitoring the symbol in the background.
-	// We run this in a goroutine so it doesn't block the API response.
-	go func() {
-		err := h.manager.StartWorker(symbol)
-		if err != nil {
-			// This error is expected if the worker is already running. We can just log it for info.
-			log.Printf("Info while auto-starting worker for %s: %v", symbol, err)
-		}
-	}()
+	// The multi-timeframe consensus of the symbol's worker comes along once it has analysed a timeframe.
+	response := PredictionResponse{Prediction: prediction}
+	if consensus, ok := h.manager.Consensus(symbol); ok {
+		response.Consensus = consensus
+	}
+	return c.JSON(response)
+}
 
-	return c.JSON(prediction)
+// PredictionResponse is a prediction along with the multi-timeframe consensus of the symbol's worker
+type PredictionResponse struct {
+	model.Prediction
+	Consensus *model.Consensus `json:"consensus,omitempty"`
+}
+
+// profile returns the named scoring profile of the request's user, or the default profile when name is empty.
+func (h *PredictionHandler) profile(c *fiber.Ctx, name string) (*model.ScoringProfile, *fiber.Error) {
+	if name == "" || name == model.DefaultScoringProfile {
+		return h.predSvc.DefaultProfile(), nil
+	}
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		return nil, fiber.NewError(401, "Log in to use scoring profile "+name)
+	}
+	profile, err := h.profileRepo.GetProfile(userID, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fiber.NewError(404, "Scoring profile not found")
+	}
+	if err != nil {
+		return nil, fiber.NewError(500, "Failed to load scoring profile")
+	}
+	return profile, nil
+}
+
+// GetReliability handles the GET /api/prediction/reliability endpoint, comparing the raw and calibrated confidence
+// of past signals with how often they hit their take profit.
+func (h *PredictionHandler) GetReliability(c *fiber.Ctx) error {
+	buckets := c.QueryInt("buckets", 10)
+	if buckets < 1 || buckets > 100 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "buckets must be between 1 and 100"})
+	}
+	return c.JSON(h.calibrator.Reliability(strings.ToUpper(c.Query("symbol")), c.Query("timeframe"), buckets))
+}
+
+// ListIndicators handles the GET /api/prediction/indicators endpoint, listing the indicators profiles can use with their default parameters.
+func (h *PredictionHandler) ListIndicators(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"indicators": h.predSvc.Indicators()})
+}
+
+// ListProfiles handles the GET /api/prediction/profiles endpoint, listing the default profile and the user's profiles.
+func (h *PredictionHandler) ListProfiles(c *fiber.Ctx) error {
+	profiles, err := h.profileRepo.GetProfilesByUserID(middleware.GetUserIDFromContext(c))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load scoring profiles"})
+	}
+	return c.JSON(fiber.Map{"profiles": append([]*model.ScoringProfile{h.predSvc.DefaultProfile()}, profiles...)})
+}
+
+// ProfileRequest represents the request to save a scoring profile
+type ProfileRequest struct {
+	Indicators []model.IndicatorWeight `json:"indicators"`
+}
+
+// SaveProfile handles the PUT /api/prediction/profiles/:name endpoint, creating or replacing a scoring profile.
+func (h *PredictionHandler) SaveProfile(c *fiber.Ctx) error {
+	name := c.Params("name")
+	if name == model.DefaultScoringProfile {
+		return c.Status(400).JSON(fiber.Map{"error": "The default profile cannot be changed"})
+	}
+	if len(name) > 50 {
+		return c.Status(400).JSON(fiber.Map{"error": "Profile name must be at most 50 characters"})
+	}
+	var req ProfileRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	profile := &model.ScoringProfile{UserID: middleware.GetUserIDFromContext(c), Name: name, Indicators: req.Indicators}
+	if err := h.predSvc.ValidateProfile(profile); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := h.profileRepo.UpsertProfile(profile); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to save scoring profile"})
+	}
+	return c.JSON(fiber.Map{"profile": profile})
+}
+
+// DeleteProfile handles the DELETE /api/prediction/profiles/:name endpoint.
+func (h *PredictionHandler) DeleteProfile(c *fiber.Ctx) error {
+	deleted, err := h.profileRepo.DeleteProfile(middleware.GetUserIDFromContext(c), c.Params("name"))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete scoring profile"})
+	}
+	if !deleted {
+		return c.Status(404).JSON(fiber.Map{"error": "Scoring profile not found"})
+	}
+	return c.JSON(fiber.Map{"message": "Scoring profile deleted"})
 }
 
 // RegisterRoutes sets up the routes for this handler on the Fiber app.
+// Predictions stay public; a logged in user may select one of their scoring profiles.
 func (h *PredictionHandler) RegisterRoutes(app *fiber.App) {
-	app.Get("/api/prediction", h.GetPrediction)
+	app.Get("/api/prediction", middleware.OptionalJWTMiddleware(h.jwtSecret), h.GetPrediction)
+	app.Get("/api/prediction/indicators", h.ListIndicators)
+	app.Get("/api/prediction/reliability", h.GetReliability)
+
+	profiles := app.Group("/api/prediction/profiles", middleware.JWTMiddleware(h.jwtSecret))
+	profiles.Get("", h.ListProfiles)
+	profiles.Put("/:name", h.SaveProfile)
+	profiles.Delete("/:name", h.DeleteProfile)
 }
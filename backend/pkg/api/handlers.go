@@ -2,11 +2,15 @@ package api
 
 import (
 	"log"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/backtest"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/events"
 	"github.com/ratheeshkumar25/forex_bot/backend/pkg/exchange"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/middleware"
 	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
 	"github.com/ratheeshkumar25/forex_bot/backend/pkg/predictor"
 	"github.com/ratheeshkumar25/forex_bot/backend/pkg/repository"
@@ -16,20 +20,22 @@ import (
 // Handler holds dependencies for API handlers
 type Handler struct {
 	Exchanges      map[string]exchange.Exchange
-	Strategies     map[string]strategy.Strategy
+	Strategies     map[string]strategy.Factory
 	Predictor      *predictor.Predictor
 	TradeRepo      *repository.TradeRepository
 	SignalRepo     *repository.SignalRepository
+	Events         *events.Bus
 }
 
 // NewHandler creates a new handler
-func NewHandler(exchanges map[string]exchange.Exchange, strategies map[string]strategy.Strategy, pred *predictor.Predictor, tradeRepo *repository.TradeRepository, signalRepo *repository.SignalRepository) *Handler {
+func NewHandler(exchanges map[string]exchange.Exchange, strategies map[string]strategy.Factory, pred *predictor.Predictor, tradeRepo *repository.TradeRepository, signalRepo *repository.SignalRepository, bus *events.Bus) *Handler {
 	return &Handler{
 		Exchanges:      exchanges,
 		Strategies:     strategies,
 		Predictor:      pred,
 		TradeRepo:      tradeRepo,
 		SignalRepo:     signalRepo,
+		Events:         bus,
 	}
 }
 
@@ -55,19 +61,97 @@ func (h *Handler) GetPrice(c *fiber.Ctx) error {
 	})
 }
 
-// PredictProfit handles profit prediction
+// GetBalance handles getting the authenticated user's balance for an asset on an exchange
+func (h *Handler) GetBalance(c *fiber.Ctx) error {
+	exchangeName := c.Params("exchange")
+	asset := strings.ToUpper(c.Query("asset", "USDT"))
+
+	ex, ok := h.Exchanges[exchangeName]
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"error": "Exchange not found"})
+	}
+
+	ctx := exchange.WithUserID(c.Context(), middleware.GetUserIDFromContext(c))
+	balance, err := ex.GetBalance(ctx, asset)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"exchange": exchangeName,
+		"asset":    asset,
+		"balance":  balance,
+	})
+}
+
+// GetPaperAccount handles getting the authenticated user's paper trading balances, open orders and fills
+func (h *Handler) GetPaperAccount(c *fiber.Ctx) error {
+	paper, ok := exchange.Unwrap(h.Exchanges["paper"]).(*exchange.PaperExchange)
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"error": "Paper trading is not enabled"})
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	free, locked := paper.Balances(userID)
+
+	return c.JSON(fiber.Map{
+		"balances":    free,
+		"locked":      locked,
+		"open_orders": paper.OpenOrders(userID),
+		"fills":       paper.Fills(userID),
+	})
+}
+
+// backtestWindows maps the legacy timeframe parameter to a default backtest range
+var backtestWindows = map[string]time.Duration{
+	"short": 7 * 24 * time.Hour,
+	"long":  90 * 24 * time.Hour,
+}
+
+// PredictProfit handles profit prediction by backtesting the strategy over a date range
 func (h *Handler) PredictProfit(c *fiber.Ctx) error {
 	strategyName := c.Params("strategy")
-	symbol := c.Query("symbol")
-	investment := c.QueryFloat("investment", 0)
+	symbol := strings.ToUpper(c.Query("symbol", "BTCUSDT"))
+	investment := c.QueryFloat("investment", 1000)
 	timeframe := c.Query("timeframe", "long")
+	interval := c.Query("interval", "1h")
 
-	strat, ok := h.Strategies[strategyName]
+	newStrategy, ok := h.Strategies[strategyName]
 	if !ok {
 		return c.Status(400).JSON(fiber.Map{"error": "Strategy not found"})
 	}
+	strat := newStrategy()
+	if err := strat.Init(nil); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
 
-	profit, percentage, err := h.Predictor.PredictProfit(strat, symbol, investment, timeframe)
+	window, ok := backtestWindows[timeframe]
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"error": "Timeframe must be short or long"})
+	}
+
+	end, err := parseDate(c.Query("end"), time.Now())
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid end date: " + err.Error()})
+	}
+	start, err := parseDate(c.Query("start"), end.Add(-window))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid start date: " + err.Error()})
+	}
+	if err := checkBars(interval, start, end, maxRequestBars); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid date range: " + err.Error()})
+	}
+
+	result, err := h.Predictor.PredictProfit(c.Context(), strat, backtest.Config{
+		Symbol:         symbol,
+		Interval:       interval,
+		Start:          start,
+		End:            end,
+		InitialCapital: investment,
+		BaseAllocation: c.QueryFloat("base_allocation", 0.5),
+		FeeRate:        c.QueryFloat("fee_rate", 0.001),
+		SlippageRate:   c.QueryFloat("slippage", 0.0005),
+	})
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -77,20 +161,36 @@ func (h *Handler) PredictProfit(c *fiber.Ctx) error {
 		"symbol":           symbol,
 		"investment":       investment,
 		"timeframe":        timeframe,
-		"predictedProfit":  profit,
-		"profitPercentage": percentage,
+		"predictedProfit":  result.FinalEquity - result.InitialCapital,
+		"profitPercentage": result.TotalReturn,
+		"backtest":         result,
 	})
 }
 
+// parseDate parses a date given as RFC 3339 or YYYY-MM-DD, returning def when the value is empty
+func parseDate(value string, def time.Time) (time.Time, error) {
+	if value == "" {
+		return def, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
 // GetSignals handles getting trading signals for a strategy
 func (h *Handler) GetSignals(c *fiber.Ctx) error {
 	strategyName := c.Params("strategy")
 	symbol := c.Query("symbol")
 
-	strat, ok := h.Strategies[strategyName]
+	newStrategy, ok := h.Strategies[strategyName]
 	if !ok {
 		return c.Status(400).JSON(fiber.Map{"error": "Strategy not found"})
 	}
+	strat, ok := newStrategy().(strategy.SignalProvider)
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"error": "Strategy does not provide signals"})
+	}
 
 	// Get current price to base signals on
 	ex, ok := h.Exchanges["binance"] // Assuming binance for now
@@ -123,6 +223,7 @@ func (h *Handler) GetSignals(c *fiber.Ctx) error {
 			// Log the error but don't block the response
 			log.Printf("Error saving signal: %v", err)
 		}
+		h.Events.Publish(events.Event{Type: events.SignalCreated, Symbol: symbol, Data: dbSignal})
 	}
 
 	return c.JSON(fiber.Map{
@@ -132,6 +233,42 @@ func (h *Handler) GetSignals(c *fiber.Ctx) error {
 	})
 }
 
+// ListSignals handles the GET /api/signals endpoint, listing the latest stored signals with their outcomes
+func (h *Handler) ListSignals(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 100)
+	if limit < 1 || limit > 1000 {
+		return c.Status(400).JSON(fiber.Map{"error": "limit must be between 1 and 1000"})
+	}
+	signals, err := h.SignalRepo.GetRecentSignals(strings.ToUpper(c.Query("symbol")), limit)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load signals"})
+	}
+	return c.JSON(fiber.Map{"signals": signals})
+}
+
+// GetSignalStats handles the GET /api/signals/stats endpoint, reporting the accuracy of the evaluated signals
+// by strategy, symbol and timeframe. The strategy, symbol and timeframe query parameters narrow it down.
+func (h *Handler) GetSignalStats(c *fiber.Ctx) error {
+	var filter repository.SignalStatsFilter
+	for _, f := range []struct {
+		param string
+		value **string
+	}{{"strategy", &filter.Strategy}, {"symbol", &filter.Symbol}, {"timeframe", &filter.Timeframe}} {
+		// An empty timeframe selects the signals generated without one
+		if value, ok := c.Queries()[f.param]; ok {
+			if f.param == "symbol" {
+				value = strings.ToUpper(value)
+			}
+			*f.value = &value
+		}
+	}
+	stats, err := h.SignalRepo.GetSignalStats(filter)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load signal statistics"})
+	}
+	return c.JSON(fiber.Map{"stats": stats})
+}
+
 // GetUserTrades handles getting user trades
 func (h *Handler) GetUserTrades(c *fiber.Ctx) error {
 	user := c.Locals("user").(*jwt.Token)
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/repository"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/service"
+)
+
+// MLHandler handles API requests for the ML forecasting models.
+type MLHandler struct {
+	mlSvc     *service.MLService
+	modelRepo *repository.MLModelRepository
+}
+
+// NewMLHandler creates a new ML model handler.
+func NewMLHandler(mlSvc *service.MLService, modelRepo *repository.MLModelRepository) *MLHandler {
+	return &MLHandler{mlSvc: mlSvc, modelRepo: modelRepo}
+}
+
+// ListModels handles the GET /api/ml/models endpoint, listing the model versions, optionally of a symbol and interval.
+func (h *MLHandler) ListModels(c *fiber.Ctx) error {
+	models, err := h.modelRepo.GetModels(strings.ToUpper(c.Query("symbol")), c.Query("interval"))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load ML models"})
+	}
+	return c.JSON(fiber.Map{"models": models})
+}
+
+// GetModel handles the GET /api/ml/models/:id endpoint.
+func (h *MLHandler) GetModel(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid model ID"})
+	}
+	m, err := h.modelRepo.GetModel(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.Status(404).JSON(fiber.Map{"error": "ML model not found"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load ML model"})
+	}
+	return c.JSON(fiber.Map{"model": m})
+}
+
+// Train handles the POST /api/admin/ml/train endpoint, training and validating a new model version on the candles of
+// the symbol, interval and date range, and activating it if asked to. Candles missing from the store are fetched first,
+// so a backfill beforehand keeps long ranges fast.
+func (h *MLHandler) Train(c *fiber.Ctx) error {
+	symbol, interval, start, end, err := candleRange(c, 0)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid date range: " + err.Error()})
+	}
+	if !end.After(start) {
+		return c.Status(400).JSON(fiber.Map{"error": "End must be after start"})
+	}
+
+	m, err := h.mlSvc.Train(c.Context(), service.MLTrainRequest{
+		Symbol:   symbol,
+		Interval: interval,
+		Start:    start,
+		End:      end,
+		Horizon:  c.QueryInt("horizon", 12),
+		Folds:    c.QueryInt("folds", 5),
+		Activate: c.QueryBool("activate"),
+	})
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"model": m})
+}
+
+// ActivateModel handles the POST /api/admin/ml/models/:id/activate endpoint, making the version the one that votes in
+// predictions for its symbol and interval.
+func (h *MLHandler) ActivateModel(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid model ID"})
+	}
+	err = h.mlSvc.Activate(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.Status(404).JSON(fiber.Map{"error": "ML model not found"})
+	}
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "ML model activated"})
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/exchange"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/middleware"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/repository"
+)
+
+// OrderHandler handles API requests for the user's exchange orders.
+type OrderHandler struct {
+	orderRepo *repository.OrderRepository
+	exchanges map[string]exchange.Exchange
+}
+
+// NewOrderHandler creates a new order handler.
+func NewOrderHandler(orderRepo *repository.OrderRepository, exchanges map[string]exchange.Exchange) *OrderHandler {
+	return &OrderHandler{
+		orderRepo: orderRepo,
+		exchanges: exchanges,
+	}
+}
+
+// ListOrders handles the GET /api/orders endpoint, returning the user's stored orders, newest first.
+func (h *OrderHandler) ListOrders(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 100)
+	if limit <= 0 || limit > 1000 {
+		return c.Status(400).JSON(fiber.Map{"error": "Limit must be between 1 and 1000"})
+	}
+
+	orders, err := h.orderRepo.GetOrdersByUserID(middleware.GetUserIDFromContext(c), limit)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load orders"})
+	}
+	return c.JSON(fiber.Map{"orders": orders})
+}
+
+// ListOpenOrders handles the GET /api/orders/open/:exchange endpoint.
+// Open orders are read from the exchange, so orders placed outside the application are included.
+func (h *OrderHandler) ListOpenOrders(c *fiber.Ctx) error {
+	ex, ok := h.exchanges[c.Params("exchange")]
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"error": "Exchange not found"})
+	}
+
+	ctx := exchange.WithUserID(c.Context(), middleware.GetUserIDFromContext(c))
+	orders, err := ex.ListOpenOrders(ctx, strings.ToUpper(c.Query("symbol")))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if orders == nil {
+		orders = []*model.Order{}
+	}
+	return c.JSON(fiber.Map{"orders": orders})
+}
+
+// GetOrder handles the GET /api/orders/:id endpoint, refreshing the stored order from its exchange.
+func (h *OrderHandler) GetOrder(c *fiber.Ctx) error {
+	order, ex, ferr := h.userOrder(c)
+	if ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+
+	ctx := exchange.WithUserID(c.Context(), order.UserID)
+	current, err := ex.GetOrder(ctx, order.Symbol, order.ExchangeOrderID)
+	if err != nil {
+		// Paper orders do not survive a restart; return what was stored
+		return c.JSON(fiber.Map{"order": order, "warning": "Could not refresh order: " + err.Error()})
+	}
+	return c.JSON(fiber.Map{"order": current})
+}
+
+// CancelOrder handles the DELETE /api/orders/:id endpoint.
+func (h *OrderHandler) CancelOrder(c *fiber.Ctx) error {
+	order, ex, ferr := h.userOrder(c)
+	if ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+	if !order.IsOpen() {
+		return c.Status(409).JSON(fiber.Map{"error": "Order is already " + order.Status})
+	}
+
+	ctx := exchange.WithUserID(c.Context(), order.UserID)
+	cancelled, err := ex.CancelOrder(ctx, order.Symbol, order.ExchangeOrderID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"order": cancelled})
+}
+
+// userOrder loads the order named by the :id parameter, which must belong to the user, and its exchange.
+func (h *OrderHandler) userOrder(c *fiber.Ctx) (*model.Order, exchange.Exchange, *fiber.Error) {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return nil, nil, fiber.NewError(400, "Invalid order id")
+	}
+	order, err := h.orderRepo.GetOrderByID(id)
+	if err != nil || order.UserID != middleware.GetUserIDFromContext(c) {
+		return nil, nil, fiber.NewError(404, "Order not found")
+	}
+	ex, ok := h.exchanges[order.Exchange]
+	if !ok {
+		return nil, nil, fiber.NewError(400, "Exchange not found")
+	}
+	return order, ex, nil
+}
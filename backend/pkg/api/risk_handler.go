@@ -0,0 +1,132 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/middleware"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/risk"
+)
+
+// RiskHandler handles API requests for risk limits and kill switches.
+type RiskHandler struct {
+	engine *risk.Engine
+}
+
+// NewRiskHandler creates a new risk handler.
+func NewRiskHandler(engine *risk.Engine) *RiskHandler {
+	return &RiskHandler{
+		engine: engine,
+	}
+}
+
+// RiskLimitsRequest represents the request to update risk limits. Zero values use the global defaults.
+type RiskLimitsRequest struct {
+	MaxPositionSize    float64 `json:"max_position_size"`
+	MaxTotalExposure   float64 `json:"max_total_exposure"`
+	MaxOrdersPerMinute int     `json:"max_orders_per_minute"`
+	MaxDailyLoss       float64 `json:"max_daily_loss"`
+	MaxDrawdownPercent float64 `json:"max_drawdown_percent"`
+}
+
+// KillSwitchRequest represents the request to turn a kill switch on or off
+type KillSwitchRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// GetRisk handles the GET /api/risk endpoint.
+// It returns the limits in force for the user along with their current usage.
+func (h *RiskHandler) GetRisk(c *fiber.Ctx) error {
+	userID := middleware.GetUserIDFromContext(c)
+	limits, own, err := h.engine.Limits(userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to get risk limits"})
+	}
+	globalKill, err := h.engine.GlobalKillSwitch()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to get risk limits"})
+	}
+	dailyPnL, err := h.engine.DailyPnL(userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to get daily profit and loss"})
+	}
+
+	return c.JSON(fiber.Map{
+		"limits":             limits,
+		"own_limits":         own,
+		"global_kill_switch": globalKill,
+		"orders_last_minute": h.engine.OrdersLastMinute(userID),
+		"daily_pnl":          dailyPnL,
+	})
+}
+
+// UpdateLimits handles the PUT /api/risk/limits endpoint.
+func (h *RiskHandler) UpdateLimits(c *fiber.Ctx) error {
+	return h.updateLimits(c, middleware.GetUserIDFromContext(c))
+}
+
+// SetKillSwitch handles the POST /api/risk/kill-switch endpoint.
+func (h *RiskHandler) SetKillSwitch(c *fiber.Ctx) error {
+	return h.setKillSwitch(c, middleware.GetUserIDFromContext(c))
+}
+
+// GetGlobalLimits handles the GET /api/admin/risk/limits endpoint.
+func (h *RiskHandler) GetGlobalLimits(c *fiber.Ctx) error {
+	limits, _, err := h.engine.Limits(model.GlobalRiskUserID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to get risk limits"})
+	}
+	globalKill, err := h.engine.GlobalKillSwitch()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to get risk limits"})
+	}
+	limits.KillSwitch = globalKill
+
+	return c.JSON(fiber.Map{"limits": limits})
+}
+
+// UpdateGlobalLimits handles the PUT /api/admin/risk/limits endpoint.
+func (h *RiskHandler) UpdateGlobalLimits(c *fiber.Ctx) error {
+	return h.updateLimits(c, model.GlobalRiskUserID)
+}
+
+// SetGlobalKillSwitch handles the POST /api/admin/risk/kill-switch endpoint.
+func (h *RiskHandler) SetGlobalKillSwitch(c *fiber.Ctx) error {
+	return h.setKillSwitch(c, model.GlobalRiskUserID)
+}
+
+func (h *RiskHandler) updateLimits(c *fiber.Ctx, userID int) error {
+	var req RiskLimitsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	if req.MaxPositionSize < 0 || req.MaxTotalExposure < 0 || req.MaxOrdersPerMinute < 0 || req.MaxDailyLoss < 0 || req.MaxDrawdownPercent < 0 || req.MaxDrawdownPercent > 100 {
+		return c.Status(400).JSON(fiber.Map{"error": "Limits must not be negative and drawdown must be at most 100%"})
+	}
+
+	limits := &model.RiskLimits{
+		UserID:             userID,
+		MaxPositionSize:    req.MaxPositionSize,
+		MaxTotalExposure:   req.MaxTotalExposure,
+		MaxOrdersPerMinute: req.MaxOrdersPerMinute,
+		MaxDailyLoss:       req.MaxDailyLoss,
+		MaxDrawdownPercent: req.MaxDrawdownPercent,
+	}
+	if err := h.engine.UpdateLimits(limits); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update risk limits"})
+	}
+
+	return c.JSON(fiber.Map{"limits": limits})
+}
+
+func (h *RiskHandler) setKillSwitch(c *fiber.Ctx, userID int) error {
+	var req KillSwitchRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if err := h.engine.SetKillSwitch(c.Context(), userID, req.Enabled); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to set kill switch", "details": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"kill_switch": req.Enabled})
+}
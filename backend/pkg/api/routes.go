@@ -7,7 +7,7 @@ import (
 )
 
 // SetupRoutes sets up the API routes
-func SetupRoutes(app *fiber.App, handler *Handler, authHandler *AuthHandler, wsHandler *WebSocketHandler, jwtSecret string) {
+func SetupRoutes(app *fiber.App, handler *Handler, authHandler *AuthHandler, wsHandler *WebSocketHandler, candleHandler *CandleHandler, botHandler *BotHandler, orderHandler *OrderHandler, autoTradeHandler *AutoTradeHandler, riskHandler *RiskHandler, tradeHandler *TradeHandler, mlHandler *MLHandler, jwtSecret string, adminUsernames []string) {
 	api := app.Group("/api")
 
 	// Public routes
@@ -23,8 +23,40 @@ func SetupRoutes(app *fiber.App, handler *Handler, authHandler *AuthHandler, wsH
 	protected.Get("/auth/profile", authHandler.GetProfile)
 	protected.Put("/auth/exchange-keys", authHandler.UpdateExchangeKeys)
 	protected.Get("/trades", handler.GetUserTrades)
+	protected.Put("/trades/:id/exit-rules", tradeHandler.SetExitRules)
+	protected.Get("/balance/:exchange", handler.GetBalance)
+	protected.Get("/paper/account", handler.GetPaperAccount)
+	protected.Post("/candles/backfill", candleHandler.Backfill)
+	protected.Get("/candles/gaps", candleHandler.GetGaps)
+	protected.Get("/bots", botHandler.ListBots)
+	protected.Post("/bots", botHandler.StartBot)
+	protected.Get("/bots/:id", botHandler.GetBot)
+	protected.Delete("/bots/:id", botHandler.StopBot)
+	protected.Get("/orders", orderHandler.ListOrders)
+	protected.Get("/orders/open/:exchange", orderHandler.ListOpenOrders)
+	protected.Get("/orders/:id", orderHandler.GetOrder)
+	protected.Delete("/orders/:id", orderHandler.CancelOrder)
+	protected.Get("/autotrade", autoTradeHandler.ListSubscriptions)
+	protected.Post("/autotrade", autoTradeHandler.Subscribe)
+	protected.Delete("/autotrade/:symbol", autoTradeHandler.Unsubscribe)
+	protected.Get("/risk", riskHandler.GetRisk)
+	protected.Put("/risk/limits", riskHandler.UpdateLimits)
+	protected.Post("/risk/kill-switch", riskHandler.SetKillSwitch)
+	protected.Get("/ml/models", mlHandler.ListModels)
+	protected.Get("/ml/models/:id", mlHandler.GetModel)
+
+	// Admin routes
+	admin := protected.Group("/admin", middleware.AdminMiddleware(adminUsernames))
+	admin.Get("/risk/limits", riskHandler.GetGlobalLimits)
+	admin.Put("/risk/limits", riskHandler.UpdateGlobalLimits)
+	admin.Post("/risk/kill-switch", riskHandler.SetGlobalKillSwitch)
+	admin.Post("/ml/train", mlHandler.Train)
+	admin.Post("/ml/models/:id/activate", mlHandler.ActivateModel)
 
 	// Public routes (no auth required)
 	api.Get("/price/:exchange", handler.GetPrice)
+	api.Get("/signals", handler.ListSignals)
+	api.Get("/signals/stats", handler.GetSignalStats)
 	api.Get("/signals/:strategy", handler.GetSignals)
+	api.Get("/candles", candleHandler.GetCandles)
 }
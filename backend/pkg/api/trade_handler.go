@@ -0,0 +1,46 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/middleware"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/service"
+)
+
+// TradeHandler handles API requests for the user's open trades.
+type TradeHandler struct {
+	positions *service.PositionManager
+}
+
+// NewTradeHandler creates a new trade handler.
+func NewTradeHandler(positions *service.PositionManager) *TradeHandler {
+	return &TradeHandler{positions: positions}
+}
+
+// ExitRulesRequest represents the request to set the exit rules of an open trade
+type ExitRulesRequest struct {
+	ExitRules *model.ExitRules `json:"exit_rules"` // null removes the rules
+}
+
+// SetExitRules handles the PUT /api/trades/:id/exit-rules endpoint.
+func (h *TradeHandler) SetExitRules(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid trade ID"})
+	}
+	var req ExitRulesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	trade, err := h.positions.SetExitRules(middleware.GetUserIDFromContext(c), id, req.ExitRules)
+	if errors.Is(err, service.ErrTradeNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": "Open trade not found"})
+	}
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"trade": trade})
+}
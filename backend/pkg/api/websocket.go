@@ -1,46 +1,52 @@
 package api
 
 import (
-	"context"
 	"log"
+	"strings"
 
+	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/websocket/v2"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/middleware"
 	"github.com/ratheeshkumar25/forex_bot/backend/pkg/service"
 )
 
 // WebSocketHandler handles WebSocket connections
 type WebSocketHandler struct {
 	priceStreamer *service.PriceStreamer
+	jwtSecret     string
 }
 
 // NewWebSocketHandler creates a new WebSocket handler
-func NewWebSocketHandler(priceStreamer *service.PriceStreamer) *WebSocketHandler {
+func NewWebSocketHandler(priceStreamer *service.PriceStreamer, jwtSecret string) *WebSocketHandler {
 	return &WebSocketHandler{
 		priceStreamer: priceStreamer,
+		jwtSecret:     jwtSecret,
 	}
 }
 
-// HandlePriceStream handles WebSocket connections for price streaming
+// HandlePriceStream handles WebSocket connections for price streaming.
+// Clients subscribe to channels with JSON messages; the optional symbol query parameter subscribes
+// to that symbol's ticker, and the optional token query parameter authenticates for private channels.
 func (h *WebSocketHandler) HandlePriceStream(c *websocket.Conn) {
-	// Get symbol from query parameters
-	symbol := c.Query("symbol", "BTCUSDT")
+	symbol := strings.ToUpper(c.Query("symbol"))
 	exchange := c.Query("exchange", "binance")
 
-	log.Printf("WebSocket connection established for %s on %s", symbol, exchange)
-
-	// Add client to streamer
-	h.priceStreamer.AddClient(c)
+	// Market data comes from Binance, which also prices the paper exchange
+	if exchange != "binance" && exchange != "paper" {
+		c.WriteJSON(fiber.Map{"type": service.FrameError, "error": "price streaming is not available for " + exchange})
+		c.Close()
+		return
+	}
 
-	// Start streaming in a goroutine
-	go h.priceStreamer.StartStreaming(context.Background(), symbol, exchange)
+	log.Printf("WebSocket connection established on %s", exchange)
+	h.priceStreamer.Serve(c, h.authenticate, c.Query("token"), symbol)
+}
 
-	// Keep connection alive and handle disconnections
-	for {
-		_, _, err := c.ReadMessage()
-		if err != nil {
-			log.Printf("WebSocket error: %v", err)
-			h.priceStreamer.RemoveClient(c)
-			break
-		}
+// authenticate validates a JWT sent on the socket and returns its user ID
+func (h *WebSocketHandler) authenticate(token string) (int, error) {
+	claims, err := middleware.ParseToken(h.jwtSecret, strings.TrimPrefix(token, "Bearer "))
+	if err != nil {
+		return 0, err
 	}
+	return claims.UserID, nil
 }
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/middleware"
 	"github.com/ratheeshkumar25/forex_bot/backend/pkg/service"
 )
 
@@ -53,19 +54,22 @@ func (h *WorkerHandler) StopWorker(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Worker stopped successfully for " + symbol})
 }
 
-// GetStatus handles the GET /api/worker/status endpoint.
+// GetStatus handles the GET /api/worker/status endpoint, listing the running workers and the consensus of their timeframes.
 func (h *WorkerHandler) GetStatus(c *fiber.Ctx) error {
 	activeWorkers := h.manager.GetStatus()
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"active_workers": len(activeWorkers),
 		"monitoring":     activeWorkers,
+		"consensus":      h.manager.GetConsensus(),
 	})
 }
 
 // RegisterRoutes sets up all the routes for the worker control API.
-func (h *WorkerHandler) RegisterRoutes(app *fiber.App) {
-	workerAPI := app.Group("/api/worker")
+// Workers trade for every user subscribed to their symbol, so only admins may start and stop them.
+func (h *WorkerHandler) RegisterRoutes(app *fiber.App, jwtSecret string, adminUsernames []string) {
+	app.Get("/api/worker/status", h.GetStatus)
+
+	workerAPI := app.Group("/api/worker", middleware.JWTMiddleware(jwtSecret), middleware.AdminMiddleware(adminUsernames))
 	workerAPI.Post("/start", h.StartWorker)
 	workerAPI.Post("/stop", h.StopWorker)
-	workerAPI.Get("/status", h.GetStatus)
 }
@@ -0,0 +1,164 @@
+package backtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/exchange"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/strategy"
+)
+
+// Config holds the parameters of a single backtest run.
+type Config struct {
+	Symbol         string
+	Interval       string // Kline interval to replay, e.g. "1h"
+	Start          time.Time
+	End            time.Time
+	InitialCapital float64 // Starting capital in the quote asset
+	BaseAllocation float64 // Fraction of the capital converted to the base asset at the first open, 0..1
+	FeeRate        float64 // Fee charged on each fill as a fraction of the notional
+	SlippageRate   float64 // Adverse price move applied to each fill as a fraction
+}
+
+// Trade is a simulated fill produced during a backtest.
+type Trade struct {
+	Time       time.Time `json:"time"`
+	Side       string    `json:"side"`
+	Price      float64   `json:"price"`
+	Quantity   float64   `json:"quantity"`
+	Fee        float64   `json:"fee"`
+	ProfitLoss float64   `json:"profit_loss"` // Realised PnL against the average entry price; zero for buys
+}
+
+// EquityPoint is the marked-to-market account value at the close of a bar.
+type EquityPoint struct {
+	Time   time.Time `json:"time"`
+	Equity float64   `json:"equity"`
+}
+
+// Result holds the outcome and performance statistics of a backtest run.
+type Result struct {
+	Symbol         string        `json:"symbol"`
+	Interval       string        `json:"interval"`
+	Start          time.Time     `json:"start"`
+	End            time.Time     `json:"end"`
+	InitialCapital float64       `json:"initial_capital"`
+	FinalEquity    float64       `json:"final_equity"`
+	TotalReturn    float64       `json:"total_return"` // Percentage
+	MaxDrawdown    float64       `json:"max_drawdown"` // Percentage, peak to trough
+	SharpeRatio    float64       `json:"sharpe_ratio"` // Annualised, zero risk-free rate
+	WinRate        float64       `json:"win_rate"`     // Percentage of closing trades with positive PnL
+	Trades         []Trade       `json:"trades"`
+	EquityCurve    []EquityPoint `json:"equity_curve"`
+	Warnings       []string      `json:"warnings,omitempty"`
+}
+
+// maxWarnings caps the number of strategy errors recorded in a Result.
+const maxWarnings = 20
+
+// CandleSource provides historical candles for a symbol and interval, oldest first.
+type CandleSource interface {
+	FetchCandleRange(ctx context.Context, symbol, interval string, start, end time.Time) ([]model.Candle, error)
+}
+
+// Engine replays historical candles through a strategy on a simulated exchange.
+type Engine struct {
+	source CandleSource
+}
+
+// NewEngine creates a new backtesting engine reading candles from source.
+func NewEngine(source CandleSource) *Engine {
+	return &Engine{source: source}
+}
+
+// Run backtests strat over the configured date range and returns its performance.
+func (e *Engine) Run(ctx context.Context, strat strategy.Strategy, cfg Config) (*Result, error) {
+	if cfg.InitialCapital <= 0 {
+		return nil, fmt.Errorf("initial capital must be positive")
+	}
+	if !cfg.End.After(cfg.Start) {
+		return nil, fmt.Errorf("backtest end must be after its start")
+	}
+	base, quote, err := exchange.SplitSymbol(cfg.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	barLength, err := model.IntervalDuration(cfg.Interval)
+	if err != nil {
+		return nil, err
+	}
+
+	candles, err := e.source.FetchCandleRange(ctx, cfg.Symbol, cfg.Interval, cfg.Start, cfg.End)
+	if err != nil {
+		return nil, err
+	}
+	if len(candles) == 0 {
+		return nil, fmt.Errorf("no candles available for %s between %s and %s", cfg.Symbol, cfg.Start.Format(time.RFC3339), cfg.End.Format(time.RFC3339))
+	}
+
+	feed := exchange.NewReplayFeed()
+	paper := exchange.NewPaperExchange(feed, exchange.PaperConfig{
+		InitialBalances: map[string]float64{
+			quote: cfg.InitialCapital * (1 - cfg.BaseAllocation),
+			base:  cfg.InitialCapital * cfg.BaseAllocation / candles[0].Open,
+		},
+		FeeRate:      cfg.FeeRate,
+		SlippageRate: cfg.SlippageRate,
+	})
+
+	runner := strategy.NewRunner(strat, paper, cfg.Symbol)
+	paper.OnFill(runner.NotifyPaperFill)
+
+	result := &Result{
+		Symbol:         cfg.Symbol,
+		Interval:       cfg.Interval,
+		Start:          cfg.Start,
+		End:            cfg.End,
+		InitialCapital: cfg.InitialCapital,
+	}
+
+	for _, c := range candles {
+		// Resting orders are matched against the whole range traded during the bar,
+		// then the strategy reacts to its fills and to the closed candle.
+		feed.Set(exchange.PriceData{Symbol: cfg.Symbol, Price: c.Open, Time: c.OpenTime})
+		paper.MatchRange(cfg.Symbol, c.Low, c.High)
+		feed.Set(exchange.PriceData{Symbol: cfg.Symbol, Price: c.Close, Time: c.CloseTime})
+
+		err := errors.Join(runner.Drain(ctx), runner.HandleCandle(ctx, c), runner.Drain(ctx))
+		if err != nil && len(result.Warnings) < maxWarnings {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: %v", c.CloseTime.Format(time.RFC3339), err))
+		}
+
+		free, locked := paper.Balances(0)
+		equity := free[quote] + locked[quote] + (free[base]+locked[base])*c.Close
+		result.EquityCurve = append(result.EquityCurve, EquityPoint{Time: c.CloseTime, Equity: equity})
+	}
+
+	result.Trades = tradesFromFills(paper.Fills(0), cfg.InitialCapital*cfg.BaseAllocation/candles[0].Open, candles[0].Open)
+	computeStats(result, barLength)
+	return result, nil
+}
+
+// tradesFromFills converts paper fills into trades, realising PnL on sells against
+// the running average entry price of the base asset held.
+func tradesFromFills(fills []exchange.PaperFill, initialQty, initialPrice float64) []Trade {
+	position := initialQty
+	avgPrice := initialPrice
+	trades := make([]Trade, 0, len(fills))
+	for _, f := range fills {
+		t := Trade{Time: f.Time, Side: f.Side, Price: f.Price, Quantity: f.Quantity, Fee: f.Fee}
+		if f.Side == "BUY" {
+			cost := position*avgPrice + f.Quantity*f.Price + f.Fee
+			position += f.Quantity
+			avgPrice = cost / position
+		} else {
+			t.ProfitLoss = (f.Price-avgPrice)*f.Quantity - f.Fee
+			position -= f.Quantity
+		}
+		trades = append(trades, t)
+	}
+	return trades
+}
@@ -0,0 +1,80 @@
+package backtest
+
+import (
+	"math"
+	"time"
+)
+
+// computeStats fills in the summary statistics of a result from its equity curve and trades.
+func computeStats(r *Result, barLength time.Duration) {
+	if len(r.EquityCurve) == 0 {
+		return
+	}
+
+	r.FinalEquity = r.EquityCurve[len(r.EquityCurve)-1].Equity
+	r.TotalReturn = (r.FinalEquity - r.InitialCapital) / r.InitialCapital * 100
+	r.MaxDrawdown = maxDrawdown(r.EquityCurve) * 100
+	r.SharpeRatio = sharpeRatio(r.InitialCapital, r.EquityCurve, barLength)
+
+	var closing, wins int
+	for _, t := range r.Trades {
+		if t.Side != "SELL" {
+			continue
+		}
+		closing++
+		if t.ProfitLoss > 0 {
+			wins++
+		}
+	}
+	if closing > 0 {
+		r.WinRate = float64(wins) / float64(closing) * 100
+	}
+}
+
+// maxDrawdown returns the largest peak-to-trough decline of the equity curve as a fraction.
+func maxDrawdown(curve []EquityPoint) float64 {
+	peak := curve[0].Equity
+	worst := 0.0
+	for _, p := range curve {
+		if p.Equity > peak {
+			peak = p.Equity
+		}
+		if peak > 0 {
+			worst = math.Max(worst, (peak-p.Equity)/peak)
+		}
+	}
+	return worst
+}
+
+// sharpeRatio returns the annualised Sharpe ratio of the per-bar returns, assuming a zero risk-free rate.
+func sharpeRatio(initial float64, curve []EquityPoint, barLength time.Duration) float64 {
+	returns := make([]float64, 0, len(curve))
+	prev := initial
+	for _, p := range curve {
+		if prev > 0 {
+			returns = append(returns, p.Equity/prev-1)
+		}
+		prev = p.Equity
+	}
+	if len(returns) < 2 {
+		return 0
+	}
+
+	mean := 0.0
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+
+	variance := 0.0
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	stdDev := math.Sqrt(variance / float64(len(returns)-1))
+	if stdDev == 0 {
+		return 0
+	}
+
+	barsPerYear := float64(365*24*time.Hour) / float64(barLength)
+	return mean / stdDev * math.Sqrt(barsPerYear)
+}
@@ -8,7 +8,10 @@ import (
 	"github.com/ratheeshkumar25/forex_bot/backend/config"
 	"github.com/ratheeshkumar25/forex_bot/backend/pkg/api"
 	"github.com/ratheeshkumar25/forex_bot/backend/pkg/database"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/events"
 	"github.com/ratheeshkumar25/forex_bot/backend/pkg/repository"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/risk"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/secrets"
 	"github.com/ratheeshkumar25/forex_bot/backend/pkg/service"
 	"go.uber.org/fx"
 )
@@ -23,8 +26,9 @@ func Init() {
 			database.NewDatabase,
 
 			// -- Repositories --
-			func(db *database.DB) *repository.UserRepository {
-				return repository.NewUserRepository(db.DB)
+			NewKeyring,
+			func(db *database.DB, keyring *secrets.Keyring) *repository.UserRepository {
+				return repository.NewUserRepository(db.DB, keyring)
 			},
 			func(db *database.DB) *repository.TradeRepository {
 				return repository.NewTradeRepository(db.DB)
@@ -32,10 +36,38 @@ func Init() {
 			func(db *database.DB) *repository.SignalRepository {
 				return repository.NewSignalRepository(db.DB)
 			},
+			func(db *database.DB) *repository.CandleRepository {
+				return repository.NewCandleRepository(db.DB)
+			},
+			func(db *database.DB) *repository.AutoTradeRepository {
+				return repository.NewAutoTradeRepository(db.DB)
+			},
+			func(db *database.DB) *repository.RiskRepository {
+				return repository.NewRiskRepository(db.DB)
+			},
+			func(db *database.DB) *repository.OrderRepository {
+				return repository.NewOrderRepository(db.DB)
+			},
+
+			// -- Events --
+			events.NewBus,
+
+			// -- Risk --
+			risk.NewEngine,
+
+			// -- Exchanges --
+			NewPaperExchange,
+			NewClientFactory,
+			service.NewOrderRecorder,
+			NewExchanges,
 
 			// -- Services --
 			service.NewFetcherService,
+			NewMarketDataHub,
 			service.NewPredictionService,
+			service.NewExecutionService,
+			service.NewConsensusService,
+			service.NewCalibrationService,
 			service.NewWorkerService,
 			service.NewWorkerManager, // The new manager for our workers
 
@@ -63,7 +95,7 @@ func registerHooks(lifecycle fx.Lifecycle, app *fiber.App, cfg *config.Config, p
 			OnStart: func(ctx context.Context) error {
 				// Register routes from both handlers
 				predHandler.RegisterRoutes(app)
-				workerHandler.RegisterRoutes(app)
+				workerHandler.RegisterRoutes(app, cfg.JWTSecret, cfg.AdminUsernames)
 
 				go func() {
 					log.Printf("API server listening on [::]:%s. Use the /api/worker/start endpoint to begin analysis.", cfg.Port)
@@ -3,15 +3,21 @@ package di
 import (
 	"context"
 	"log"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
     "github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/ratheeshkumar25/forex_bot/backend/config"
 	"github.com/ratheeshkumar25/forex_bot/backend/pkg/api"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/backtest"
 	"github.com/ratheeshkumar25/forex_bot/backend/pkg/database"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/events"
 	"github.com/ratheeshkumar25/forex_bot/backend/pkg/exchange"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/marketdata"
 	"github.com/ratheeshkumar25/forex_bot/backend/pkg/predictor"
 	"github.com/ratheeshkumar25/forex_bot/backend/pkg/repository"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/risk"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/secrets"
 	"github.com/ratheeshkumar25/forex_bot/backend/pkg/service"
 	"github.com/ratheeshkumar25/forex_bot/backend/pkg/strategy"
 	"go.uber.org/fx"
@@ -21,40 +27,133 @@ import (
 var Module = fx.Options(
 	config.Module,
 	fx.Provide(database.NewDatabase),
-	fx.Provide(func(db *database.DB) *repository.UserRepository { return repository.NewUserRepository(db.DB) }),
+	fx.Provide(NewKeyring),
+	fx.Provide(func(db *database.DB, keyring *secrets.Keyring) *repository.UserRepository {
+		return repository.NewUserRepository(db.DB, keyring)
+	}),
 	fx.Provide(func(db *database.DB) *repository.TradeRepository { return repository.NewTradeRepository(db.DB) }),
 	fx.Provide(func(db *database.DB) *repository.SignalRepository { return repository.NewSignalRepository(db.DB) }),
+	fx.Provide(func(db *database.DB) *repository.CandleRepository { return repository.NewCandleRepository(db.DB) }),
+	fx.Provide(func(db *database.DB) *repository.BotRepository { return repository.NewBotRepository(db.DB) }),
+	fx.Provide(func(db *database.DB) *repository.AutoTradeRepository { return repository.NewAutoTradeRepository(db.DB) }),
+	fx.Provide(func(db *database.DB) *repository.RiskRepository { return repository.NewRiskRepository(db.DB) }),
+	fx.Provide(func(db *database.DB) *repository.OrderRepository { return repository.NewOrderRepository(db.DB) }),
+	fx.Provide(func(db *database.DB) *repository.ScoringProfileRepository {
+		return repository.NewScoringProfileRepository(db.DB)
+	}),
+	fx.Provide(func(db *database.DB) *repository.MLModelRepository { return repository.NewMLModelRepository(db.DB) }),
+	fx.Provide(events.NewBus),
+	fx.Provide(risk.NewEngine),
+	fx.Provide(NewPaperExchange),
+	fx.Provide(NewClientFactory),
+	fx.Provide(service.NewOrderRecorder),
 	fx.Provide(NewExchanges),
 	fx.Provide(NewStrategies),
+	fx.Provide(service.NewFetcherService),
+	fx.Provide(service.NewBackfillService),
+	fx.Provide(NewMarketDataHub),
+	fx.Provide(func(fetcher *service.FetcherService) *backtest.Engine { return backtest.NewEngine(fetcher) }),
 	fx.Provide(predictor.NewPredictor),
 	fx.Provide(service.NewPriceStreamer),
-	fx.Provide(func(exchanges map[string]exchange.Exchange, strategies map[string]strategy.Strategy, pred *predictor.Predictor, tradeRepo *repository.TradeRepository, signalRepo *repository.SignalRepository) *api.Handler {
-		return api.NewHandler(exchanges, strategies, pred, tradeRepo, signalRepo)
+	fx.Provide(service.NewBotManager),
+	fx.Provide(service.NewPredictionService),
+	fx.Provide(service.NewExecutionService),
+	fx.Provide(service.NewConsensusService),
+	fx.Provide(service.NewCalibrationService),
+	fx.Provide(service.NewWorkerManager),
+	fx.Provide(service.NewPositionManager),
+	fx.Provide(service.NewSignalEvaluator),
+	fx.Provide(service.NewMLService),
+	fx.Provide(func(exchanges map[string]exchange.Exchange, strategies map[string]strategy.Factory, pred *predictor.Predictor, tradeRepo *repository.TradeRepository, signalRepo *repository.SignalRepository, bus *events.Bus) *api.Handler {
+		return api.NewHandler(exchanges, strategies, pred, tradeRepo, signalRepo, bus)
 	}),
 	fx.Provide(api.NewAuthHandler),
 	fx.Provide(func(cfg *config.Config) string { return cfg.JWTSecret }),
 	fx.Provide(api.NewWebSocketHandler),
+	fx.Provide(api.NewCandleHandler),
+	fx.Provide(api.NewBotHandler),
+	fx.Provide(api.NewOrderHandler),
+	fx.Provide(api.NewPredictionHandler),
+	fx.Provide(api.NewWorkerHandler),
+	fx.Provide(api.NewAutoTradeHandler),
+	fx.Provide(api.NewRiskHandler),
+	fx.Provide(api.NewTradeHandler),
+	fx.Provide(api.NewMLHandler),
 	fx.Provide(NewApp),
+	fx.Invoke(RegisterIndicators),
 	fx.Invoke(SetupRoutes),
+	fx.Invoke(StartPaperExchange),
+	fx.Invoke(StartBots),
+	fx.Invoke(StartWorkers),
+	fx.Invoke(StartPositionManager),
+	fx.Invoke(StartSignalEvaluator),
 	fx.Invoke(StartServer),
 )
 
-// NewExchanges provides exchange instances
-func NewExchanges(cfg *config.Config) map[string]exchange.Exchange {
+// NewKeyring provides the keyring that encrypts exchange credentials, or nil when no master key is configured
+func NewKeyring(cfg *config.Config) (*secrets.Keyring, error) {
+	if len(cfg.CredentialKeys) == 0 {
+		return nil, nil
+	}
+	return secrets.NewKeyring(cfg.CredentialKeys)
+}
+
+// NewPaperExchange provides the paper trading exchange, priced from public Binance market data
+func NewPaperExchange(cfg *config.Config) *exchange.PaperExchange {
+	return exchange.NewPaperExchange(exchange.NewBinanceExchange("", ""), exchange.PaperConfig{
+		InitialBalances: cfg.PaperInitialBalances,
+		FeeRate:         cfg.PaperFeeRate,
+		SlippageRate:    cfg.PaperSlippageRate,
+	})
+}
+
+// NewMarketDataHub provides the market data hub, streaming from the Binance WebSockets with REST polling as the fallback
+func NewMarketDataHub(lc fx.Lifecycle, fetcher *service.FetcherService) *marketdata.Hub {
+	hub := marketdata.NewHub(marketdata.NewBinanceSource(), marketdata.NewPollingSource(exchange.NewBinanceExchange("", ""), fetcher))
+	lc.Append(fx.Hook{
+		OnStop: func(context.Context) error {
+			hub.Close()
+			return nil
+		},
+	})
+	return hub
+}
+
+// NewClientFactory provides the factory of per-user exchange clients, loading credentials from the users table
+func NewClientFactory(userRepo *repository.UserRepository) *exchange.ClientFactory {
+	return exchange.NewClientFactory(func(userID int) (exchange.Credentials, error) {
+		user, err := userRepo.GetUserByID(userID)
+		if err != nil {
+			return exchange.Credentials{}, err
+		}
+		return exchange.Credentials{
+			BinanceAPIKey:    user.BinanceAPIKey,
+			BinanceSecretKey: user.BinanceSecretKey,
+			SolanaPrivateKey: user.SolanaPrivateKey,
+		}, nil
+	})
+}
+
+// NewExchanges provides exchange instances, each guarded by the risk engine and with its orders recorded.
+// Binance and Solana calls go to the client of the user set on the call context.
+func NewExchanges(factory *exchange.ClientFactory, paper *exchange.PaperExchange, riskEngine *risk.Engine, recorder *service.OrderRecorder) map[string]exchange.Exchange {
 	exchanges := make(map[string]exchange.Exchange)
-	if cfg.BinanceAPIKey != "" && cfg.BinanceSecret != "" {
-		exchanges["binance"] = exchange.NewBinanceExchange(cfg.BinanceAPIKey, cfg.BinanceSecret)
+	for _, name := range factory.Names() {
+		exchanges[name] = factory.Exchange(name)
+	}
+	// Simulated exchange with per-user virtual balances, selected by passing exchange "paper"
+	exchanges["paper"] = paper
+	for name, ex := range exchanges {
+		exchanges[name] = riskEngine.Wrap(name, recorder.Wrap(name, ex))
 	}
-	// Add Solana exchange
-	exchanges["solana"] = exchange.NewSolanaExchange()
 	return exchanges
 }
 
-// NewStrategies provides strategy instances
-func NewStrategies() map[string]strategy.Strategy {
-	strategies := make(map[string]strategy.Strategy)
-	strategies["grid"] = &strategy.GridStrategy{GridLevels: 5, GridSize: 1.0}
-	strategies["dca"] = &strategy.DCA{Interval: 86400000, Amount: 100} // 1 day in milliseconds, $100
+// NewStrategies provides strategy factories with default parameters
+func NewStrategies() map[string]strategy.Factory {
+	strategies := make(map[string]strategy.Factory)
+	strategies["grid"] = func() strategy.Strategy { return &strategy.GridStrategy{GridLevels: 5, GridSize: 1.0, TotalInvestment: 1000} }
+	strategies["dca"] = func() strategy.Strategy { return &strategy.DCA{Cadence: "24h", Amount: 100} } // $100 every day
 	return strategies
 }
 
@@ -65,9 +164,102 @@ func NewApp() *fiber.App {
     return app
 }
 
+// RegisterIndicators registers the indicators backed by other services, so scoring profiles can use them
+func RegisterIndicators(predictor *service.PredictionService, mlSvc *service.MLService) {
+	predictor.RegisterIndicator("ml", mlSvc.Indicator())
+}
+
 // SetupRoutes sets up the routes
-func SetupRoutes(app *fiber.App, handler *api.Handler, authHandler *api.AuthHandler, wsHandler *api.WebSocketHandler, cfg *config.Config) {
-	api.SetupRoutes(app, handler, authHandler, wsHandler, cfg.JWTSecret)
+func SetupRoutes(app *fiber.App, handler *api.Handler, authHandler *api.AuthHandler, wsHandler *api.WebSocketHandler, candleHandler *api.CandleHandler, botHandler *api.BotHandler, orderHandler *api.OrderHandler, autoTradeHandler *api.AutoTradeHandler, riskHandler *api.RiskHandler, tradeHandler *api.TradeHandler, mlHandler *api.MLHandler, predHandler *api.PredictionHandler, workerHandler *api.WorkerHandler, cfg *config.Config) {
+	api.SetupRoutes(app, handler, authHandler, wsHandler, candleHandler, botHandler, orderHandler, autoTradeHandler, riskHandler, tradeHandler, mlHandler, cfg.JWTSecret, cfg.AdminUsernames)
+	predHandler.RegisterRoutes(app)
+	workerHandler.RegisterRoutes(app, cfg.JWTSecret, cfg.AdminUsernames)
+}
+
+// StartPaperExchange runs the paper exchange matching loop with fx lifecycle
+func StartPaperExchange(lc fx.Lifecycle, paper *exchange.PaperExchange) {
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go paper.Run(ctx, 2*time.Second)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+}
+
+// StartBots resumes the bots that were running before the last shutdown with fx lifecycle
+func StartBots(lc fx.Lifecycle, manager *service.BotManager) {
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			if err := manager.ResumeBots(); err != nil {
+				log.Printf("Error resuming bots: %v", err)
+			}
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			manager.StopAll()
+			return nil
+		},
+	})
+}
+
+// StartWorkers starts the analysis workers for every symbol with auto trade subscriptions with fx lifecycle
+func StartWorkers(lc fx.Lifecycle, manager *service.WorkerManager, subRepo *repository.AutoTradeRepository) {
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			symbols, err := subRepo.GetSubscribedSymbols()
+			if err != nil {
+				log.Printf("Error loading auto trade symbols: %v", err)
+				return nil
+			}
+			for _, symbol := range symbols {
+				if err := manager.StartWorker(symbol); err != nil {
+					log.Printf("Error starting worker for %s: %v", symbol, err)
+				}
+			}
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			for _, symbol := range manager.GetStatus() {
+				manager.StopWorker(symbol)
+			}
+			return nil
+		},
+	})
+}
+
+// StartPositionManager runs the position manager, which closes open trades at their exits, with fx lifecycle
+func StartPositionManager(lc fx.Lifecycle, manager *service.PositionManager) {
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go manager.Start(ctx)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+}
+
+// StartSignalEvaluator runs the signal evaluator, which records the outcome of stored signals, with fx lifecycle
+func StartSignalEvaluator(lc fx.Lifecycle, evaluator *service.SignalEvaluator) {
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go evaluator.Start(ctx)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	})
 }
 
 // StartServer starts the server with fx lifecycle
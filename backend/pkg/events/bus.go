@@ -0,0 +1,104 @@
+package events
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Event types
+const (
+	SignalCreated = "signal.created" // A strategy or the execution pipeline produced a signal; Data is a *model.Signal
+	OrderPlaced   = "order.placed"   // An order was placed for a user; Data is a *model.Order
+	OrderFilled   = "order.filled"   // A user's order filled completely; Data is a *model.Order
+	OrderUpdated  = "order.updated"  // Any other change of an order's status or fills; Data is a *model.Order
+	TradeOpened   = "trade.opened"   // The execution pipeline opened a trade; Data is a *model.DBTrade
+	TradeUpdated  = "trade.updated"  // Exit rules changed an open trade or closed part of it; Data is the *model.DBTrade
+	TradeClosed   = "trade.closed"   // An open trade was closed; Data is the closed *model.DBTrade
+	WorkerStarted = "worker.started" // An analysis worker started on Symbol
+	WorkerStopped = "worker.stopped" // An analysis worker stopped on Symbol
+)
+
+// subscriberBuffer is the number of events buffered per subscriber; a slow subscriber misses events beyond it.
+const subscriberBuffer = 256
+
+// Event is something that happened in the application.
+type Event struct {
+	Type   string      `json:"type"`
+	UserID int         `json:"user_id,omitempty"` // User the event belongs to; 0 for events that concern everyone
+	Symbol string      `json:"symbol,omitempty"`
+	Data   interface{} `json:"data,omitempty"`
+	Time   time.Time   `json:"time"`
+}
+
+// Subscription receives the events of the types it subscribed to until it is closed.
+type Subscription struct {
+	C     <-chan Event
+	bus   *Bus
+	ch    chan Event
+	types map[string]bool // nil receives every type
+	once  sync.Once
+}
+
+// Close stops the subscription and closes C.
+func (s *Subscription) Close() {
+	s.once.Do(func() { s.bus.unsubscribe(s) })
+}
+
+// Bus is an in-process publish/subscribe bus. Subsystems publish what happens, such as signals, orders
+// and trades, without knowing who listens; the WebSocket layer and any other subsystem subscribe to it.
+// Publishing never blocks: each subscriber has a buffer, and events are dropped for a subscriber whose buffer is full.
+type Bus struct {
+	subs map[*Subscription]bool
+	mu   sync.RWMutex
+}
+
+// NewBus creates a new event bus.
+func NewBus() *Bus {
+	return &Bus{subs: make(map[*Subscription]bool)}
+}
+
+// Subscribe subscribes to events of the given types, or to every event when no type is given.
+func (b *Bus) Subscribe(types ...string) *Subscription {
+	ch := make(chan Event, subscriberBuffer)
+	sub := &Subscription{C: ch, bus: b, ch: ch}
+	if len(types) > 0 {
+		sub.types = make(map[string]bool, len(types))
+		for _, t := range types {
+			sub.types[t] = true
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subs[sub] = true
+	return sub
+}
+
+// Publish delivers an event to its subscribers, stamping it with the current time if it has none.
+func (b *Bus) Publish(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for sub := range b.subs {
+		if sub.types != nil && !sub.types[e.Type] {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+			log.Printf("Event bus: subscriber is not keeping up, dropped %s event", e.Type)
+		}
+	}
+}
+
+func (b *Bus) unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.subs[sub] {
+		delete(b.subs, sub)
+		close(sub.ch)
+	}
+}
@@ -2,20 +2,36 @@ package exchange
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"strconv"
+	"time"
 
 	"github.com/adshao/go-binance/v2"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
 )
 
+// binanceSymbolsTTL is how long Binance trading rules are cached before they are reloaded.
+const binanceSymbolsTTL = time.Hour
+
+// binanceSymbols caches the Binance exchange info. It is public data, so one cache serves every client.
+var binanceSymbols = NewSymbolCache(loadBinanceSymbols, binanceSymbolsTTL)
+
 // BinanceExchange implements the Exchange interface for Binance
 type BinanceExchange struct {
-	client *binance.Client
+	client  *binance.Client
+	symbols *SymbolCache
 }
 
 // NewBinanceExchange creates a new Binance exchange instance
 func NewBinanceExchange(apiKey, secret string) Exchange {
 	client := binance.NewClient(apiKey, secret)
-	return &BinanceExchange{client: client}
+	return &BinanceExchange{client: client, symbols: binanceSymbols}
+}
+
+// SymbolInfo returns the trading rules of a symbol from the cached exchange info
+func (b *BinanceExchange) SymbolInfo(ctx context.Context, symbol string) (SymbolInfo, error) {
+	return b.symbols.Get(ctx, symbol)
 }
 
 // GetPrice retrieves the current price for a symbol
@@ -34,14 +50,164 @@ func (b *BinanceExchange) GetPrice(ctx context.Context, symbol string) (float64,
 	return price, nil
 }
 
-// PlaceOrder places an order on Binance
-func (b *BinanceExchange) PlaceOrder(ctx context.Context, symbol string, side string, quantity float64, price float64) error {
-	order := b.client.NewCreateOrderService().Symbol(symbol).
-		Side(binance.SideType(side)).Type(binance.OrderTypeLimit).
-		TimeInForce(binance.TimeInForceTypeGTC).Quantity(strconv.FormatFloat(quantity, 'f', -1, 64)).
-		Price(strconv.FormatFloat(price, 'f', -1, 64))
-	_, err := order.Do(ctx)
-	return err
+// PlaceOrder places an order on Binance and returns it with any immediate fills
+// The quantity and prices are rounded to the symbol's step and tick sizes and checked against its limits first.
+func (b *BinanceExchange) PlaceOrder(ctx context.Context, req OrderRequest) (*model.Order, error) {
+	if err := req.Normalize(); err != nil {
+		return nil, err
+	}
+	info, err := b.symbols.Get(ctx, req.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	var marketPrice float64
+	if req.Type == OrderTypeMarket && info.ApplyMinToMarket && info.MinNotional > 0 {
+		if marketPrice, err = b.GetPrice(ctx, req.Symbol); err != nil {
+			return nil, fmt.Errorf("failed to price market order: %w", err)
+		}
+	}
+	if err := info.NormalizeOrder(&req, marketPrice); err != nil {
+		return nil, err
+	}
+	svc := b.client.NewCreateOrderService().Symbol(req.Symbol).
+		Side(binance.SideType(req.Side)).Type(binance.OrderType(req.Type)).
+		Quantity(formatFloat(req.Quantity)).
+		NewClientOrderID(req.ClientOrderID).NewOrderRespType(binance.NewOrderRespTypeFULL)
+	if req.Type != OrderTypeMarket {
+		svc = svc.TimeInForce(binance.TimeInForceType(req.TimeInForce)).Price(formatFloat(req.Price))
+	}
+	if req.StopPrice > 0 {
+		svc = svc.StopPrice(formatFloat(req.StopPrice))
+	}
+	res, err := svc.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	order := &model.Order{
+		Exchange:        "binance",
+		Symbol:          res.Symbol,
+		Side:            string(res.Side),
+		Type:            string(res.Type),
+		ClientOrderID:   res.ClientOrderID,
+		ExchangeOrderID: strconv.FormatInt(res.OrderID, 10),
+		Quantity:        parseFloat(res.OrigQuantity),
+		Price:           parseFloat(res.Price),
+		StopPrice:       req.StopPrice,
+		TimeInForce:     string(res.TimeInForce),
+		Status:          string(res.Status),
+		CreatedAt:       time.UnixMilli(res.TransactTime),
+		UpdatedAt:       time.UnixMilli(res.TransactTime),
+	}
+	setExecution(order, res.ExecutedQuantity, res.CummulativeQuoteQuantity)
+	for _, f := range res.Fills {
+		addFee(order, parseFloat(f.Commission), f.CommissionAsset)
+	}
+	return order, nil
+}
+
+// PlaceOCO places a one-cancels-the-other bracket and returns its limit leg and stop leg
+func (b *BinanceExchange) PlaceOCO(ctx context.Context, req OCORequest) ([]*model.Order, error) {
+	if err := req.Normalize(); err != nil {
+		return nil, err
+	}
+	info, err := b.symbols.Get(ctx, req.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	if err := info.NormalizeOCO(&req); err != nil {
+		return nil, err
+	}
+	res, err := b.client.NewCreateOCOService().Symbol(req.Symbol).
+		Side(binance.SideType(req.Side)).Quantity(formatFloat(req.Quantity)).
+		Price(formatFloat(req.Price)).StopPrice(formatFloat(req.StopPrice)).
+		StopLimitPrice(formatFloat(req.StopLimitPrice)).StopLimitTimeInForce(binance.TimeInForceTypeGTC).
+		ListClientOrderID(req.ListClientOrderID).LimitClientOrderID(req.LimitClientOrderID).
+		StopClientOrderID(req.StopClientOrderID).NewOrderRespType(binance.NewOrderRespTypeFULL).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	listID := strconv.FormatInt(res.OrderListID, 10)
+	orders := make([]*model.Order, 0, len(res.OrderReports))
+	for _, r := range res.OrderReports {
+		order := &model.Order{
+			Exchange:        "binance",
+			Symbol:          r.Symbol,
+			Side:            string(r.Side),
+			Type:            string(r.Type),
+			ClientOrderID:   r.ClientOrderID,
+			ExchangeOrderID: strconv.FormatInt(r.OrderID, 10),
+			Quantity:        parseFloat(r.OrigQuantity),
+			Price:           parseFloat(r.Price),
+			StopPrice:       parseFloat(r.StopPrice),
+			TimeInForce:     string(r.TimeInForce),
+			OrderListID:     listID,
+			Status:          string(r.Status),
+			CreatedAt:       time.UnixMilli(r.TransactionTime),
+			UpdatedAt:       time.UnixMilli(r.TransactionTime),
+		}
+		setExecution(order, r.ExecutedQuantity, r.CummulativeQuoteQuantity)
+		orders = append(orders, order)
+	}
+	// Binance reports the stop leg first; return the limit leg first
+	sort.SliceStable(orders, func(i, j int) bool { return orders[i].StopPrice == 0 && orders[j].StopPrice != 0 })
+	return orders, nil
+}
+
+// CancelOrder cancels an open order and returns its final state
+func (b *BinanceExchange) CancelOrder(ctx context.Context, symbol string, orderID string) (*model.Order, error) {
+	id, err := strconv.ParseInt(orderID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Binance order ID %q", orderID)
+	}
+	if _, err := b.client.NewCancelOrderService().Symbol(symbol).OrderID(id).Do(ctx); err != nil {
+		return nil, err
+	}
+	// The cancel response has no fees, so read the order back like GetOrder does
+	return b.GetOrder(ctx, symbol, orderID)
+}
+
+// GetOrder returns the current state of an order, with the fees of its fills
+func (b *BinanceExchange) GetOrder(ctx context.Context, symbol string, orderID string) (*model.Order, error) {
+	id, err := strconv.ParseInt(orderID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Binance order ID %q", orderID)
+	}
+	res, err := b.client.NewGetOrderService().Symbol(symbol).OrderID(id).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	order := toOrder(res)
+	if order.FilledQuantity > 0 {
+		trades, err := b.client.NewListTradesService().Symbol(symbol).OrderId(id).Do(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get fills of order %s: %w", orderID, err)
+		}
+		for _, t := range trades {
+			addFee(order, parseFloat(t.Commission), t.CommissionAsset)
+		}
+	}
+	return order, nil
+}
+
+// ListOpenOrders returns the open orders on symbol, or on every symbol when symbol is empty.
+// Fees are not included; use GetOrder for a single order's fees.
+func (b *BinanceExchange) ListOpenOrders(ctx context.Context, symbol string) ([]*model.Order, error) {
+	svc := b.client.NewListOpenOrdersService()
+	if symbol != "" {
+		svc = svc.Symbol(symbol)
+	}
+	res, err := svc.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	orders := make([]*model.Order, 0, len(res))
+	for _, o := range res {
+		orders = append(orders, toOrder(o))
+	}
+	return orders, nil
 }
 
 // GetVolume retrieves the trading volume for a symbol over a timeframe
@@ -78,3 +244,133 @@ func (b *BinanceExchange) GetBalance(ctx context.Context, asset string) (float64
 	}
 	return 0, nil
 }
+
+// GetLockedBalance retrieves the amount of an asset reserved by open orders
+func (b *BinanceExchange) GetLockedBalance(ctx context.Context, asset string) (float64, error) {
+	account, err := b.client.NewGetAccountService().Do(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, balance := range account.Balances {
+		if balance.Asset == asset {
+			return strconv.ParseFloat(balance.Locked, 64)
+		}
+	}
+	return 0, nil
+}
+
+// CancelAllOrders cancels the open orders of every symbol on the account
+func (b *BinanceExchange) CancelAllOrders(ctx context.Context) error {
+	orders, err := b.client.NewListOpenOrdersService().Do(ctx)
+	if err != nil {
+		return err
+	}
+	cancelled := make(map[string]bool)
+	for _, o := range orders {
+		if cancelled[o.Symbol] {
+			continue
+		}
+		if _, err := b.client.NewCancelOpenOrdersService().Symbol(o.Symbol).Do(ctx); err != nil {
+			return err
+		}
+		cancelled[o.Symbol] = true
+	}
+	return nil
+}
+
+// toOrder converts a Binance order into a model.Order
+func toOrder(o *binance.Order) *model.Order {
+	order := &model.Order{
+		Exchange:        "binance",
+		Symbol:          o.Symbol,
+		Side:            string(o.Side),
+		Type:            string(o.Type),
+		ClientOrderID:   o.ClientOrderID,
+		ExchangeOrderID: strconv.FormatInt(o.OrderID, 10),
+		Quantity:        parseFloat(o.OrigQuantity),
+		Price:           parseFloat(o.Price),
+		StopPrice:       parseFloat(o.StopPrice),
+		TimeInForce:     string(o.TimeInForce),
+		Status:          string(o.Status),
+		CreatedAt:       time.UnixMilli(o.Time),
+		UpdatedAt:       time.UnixMilli(o.UpdateTime),
+	}
+	if o.OrderListId >= 0 {
+		order.OrderListID = strconv.FormatInt(o.OrderListId, 10)
+	}
+	setExecution(order, o.ExecutedQuantity, o.CummulativeQuoteQuantity)
+	return order
+}
+
+// setExecution sets the filled quantity and the average price from the executed base and quote quantities
+func setExecution(order *model.Order, executed, cumulativeQuote string) {
+	order.FilledQuantity = parseFloat(executed)
+	if order.FilledQuantity > 0 {
+		order.AvgPrice = parseFloat(cumulativeQuote) / order.FilledQuantity
+	}
+}
+
+// addFee adds a fill's commission to the order. Binance may charge in the base, quote or BNB;
+// the fee is reported in the asset of the first fill and commissions in other assets are left out.
+func addFee(order *model.Order, amount float64, asset string) {
+	if order.FeeAsset == "" {
+		order.FeeAsset = asset
+	}
+	if asset == order.FeeAsset {
+		order.Fee += amount
+	}
+}
+
+// loadBinanceSymbols loads the trading rules of every Binance spot symbol from the public exchange info
+func loadBinanceSymbols(ctx context.Context) (map[string]SymbolInfo, error) {
+	info, err := binance.NewClient("", "").NewExchangeInfoService().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	symbols := make(map[string]SymbolInfo, len(info.Symbols))
+	for _, s := range info.Symbols {
+		si := SymbolInfo{
+			Symbol:     s.Symbol,
+			BaseAsset:  s.BaseAsset,
+			QuoteAsset: s.QuoteAsset,
+			OCOAllowed: s.OcoAllowed,
+		}
+		if f := s.PriceFilter(); f != nil {
+			si.TickSize, si.MinPrice, si.MaxPrice = parseFloat(f.TickSize), parseFloat(f.MinPrice), parseFloat(f.MaxPrice)
+		}
+		if f := s.LotSizeFilter(); f != nil {
+			si.StepSize, si.MinQty, si.MaxQty = parseFloat(f.StepSize), parseFloat(f.MinQuantity), parseFloat(f.MaxQuantity)
+		}
+		if f := s.NotionalFilter(); f != nil {
+			si.MinNotional, si.ApplyMinToMarket = parseFloat(f.MinNotional), f.ApplyMinToMarket
+		}
+		// Older symbols still publish the legacy MIN_NOTIONAL filter
+		for _, f := range s.Filters {
+			if f["filterType"] == "MIN_NOTIONAL" && si.MinNotional == 0 {
+				minNotional, _ := f["minNotional"].(string)
+				applyToMarket, _ := f["applyToMarket"].(bool)
+				si.MinNotional, si.ApplyMinToMarket = parseFloat(minNotional), applyToMarket
+			}
+		}
+		symbols[s.Symbol] = si
+	}
+	return symbols, nil
+}
+
+// parseFloat parses a decimal string from the Binance API, treating malformed values as 0
+func parseFloat(s string) float64 {
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
+
+// CheckCredentials verifies that the API keys are accepted by Binance and allowed to trade
+func (b *BinanceExchange) CheckCredentials(ctx context.Context) error {
+	account, err := b.client.NewGetAccountService().Do(ctx)
+	if err != nil {
+		return err
+	}
+	if !account.CanTrade {
+		return fmt.Errorf("API key does not have spot trading permission")
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package exchange
+
+import "context"
+
+type contextKey string
+
+const userIDKey contextKey = "exchange_user_id"
+
+// WithUserID returns a copy of ctx that carries the ID of the user an exchange call is made for.
+// Exchanges that keep per-user state, such as PaperExchange, use it to pick the right account.
+func WithUserID(ctx context.Context, userID int) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+// UserIDFromContext extracts the user ID set by WithUserID, or 0 if none was set.
+func UserIDFromContext(ctx context.Context) int {
+	if userID, ok := ctx.Value(userIDKey).(int); ok {
+		return userID
+	}
+	return 0
+}
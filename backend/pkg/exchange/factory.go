@@ -0,0 +1,253 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+)
+
+// ErrNoCredentials is returned when a user has not saved keys for the exchange a call needs.
+var ErrNoCredentials = errors.New("no exchange credentials saved")
+
+// Credentials are the exchange keys a user has stored.
+type Credentials struct {
+	BinanceAPIKey    string
+	BinanceSecretKey string
+	SolanaPrivateKey string
+}
+
+// CredentialLoader loads the stored credentials of a user.
+type CredentialLoader func(userID int) (Credentials, error)
+
+// ClientFactory builds exchange clients from each user's stored credentials and caches them per user.
+// Market data does not need an account, so it is served by one shared unauthenticated client per exchange.
+type ClientFactory struct {
+	load    CredentialLoader
+	public  map[string]Exchange
+	clients map[int]map[string]Exchange
+	mu      sync.Mutex
+}
+
+// NewClientFactory creates a factory for the "binance" and "solana" exchanges.
+func NewClientFactory(load CredentialLoader) *ClientFactory {
+	return &ClientFactory{
+		load: load,
+		public: map[string]Exchange{
+			"binance": NewBinanceExchange("", ""),
+			"solana":  NewSolanaExchange(),
+		},
+		clients: make(map[int]map[string]Exchange),
+	}
+}
+
+// Names returns the exchanges the factory builds clients for.
+func (f *ClientFactory) Names() []string {
+	names := make([]string, 0, len(f.public))
+	for name := range f.public {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Client returns the cached client of a user on an exchange, building it from the stored credentials on first use.
+func (f *ClientFactory) Client(userID int, name string) (Exchange, error) {
+	if _, ok := f.public[name]; !ok {
+		return nil, fmt.Errorf("exchange %s not found", name)
+	}
+	if userID == 0 {
+		return nil, fmt.Errorf("%s: %w", name, ErrNoCredentials)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if ex, ok := f.clients[userID][name]; ok {
+		return ex, nil
+	}
+
+	creds, err := f.load(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load credentials of user %d: %w", userID, err)
+	}
+	ex, err := newClient(name, creds)
+	if err != nil {
+		return nil, err
+	}
+	if f.clients[userID] == nil {
+		f.clients[userID] = make(map[string]Exchange)
+	}
+	f.clients[userID][name] = ex
+	return ex, nil
+}
+
+// CheckAccess returns an error when the user cannot trade on an exchange the factory manages.
+// Exchanges that need no credentials, such as paper, are always accessible.
+func (f *ClientFactory) CheckAccess(userID int, name string) error {
+	if _, ok := f.public[name]; !ok {
+		return nil
+	}
+	_, err := f.Client(userID, name)
+	return err
+}
+
+// Invalidate drops the cached clients of a user, so the next call picks up newly saved credentials.
+func (f *ClientFactory) Invalidate(userID int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.clients, userID)
+}
+
+// Validate checks the credentials that are set before they are saved.
+// Binance keys are verified against the account endpoint; a Solana key must parse as a wallet key.
+func (f *ClientFactory) Validate(ctx context.Context, creds Credentials) error {
+	if (creds.BinanceAPIKey == "") != (creds.BinanceSecretKey == "") {
+		return fmt.Errorf("both the Binance API key and secret key are required")
+	}
+	if creds.BinanceAPIKey != "" {
+		binance := NewBinanceExchange(creds.BinanceAPIKey, creds.BinanceSecretKey).(*BinanceExchange)
+		if err := binance.CheckCredentials(ctx); err != nil {
+			return fmt.Errorf("invalid Binance API keys: %w", err)
+		}
+	}
+	if creds.SolanaPrivateKey != "" {
+		if _, err := NewSolanaWallet(creds.SolanaPrivateKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Exchange returns an Exchange that routes each call to the client of the user set on the call context
+// with WithUserID. Prices and volumes come from the shared public client.
+func (f *ClientFactory) Exchange(name string) Exchange {
+	return &userExchange{name: name, factory: f}
+}
+
+// newClient builds an authenticated client for one exchange.
+func newClient(name string, creds Credentials) (Exchange, error) {
+	switch name {
+	case "binance":
+		if creds.BinanceAPIKey == "" || creds.BinanceSecretKey == "" {
+			return nil, fmt.Errorf("binance: %w", ErrNoCredentials)
+		}
+		return NewBinanceExchange(creds.BinanceAPIKey, creds.BinanceSecretKey), nil
+	case "solana":
+		if creds.SolanaPrivateKey == "" {
+			return nil, fmt.Errorf("solana: %w", ErrNoCredentials)
+		}
+		return NewSolanaWallet(creds.SolanaPrivateKey)
+	}
+	return nil, fmt.Errorf("exchange %s not found", name)
+}
+
+// userExchange dispatches account calls to the client of the user in the call context.
+type userExchange struct {
+	name    string
+	factory *ClientFactory
+}
+
+func (u *userExchange) client(ctx context.Context) (Exchange, error) {
+	return u.factory.Client(UserIDFromContext(ctx), u.name)
+}
+
+// GetPrice retrieves the current price from the public client
+func (u *userExchange) GetPrice(ctx context.Context, symbol string) (float64, error) {
+	return u.factory.public[u.name].GetPrice(ctx, symbol)
+}
+
+// GetVolume retrieves the trading volume from the public client
+func (u *userExchange) GetVolume(ctx context.Context, symbol string, timeframe string) (float64, error) {
+	return u.factory.public[u.name].GetVolume(ctx, symbol, timeframe)
+}
+
+// SymbolInfo returns the trading rules of a symbol from the public client
+func (u *userExchange) SymbolInfo(ctx context.Context, symbol string) (SymbolInfo, error) {
+	provider, ok := u.factory.public[u.name].(SymbolInfoProvider)
+	if !ok {
+		return SymbolInfo{}, fmt.Errorf("%s does not publish symbol trading rules", u.name)
+	}
+	return provider.SymbolInfo(ctx, symbol)
+}
+
+// PlaceOrder places an order on the user's account
+func (u *userExchange) PlaceOrder(ctx context.Context, req OrderRequest) (*model.Order, error) {
+	ex, err := u.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ex.PlaceOrder(ctx, req)
+}
+
+// PlaceOCO places an OCO bracket on the user's account
+func (u *userExchange) PlaceOCO(ctx context.Context, req OCORequest) ([]*model.Order, error) {
+	ex, err := u.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ex.PlaceOCO(ctx, req)
+}
+
+// CancelOrder cancels an order on the user's account
+func (u *userExchange) CancelOrder(ctx context.Context, symbol string, orderID string) (*model.Order, error) {
+	ex, err := u.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ex.CancelOrder(ctx, symbol, orderID)
+}
+
+// GetOrder retrieves an order on the user's account
+func (u *userExchange) GetOrder(ctx context.Context, symbol string, orderID string) (*model.Order, error) {
+	ex, err := u.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ex.GetOrder(ctx, symbol, orderID)
+}
+
+// ListOpenOrders lists the open orders on the user's account
+func (u *userExchange) ListOpenOrders(ctx context.Context, symbol string) ([]*model.Order, error) {
+	ex, err := u.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ex.ListOpenOrders(ctx, symbol)
+}
+
+// GetBalance retrieves the free balance of an asset on the user's account
+func (u *userExchange) GetBalance(ctx context.Context, asset string) (float64, error) {
+	ex, err := u.client(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return ex.GetBalance(ctx, asset)
+}
+
+// GetLockedBalance retrieves the amount reserved by open orders, or 0 when the exchange does not report it
+func (u *userExchange) GetLockedBalance(ctx context.Context, asset string) (float64, error) {
+	ex, err := u.client(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if lb, ok := ex.(LockedBalancer); ok {
+		return lb.GetLockedBalance(ctx, asset)
+	}
+	return 0, nil
+}
+
+// CancelAllOrders cancels the user's open orders; a user without credentials has none to cancel
+func (u *userExchange) CancelAllOrders(ctx context.Context) error {
+	ex, err := u.client(ctx)
+	if errors.Is(err, ErrNoCredentials) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if canceller, ok := ex.(OrderCanceller); ok {
+		return canceller.CancelAllOrders(ctx)
+	}
+	return nil
+}
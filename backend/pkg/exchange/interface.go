@@ -2,18 +2,61 @@ package exchange
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"time"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
 )
 
 // Exchange defines the interface for interacting with cryptocurrency exchanges
 type Exchange interface {
 	GetPrice(ctx context.Context, symbol string) (float64, error)
 	GetVolume(ctx context.Context, symbol string, timeframe string) (float64, error)
-	PlaceOrder(ctx context.Context, symbol string, side string, quantity float64, price float64) error
+	// PlaceOrder places an order and returns it with the IDs assigned to it and any immediate fills
+	PlaceOrder(ctx context.Context, req OrderRequest) (*model.Order, error)
+	// PlaceOCO places a one-cancels-the-other bracket and returns its two legs, the limit leg first
+	PlaceOCO(ctx context.Context, req OCORequest) ([]*model.Order, error)
+	// CancelOrder cancels an open order by its exchange order ID and returns its final state
+	CancelOrder(ctx context.Context, symbol string, orderID string) (*model.Order, error)
+	// GetOrder returns the current state of an order by its exchange order ID
+	GetOrder(ctx context.Context, symbol string, orderID string) (*model.Order, error)
+	// ListOpenOrders returns the open orders on symbol, or on every symbol when symbol is empty
+	ListOpenOrders(ctx context.Context, symbol string) ([]*model.Order, error)
 	GetBalance(ctx context.Context, asset string) (float64, error)
 	// Add more methods as needed, e.g., GetOrderBook, etc.
 }
 
+// NewClientOrderID returns a unique client order ID, valid on Binance (at most 36 characters of [A-Za-z0-9_-]).
+func NewClientOrderID() string {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "fxb-" + time.Now().Format("20060102150405.000000")
+	}
+	return "fxb-" + hex.EncodeToString(b)
+}
+
+// OrderCanceller is implemented by exchanges that can cancel all resting orders of an account.
+type OrderCanceller interface {
+	CancelAllOrders(ctx context.Context) error
+}
+
+// LockedBalancer is implemented by exchanges that report funds reserved by open orders.
+type LockedBalancer interface {
+	GetLockedBalance(ctx context.Context, asset string) (float64, error)
+}
+
+// Unwrap returns the exchange underneath any decorators wrapping ex, such as a risk guard.
+func Unwrap(ex Exchange) Exchange {
+	for {
+		wrapper, ok := ex.(interface{ Unwrap() Exchange })
+		if !ok {
+			return ex
+		}
+		ex = wrapper.Unwrap()
+	}
+}
+
 // PriceData represents price information
 type PriceData struct {
 	Symbol string
@@ -0,0 +1,157 @@
+package exchange
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Order types
+const (
+	OrderTypeMarket          = "MARKET"
+	OrderTypeLimit           = "LIMIT"
+	OrderTypeStopLossLimit   = "STOP_LOSS_LIMIT"   // Limit order placed once the price reaches StopPrice against the position
+	OrderTypeTakeProfitLimit = "TAKE_PROFIT_LIMIT" // Limit order placed once the price reaches StopPrice in favour of the position
+)
+
+// Time in force of limit orders
+const (
+	TimeInForceGTC = "GTC" // Good till cancelled
+	TimeInForceIOC = "IOC" // Immediate or cancel: fill what is possible now, cancel the rest
+	TimeInForceFOK = "FOK" // Fill or kill: fill completely now or cancel
+)
+
+// OrderRequest describes an order to place.
+type OrderRequest struct {
+	Symbol        string
+	Side          string  // BUY or SELL
+	Type          string  // One of the OrderType constants, LIMIT when empty
+	Quantity      float64 // Base asset quantity
+	Price         float64 // Limit price; unused for market orders
+	StopPrice     float64 // Trigger price of stop-loss-limit and take-profit-limit orders
+	TimeInForce   string  // For limit and stop orders, GTC when empty
+	ClientOrderID string  // Generated when empty
+	ReduceOnly    bool    // Only closes or shrinks an open position, such as a trade's exit
+}
+
+// LimitOrder returns a GTC limit order request.
+func LimitOrder(symbol, side string, quantity, price float64) OrderRequest {
+	return OrderRequest{Symbol: symbol, Side: side, Type: OrderTypeLimit, Quantity: quantity, Price: price}
+}
+
+// MarketOrder returns a market order request.
+func MarketOrder(symbol, side string, quantity float64) OrderRequest {
+	return OrderRequest{Symbol: symbol, Side: side, Type: OrderTypeMarket, Quantity: quantity}
+}
+
+// Normalize fills in the defaults of a request and checks that it is complete.
+func (r *OrderRequest) Normalize() error {
+	r.Side = strings.ToUpper(r.Side)
+	r.Type = strings.ToUpper(r.Type)
+	r.TimeInForce = strings.ToUpper(r.TimeInForce)
+	if r.Type == "" {
+		r.Type = OrderTypeLimit
+	}
+	if r.ClientOrderID == "" {
+		r.ClientOrderID = NewClientOrderID()
+	}
+
+	if r.Side != "BUY" && r.Side != "SELL" {
+		return fmt.Errorf("invalid order side %q", r.Side)
+	}
+	if r.Quantity <= 0 {
+		return fmt.Errorf("order quantity must be positive")
+	}
+	switch r.Type {
+	case OrderTypeMarket:
+		r.Price, r.StopPrice, r.TimeInForce = 0, 0, ""
+		return nil
+	case OrderTypeLimit:
+		r.StopPrice = 0
+	case OrderTypeStopLossLimit, OrderTypeTakeProfitLimit:
+		if r.StopPrice <= 0 {
+			return fmt.Errorf("%s orders need a positive stop price", r.Type)
+		}
+	default:
+		return fmt.Errorf("unsupported order type %q", r.Type)
+	}
+	if r.Price <= 0 {
+		return fmt.Errorf("%s orders need a positive limit price", r.Type)
+	}
+	switch r.TimeInForce {
+	case "":
+		r.TimeInForce = TimeInForceGTC
+	case TimeInForceGTC, TimeInForceIOC, TimeInForceFOK:
+	default:
+		return fmt.Errorf("invalid time in force %q", r.TimeInForce)
+	}
+	return nil
+}
+
+// OCORequest describes a one-cancels-the-other bracket of two orders for the same quantity:
+// a limit order at Price and a stop-loss-limit order triggered at StopPrice with limit StopLimitPrice.
+// When either leg executes the other is cancelled. A SELL bracket protects a long position,
+// with Price as the take profit above the market and StopPrice as the stop loss below it.
+type OCORequest struct {
+	Symbol             string
+	Side               string
+	Quantity           float64
+	Price              float64 // Limit (take profit) leg
+	StopPrice          float64 // Trigger of the stop leg
+	StopLimitPrice     float64 // Limit price of the stop leg once triggered
+	ListClientOrderID  string  // Generated when empty
+	LimitClientOrderID string  // Client order ID of the limit leg, generated when empty
+	StopClientOrderID  string  // Client order ID of the stop leg, generated when empty
+}
+
+// ProtectiveOCO returns the SELL bracket closing a long position of quantity at takeProfit or stopLoss.
+// The stop leg's limit sits slippage (a fraction) below the stop price so that it still fills in a fast market.
+func ProtectiveOCO(symbol string, quantity, takeProfit, stopLoss, slippage float64) OCORequest {
+	return OCORequest{
+		Symbol:         symbol,
+		Side:           "SELL",
+		Quantity:       quantity,
+		Price:          takeProfit,
+		StopPrice:      stopLoss,
+		StopLimitPrice: stopLoss * (1 - slippage),
+	}
+}
+
+// Normalize fills in the defaults of a bracket and checks that its prices are on the right sides.
+func (r *OCORequest) Normalize() error {
+	r.Side = strings.ToUpper(r.Side)
+	if r.ListClientOrderID == "" {
+		r.ListClientOrderID = NewClientOrderID()
+	}
+	if r.LimitClientOrderID == "" {
+		r.LimitClientOrderID = NewClientOrderID()
+	}
+	if r.StopClientOrderID == "" {
+		r.StopClientOrderID = NewClientOrderID()
+	}
+	if r.Quantity <= 0 || r.Price <= 0 || r.StopPrice <= 0 || r.StopLimitPrice <= 0 {
+		return fmt.Errorf("OCO quantity and prices must be positive")
+	}
+	switch r.Side {
+	case "SELL":
+		if r.Price <= r.StopPrice {
+			return fmt.Errorf("a SELL OCO needs the limit price above the stop price")
+		}
+	case "BUY":
+		if r.Price >= r.StopPrice {
+			return fmt.Errorf("a BUY OCO needs the limit price below the stop price")
+		}
+	default:
+		return fmt.Errorf("invalid order side %q", r.Side)
+	}
+	return nil
+}
+
+// Leg returns the order request of one leg of the bracket: the limit leg, or the stop leg if stop is set.
+func (r OCORequest) Leg(stop bool) OrderRequest {
+	if stop {
+		return OrderRequest{Symbol: r.Symbol, Side: r.Side, Type: OrderTypeStopLossLimit, Quantity: r.Quantity,
+			Price: r.StopLimitPrice, StopPrice: r.StopPrice, TimeInForce: TimeInForceGTC, ClientOrderID: r.StopClientOrderID}
+	}
+	return OrderRequest{Symbol: r.Symbol, Side: r.Side, Type: OrderTypeLimit, Quantity: r.Quantity,
+		Price: r.Price, TimeInForce: TimeInForceGTC, ClientOrderID: r.LimitClientOrderID}
+}
@@ -0,0 +1,629 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+)
+
+// PriceFeed is the source of prices for the paper exchange.
+// Any Exchange satisfies it, as does a ReplayFeed.
+type PriceFeed interface {
+	GetPrice(ctx context.Context, symbol string) (float64, error)
+}
+
+// PaperConfig holds the simulation settings for a PaperExchange.
+type PaperConfig struct {
+	InitialBalances map[string]float64 // Balances every new account starts with, e.g. {"USDT": 10000}
+	FeeRate         float64            // Fee charged on each fill as a fraction of the notional, e.g. 0.001
+	SlippageRate    float64            // Adverse price move applied to fills as a fraction, never worse than the limit price
+}
+
+// PaperOrder is a resting order in the simulated order book.
+type PaperOrder struct {
+	ID            int64     `json:"id"`
+	ClientOrderID string    `json:"client_order_id"`
+	UserID        int       `json:"user_id"`
+	Symbol        string    `json:"symbol"`
+	Side          string    `json:"side"`
+	Type          string    `json:"type"`
+	Quantity      float64   `json:"quantity"`
+	Price         float64   `json:"price"`                   // Limit price; for market buys, the most the order may pay
+	StopPrice     float64   `json:"stop_price,omitempty"`    // Trigger price of stop orders
+	TimeInForce   string    `json:"time_in_force,omitempty"` // GTC, IOC or FOK
+	ListID        int64     `json:"list_id,omitempty"`       // Shared by the two legs of an OCO
+	Triggered     bool      `json:"triggered,omitempty"`     // Whether a stop order has reached its stop price
+	CreatedAt     time.Time `json:"created_at"`
+
+	hold *paperHold
+}
+
+// paperHold is the balance reserved for one or more resting orders.
+// The two legs of an OCO share a single hold, since at most one of them can execute.
+type paperHold struct {
+	asset  string
+	amount float64
+}
+
+// PaperFill records the execution of a PaperOrder.
+type PaperFill struct {
+	OrderID       int64     `json:"order_id"`
+	ClientOrderID string    `json:"client_order_id"`
+	UserID        int       `json:"user_id"`
+	Symbol        string    `json:"symbol"`
+	Side          string    `json:"side"`
+	Quantity      float64   `json:"quantity"`
+	LimitPrice    float64   `json:"limit_price"`
+	Price         float64   `json:"price"`
+	Fee           float64   `json:"fee"`
+	FeeAsset      string    `json:"fee_asset"`
+	Time          time.Time `json:"time"`
+}
+
+// paperAccount holds the virtual balances and orders of a single user.
+type paperAccount struct {
+	free    map[string]float64
+	locked  map[string]float64
+	orders  map[int64]*PaperOrder
+	history map[int64]*model.Order // Every order placed, with its current status
+	fills   []PaperFill
+}
+
+// PaperExchange implements the Exchange interface against virtual balances.
+// Prices come from a PriceFeed, and limit orders are filled once the price crosses their limit.
+// Accounts are kept per user, selected with WithUserID on the call context.
+type PaperExchange struct {
+	feed     PriceFeed
+	cfg      PaperConfig
+	accounts map[int]*paperAccount
+	nextID   int64
+	onFill   []func(PaperFill)
+	mu       sync.Mutex
+}
+
+// NewPaperExchange creates a new paper trading exchange reading prices from feed.
+func NewPaperExchange(feed PriceFeed, cfg PaperConfig) *PaperExchange {
+	return &PaperExchange{
+		feed:     feed,
+		cfg:      cfg,
+		accounts: make(map[int]*paperAccount),
+	}
+}
+
+// OnFill registers a callback that is invoked after every simulated fill.
+func (p *PaperExchange) OnFill(fn func(PaperFill)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onFill = append(p.onFill, fn)
+}
+
+// GetPrice retrieves the current price from the feed and matches resting orders against it.
+func (p *PaperExchange) GetPrice(ctx context.Context, symbol string) (float64, error) {
+	price, err := p.feed.GetPrice(ctx, symbol)
+	if err != nil {
+		return 0, err
+	}
+	p.MatchRange(symbol, price, price)
+	return price, nil
+}
+
+// GetVolume forwards to the feed when it is a real exchange.
+func (p *PaperExchange) GetVolume(ctx context.Context, symbol string, timeframe string) (float64, error) {
+	if ex, ok := p.feed.(Exchange); ok {
+		return ex.GetVolume(ctx, symbol, timeframe)
+	}
+	return 0, fmt.Errorf("volume is not available from the paper exchange price feed")
+}
+
+// PlaceOrder reserves funds for an order and adds it to the simulated book.
+// The order is matched straight away against the current price, so market orders and marketable
+// limit orders fill immediately. IOC and FOK limit orders that cannot fill at once expire.
+// When the feed publishes trading rules, the order is rounded and validated against them as the real exchange would.
+func (p *PaperExchange) PlaceOrder(ctx context.Context, req OrderRequest) (*model.Order, error) {
+	if err := req.Normalize(); err != nil {
+		return nil, err
+	}
+	base, quote, err := SplitSymbol(req.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	current, priceErr := p.feed.GetPrice(ctx, req.Symbol)
+	if req.Type == OrderTypeMarket && priceErr != nil {
+		return nil, fmt.Errorf("cannot price market order: %w", priceErr)
+	}
+	if provider, ok := p.feed.(SymbolInfoProvider); ok {
+		info, err := provider.SymbolInfo(ctx, req.Symbol)
+		if err != nil {
+			return nil, err
+		}
+		if err := info.NormalizeOrder(&req, current); err != nil {
+			return nil, err
+		}
+	}
+
+	limit := req.Price
+	if req.Type == OrderTypeMarket && req.Side == "BUY" {
+		limit = current * (1 + p.cfg.SlippageRate)
+	}
+	hold := &paperHold{asset: base, amount: req.Quantity}
+	if req.Side == "BUY" {
+		hold = &paperHold{asset: quote, amount: req.Quantity * limit * (1 + p.cfg.FeeRate)}
+	}
+
+	userID := UserIDFromContext(ctx)
+	p.mu.Lock()
+	acc := p.account(userID)
+	if err := reserve(acc, hold); err != nil {
+		p.mu.Unlock()
+		return nil, err
+	}
+	o := p.add(acc, userID, req, hold, 0)
+	o.Price = limit
+	p.mu.Unlock()
+
+	// A feed error just leaves a limit order resting.
+	if priceErr == nil {
+		p.MatchRange(req.Symbol, current, current)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, resting := acc.orders[o.ID]; resting && o.Type == OrderTypeLimit && o.TimeInForce != TimeInForceGTC {
+		p.closeOrder(acc, o, model.OrderExpired)
+	}
+	order := *acc.history[o.ID]
+	return &order, nil
+}
+
+// PlaceOCO reserves funds once for both legs of a bracket and adds them to the simulated book.
+// When one leg fills the other expires; cancelling either leg cancels both.
+func (p *PaperExchange) PlaceOCO(ctx context.Context, req OCORequest) ([]*model.Order, error) {
+	if err := req.Normalize(); err != nil {
+		return nil, err
+	}
+	base, quote, err := SplitSymbol(req.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	if provider, ok := p.feed.(SymbolInfoProvider); ok {
+		info, err := provider.SymbolInfo(ctx, req.Symbol)
+		if err != nil {
+			return nil, err
+		}
+		if err := info.NormalizeOCO(&req); err != nil {
+			return nil, err
+		}
+	}
+	hold := &paperHold{asset: base, amount: req.Quantity}
+	if req.Side == "BUY" {
+		hold = &paperHold{asset: quote, amount: req.Quantity * max(req.Price, req.StopLimitPrice) * (1 + p.cfg.FeeRate)}
+	}
+
+	userID := UserIDFromContext(ctx)
+	p.mu.Lock()
+	acc := p.account(userID)
+	if err := reserve(acc, hold); err != nil {
+		p.mu.Unlock()
+		return nil, err
+	}
+	p.nextID++
+	listID := p.nextID
+	legs := []*PaperOrder{
+		p.add(acc, userID, req.Leg(false), hold, listID),
+		p.add(acc, userID, req.Leg(true), hold, listID),
+	}
+	p.mu.Unlock()
+
+	if current, err := p.feed.GetPrice(ctx, req.Symbol); err == nil {
+		p.MatchRange(req.Symbol, current, current)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	orders := make([]*model.Order, 0, len(legs))
+	for _, o := range legs {
+		order := *acc.history[o.ID]
+		orders = append(orders, &order)
+	}
+	return orders, nil
+}
+
+// CancelOrder cancels a resting order and releases its reserved funds.
+func (p *PaperExchange) CancelOrder(ctx context.Context, symbol string, orderID string) (*model.Order, error) {
+	id, err := strconv.ParseInt(orderID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid paper order ID %q", orderID)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	acc := p.account(UserIDFromContext(ctx))
+	order, ok := acc.history[id]
+	if !ok || order.Symbol != symbol {
+		return nil, fmt.Errorf("order %s not found", orderID)
+	}
+	o, ok := acc.orders[id]
+	if !ok {
+		return nil, fmt.Errorf("order %s is already %s", orderID, order.Status)
+	}
+	p.cancel(acc, o)
+	result := *order
+	return &result, nil
+}
+
+// GetOrder returns the current state of an order placed on the user's account.
+func (p *PaperExchange) GetOrder(ctx context.Context, symbol string, orderID string) (*model.Order, error) {
+	id, err := strconv.ParseInt(orderID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid paper order ID %q", orderID)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	order, ok := p.account(UserIDFromContext(ctx)).history[id]
+	if !ok || order.Symbol != symbol {
+		return nil, fmt.Errorf("order %s not found", orderID)
+	}
+	result := *order
+	return &result, nil
+}
+
+// ListOpenOrders returns the resting orders of the user's account on symbol, or on every symbol when symbol is empty.
+func (p *PaperExchange) ListOpenOrders(ctx context.Context, symbol string) ([]*model.Order, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	acc := p.account(UserIDFromContext(ctx))
+	var ids []int64
+	for id, o := range acc.orders {
+		if symbol == "" || o.Symbol == symbol {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	orders := make([]*model.Order, 0, len(ids))
+	for _, id := range ids {
+		order := *acc.history[id]
+		orders = append(orders, &order)
+	}
+	return orders, nil
+}
+
+// GetBalance retrieves the free virtual balance for an asset.
+func (p *PaperExchange) GetBalance(ctx context.Context, asset string) (float64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.account(UserIDFromContext(ctx)).free[asset], nil
+}
+
+// GetLockedBalance retrieves the virtual balance of an asset reserved by resting orders.
+func (p *PaperExchange) GetLockedBalance(ctx context.Context, asset string) (float64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.account(UserIDFromContext(ctx)).locked[asset], nil
+}
+
+// CancelAllOrders cancels every resting order of the user's account and releases the reserved funds.
+func (p *PaperExchange) CancelAllOrders(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	acc := p.account(UserIDFromContext(ctx))
+	for _, o := range acc.orders {
+		p.cancel(acc, o)
+	}
+	return nil
+}
+
+// cancel cancels a resting order, along with the other leg if it belongs to an OCO. The caller must hold p.mu.
+func (p *PaperExchange) cancel(acc *paperAccount, o *PaperOrder) {
+	p.closeOrder(acc, o, model.OrderCanceled)
+	if o.ListID == 0 {
+		return
+	}
+	for _, other := range acc.orders {
+		if other.ListID == o.ListID {
+			p.closeOrder(acc, other, model.OrderCanceled)
+		}
+	}
+}
+
+// closeOrder removes a resting order from the book with a final status and releases its reserved funds.
+// The caller must hold p.mu.
+func (p *PaperExchange) closeOrder(acc *paperAccount, o *PaperOrder, status string) {
+	acc.locked[o.hold.asset] -= o.hold.amount
+	acc.free[o.hold.asset] += o.hold.amount
+	o.hold.amount = 0
+	delete(acc.orders, o.ID)
+
+	order := acc.history[o.ID]
+	order.Status = status
+	order.UpdatedAt = p.now()
+}
+
+// add puts an order into the book and the account history; its funds must already be reserved in hold.
+// The caller must hold p.mu.
+func (p *PaperExchange) add(acc *paperAccount, userID int, req OrderRequest, hold *paperHold, listID int64) *PaperOrder {
+	if req.ClientOrderID == "" {
+		req.ClientOrderID = NewClientOrderID()
+	}
+	p.nextID++
+	id, now := p.nextID, p.now()
+	o := &PaperOrder{
+		ID:            id,
+		ClientOrderID: req.ClientOrderID,
+		UserID:        userID,
+		Symbol:        req.Symbol,
+		Side:          req.Side,
+		Type:          req.Type,
+		Quantity:      req.Quantity,
+		Price:         req.Price,
+		StopPrice:     req.StopPrice,
+		TimeInForce:   req.TimeInForce,
+		ListID:        listID,
+		CreatedAt:     now,
+		hold:          hold,
+	}
+	acc.orders[id] = o
+
+	order := &model.Order{
+		UserID:          userID,
+		Exchange:        "paper",
+		Symbol:          req.Symbol,
+		Side:            req.Side,
+		Type:            req.Type,
+		ClientOrderID:   req.ClientOrderID,
+		ExchangeOrderID: strconv.FormatInt(id, 10),
+		Quantity:        req.Quantity,
+		Price:           req.Price,
+		StopPrice:       req.StopPrice,
+		TimeInForce:     req.TimeInForce,
+		Status:          model.OrderNew,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if listID != 0 {
+		order.OrderListID = strconv.FormatInt(listID, 10)
+	}
+	acc.history[id] = order
+	return o
+}
+
+// reserve moves the amount of a hold from the free to the locked balance.
+func reserve(acc *paperAccount, h *paperHold) error {
+	if acc.free[h.asset] < h.amount {
+		return fmt.Errorf("insufficient %s balance: have %.8f, need %.8f", h.asset, acc.free[h.asset], h.amount)
+	}
+	acc.free[h.asset] -= h.amount
+	acc.locked[h.asset] += h.amount
+	return nil
+}
+
+// Balances returns the free and locked balances of a user's account.
+func (p *PaperExchange) Balances(userID int) (free, locked map[string]float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	acc := p.account(userID)
+	free = make(map[string]float64, len(acc.free))
+	for asset, amount := range acc.free {
+		free[asset] = amount
+	}
+	locked = make(map[string]float64, len(acc.locked))
+	for asset, amount := range acc.locked {
+		locked[asset] = amount
+	}
+	return free, locked
+}
+
+// OpenOrders returns the resting orders of a user's account, oldest first.
+func (p *PaperExchange) OpenOrders(userID int) []PaperOrder {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var orders []PaperOrder
+	for _, o := range p.account(userID).orders {
+		orders = append(orders, *o)
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].ID < orders[j].ID })
+	return orders
+}
+
+// Fills returns all simulated fills of a user's account in execution order.
+func (p *PaperExchange) Fills(userID int) []PaperFill {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]PaperFill(nil), p.account(userID).fills...)
+}
+
+// MatchRange fills every resting order on symbol whose limit lies within the traded range [low, high].
+// Stop orders first become limit orders once the range reaches their stop price.
+// A single price tick is matched with low == high; a backtest passes a candle's low and high.
+func (p *PaperExchange) MatchRange(symbol string, low, high float64) {
+	p.mu.Lock()
+	var fills []PaperFill
+	for _, acc := range p.accounts {
+		for id, o := range acc.orders {
+			if o.Symbol != symbol {
+				continue
+			}
+			if o.StopPrice > 0 && !o.Triggered {
+				if !stopReached(o, low, high) {
+					continue
+				}
+				o.Triggered = true
+			}
+			fillPrice, ok := p.matchPrice(o, low, high)
+			if !ok {
+				if o.Triggered && o.TimeInForce != TimeInForceGTC {
+					p.closeOrder(acc, o, model.OrderExpired)
+				}
+				continue
+			}
+			fills = append(fills, p.fill(acc, o, fillPrice))
+			delete(acc.orders, id)
+			if o.ListID != 0 {
+				for _, other := range acc.orders {
+					if other.ListID == o.ListID {
+						p.closeOrder(acc, other, model.OrderExpired)
+					}
+				}
+			}
+		}
+	}
+	callbacks := p.onFill
+	p.mu.Unlock()
+
+	sort.Slice(fills, func(i, j int) bool { return fills[i].OrderID < fills[j].OrderID })
+	for _, f := range fills {
+		for _, fn := range callbacks {
+			fn(f)
+		}
+	}
+}
+
+// Run periodically polls the feed for every symbol with resting orders until ctx is cancelled,
+// so that orders fill even when nobody is asking for prices.
+func (p *PaperExchange) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			for _, symbol := range p.openSymbols() {
+				if _, err := p.GetPrice(ctx, symbol); err != nil {
+					log.Printf("Paper exchange: error getting price for %s: %v", symbol, err)
+				}
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// matchPrice returns the price a resting order fills at within the traded range [low, high], if it fills.
+func (p *PaperExchange) matchPrice(o *PaperOrder, low, high float64) (float64, bool) {
+	switch {
+	case o.Type == OrderTypeMarket && o.Side == "BUY":
+		return min(o.Price, high*(1+p.cfg.SlippageRate)), true
+	case o.Type == OrderTypeMarket:
+		return low * (1 - p.cfg.SlippageRate), true
+	case o.Side == "BUY" && low <= o.Price:
+		return min(o.Price, high*(1+p.cfg.SlippageRate)), true
+	case o.Side == "SELL" && high >= o.Price:
+		return max(o.Price, low*(1-p.cfg.SlippageRate)), true
+	}
+	return 0, false
+}
+
+// stopReached reports whether the range [low, high] reaches the stop price of a stop order.
+// Stop losses trigger when the price moves against the order's position (down for sells),
+// take profits when it moves in its favour.
+func stopReached(o *PaperOrder, low, high float64) bool {
+	if (o.Side == "SELL") == (o.Type == OrderTypeStopLossLimit) {
+		return low <= o.StopPrice
+	}
+	return high >= o.StopPrice
+}
+
+// fill settles an order at fillPrice and records the fill. The caller must hold p.mu.
+func (p *PaperExchange) fill(acc *paperAccount, o *PaperOrder, fillPrice float64) PaperFill {
+	base, quote, _ := SplitSymbol(o.Symbol)
+	notional := o.Quantity * fillPrice
+	fee := notional * p.cfg.FeeRate
+
+	if o.Side == "BUY" {
+		acc.locked[quote] -= o.hold.amount
+		acc.free[quote] += o.hold.amount - notional - fee
+		acc.free[base] += o.Quantity
+	} else {
+		acc.locked[base] -= o.hold.amount
+		acc.free[quote] += notional - fee
+	}
+	o.hold.amount = 0
+
+	f := PaperFill{
+		OrderID:       o.ID,
+		ClientOrderID: o.ClientOrderID,
+		UserID:        o.UserID,
+		Symbol:        o.Symbol,
+		Side:          o.Side,
+		Quantity:      o.Quantity,
+		LimitPrice:    o.Price,
+		Price:         fillPrice,
+		Fee:           fee,
+		FeeAsset:      quote,
+		Time:          p.now(),
+	}
+	acc.fills = append(acc.fills, f)
+
+	order := acc.history[o.ID]
+	order.Status = model.OrderFilled
+	order.FilledQuantity = o.Quantity
+	order.AvgPrice = fillPrice
+	order.Fee = fee
+	order.FeeAsset = quote
+	order.UpdatedAt = f.Time
+	return f
+}
+
+// account returns the account for userID, opening it with the initial balances if needed.
+// The caller must hold p.mu.
+func (p *PaperExchange) account(userID int) *paperAccount {
+	acc, ok := p.accounts[userID]
+	if !ok {
+		acc = &paperAccount{
+			free:    make(map[string]float64),
+			locked:  make(map[string]float64),
+			orders:  make(map[int64]*PaperOrder),
+			history: make(map[int64]*model.Order),
+		}
+		for asset, amount := range p.cfg.InitialBalances {
+			acc.free[asset] = amount
+		}
+		p.accounts[userID] = acc
+	}
+	return acc
+}
+
+func (p *PaperExchange) openSymbols() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	seen := make(map[string]bool)
+	var symbols []string
+	for _, acc := range p.accounts {
+		for _, o := range acc.orders {
+			if !seen[o.Symbol] {
+				seen[o.Symbol] = true
+				symbols = append(symbols, o.Symbol)
+			}
+		}
+	}
+	return symbols
+}
+
+// now returns the feed's clock when it has one (as a ReplayFeed does), or the wall clock.
+func (p *PaperExchange) now() time.Time {
+	if clock, ok := p.feed.(interface{ Now() time.Time }); ok {
+		return clock.Now()
+	}
+	return time.Now()
+}
+
+// quoteAssets lists the quote assets recognised by SplitSymbol, longest first.
+var quoteAssets = []string{"FDUSD", "USDT", "USDC", "BUSD", "TUSD", "EUR", "TRY", "BTC", "ETH", "BNB"}
+
+// SplitSymbol splits a trading pair such as BTCUSDT into its base and quote assets.
+func SplitSymbol(symbol string) (base, quote string, err error) {
+	for _, q := range quoteAssets {
+		if strings.HasSuffix(symbol, q) && len(symbol) > len(q) {
+			return strings.TrimSuffix(symbol, q), q, nil
+		}
+	}
+	return "", "", fmt.Errorf("cannot determine base and quote assets of symbol %q", symbol)
+}
@@ -0,0 +1,81 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ReplayFeed is a PriceFeed that plays back recorded prices instead of asking a live exchange.
+// It keeps its own clock, which follows the time of the most recently replayed price.
+type ReplayFeed struct {
+	series  map[string][]PriceData
+	cursor  map[string]int
+	current map[string]PriceData
+	now     time.Time
+	mu      sync.RWMutex
+}
+
+// NewReplayFeed creates an empty replay feed.
+func NewReplayFeed() *ReplayFeed {
+	return &ReplayFeed{
+		series:  make(map[string][]PriceData),
+		cursor:  make(map[string]int),
+		current: make(map[string]PriceData),
+	}
+}
+
+// Load queues a chronological price series for a symbol, replacing any previous series.
+func (r *ReplayFeed) Load(symbol string, prices []PriceData) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.series[symbol] = prices
+	r.cursor[symbol] = 0
+}
+
+// Advance moves the symbol to its next queued price and returns it.
+// It returns false once the series is exhausted.
+func (r *ReplayFeed) Advance(symbol string) (PriceData, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.cursor[symbol]
+	if i >= len(r.series[symbol]) {
+		return PriceData{}, false
+	}
+	r.cursor[symbol] = i + 1
+	r.setLocked(r.series[symbol][i])
+	return r.series[symbol][i], true
+}
+
+// Set makes p the current price of its symbol without touching any queued series.
+func (r *ReplayFeed) Set(p PriceData) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.setLocked(p)
+}
+
+// GetPrice returns the current replayed price for a symbol.
+func (r *ReplayFeed) GetPrice(ctx context.Context, symbol string) (float64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.current[symbol]
+	if !ok {
+		return 0, fmt.Errorf("no replayed price for %s yet", symbol)
+	}
+	return p.Price, nil
+}
+
+// Now returns the time of the most recently replayed price.
+func (r *ReplayFeed) Now() time.Time {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.now
+}
+
+func (r *ReplayFeed) setLocked(p PriceData) {
+	r.current[p.Symbol] = p
+	if p.Time.After(r.now) {
+		r.now = p.Time
+	}
+}
@@ -3,13 +3,17 @@ package exchange
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"github.com/gagliardetto/solana-go"
 	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
 )
 
 // SolanaExchange implements the Exchange interface for Solana
 type SolanaExchange struct {
 	client *rpc.Client
+	wallet solana.PublicKey // Zero for the public, walletless instance
 }
 
 // NewSolanaExchange creates a new Solana exchange instance
@@ -18,6 +22,15 @@ func NewSolanaExchange() Exchange {
 	return &SolanaExchange{client: client}
 }
 
+// NewSolanaWallet creates a Solana exchange instance for the wallet of a base58 private key
+func NewSolanaWallet(privateKey string) (Exchange, error) {
+	key, err := solana.PrivateKeyFromBase58(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Solana private key: %w", err)
+	}
+	return &SolanaExchange{client: rpc.New(rpc.MainNetBeta_RPC), wallet: key.PublicKey()}, nil
+}
+
 // GetPrice retrieves the current price for a symbol (placeholder implementation)
 func (s *SolanaExchange) GetPrice(ctx context.Context, symbol string) (float64, error) {
 	// For Solana, we would typically get price from Pyth or other oracles
@@ -27,10 +40,30 @@ func (s *SolanaExchange) GetPrice(ctx context.Context, symbol string) (float64,
 }
 
 // PlaceOrder places an order on Solana (placeholder implementation)
-func (s *SolanaExchange) PlaceOrder(ctx context.Context, symbol string, side string, quantity float64, price float64) error {
+func (s *SolanaExchange) PlaceOrder(ctx context.Context, req OrderRequest) (*model.Order, error) {
 	// This would require wallet integration and program calls
 	// Placeholder for now
-	return fmt.Errorf("order placement not implemented for Solana yet")
+	return nil, fmt.Errorf("order placement not implemented for Solana yet")
+}
+
+// PlaceOCO places an OCO bracket on Solana (placeholder implementation)
+func (s *SolanaExchange) PlaceOCO(ctx context.Context, req OCORequest) ([]*model.Order, error) {
+	return nil, fmt.Errorf("OCO orders not implemented for Solana yet")
+}
+
+// CancelOrder cancels an order on Solana (placeholder implementation)
+func (s *SolanaExchange) CancelOrder(ctx context.Context, symbol string, orderID string) (*model.Order, error) {
+	return nil, fmt.Errorf("order cancellation not implemented for Solana yet")
+}
+
+// GetOrder retrieves an order on Solana (placeholder implementation)
+func (s *SolanaExchange) GetOrder(ctx context.Context, symbol string, orderID string) (*model.Order, error) {
+	return nil, fmt.Errorf("order lookup not implemented for Solana yet")
+}
+
+// ListOpenOrders lists open orders on Solana; no orders can be placed yet, so there are none
+func (s *SolanaExchange) ListOpenOrders(ctx context.Context, symbol string) ([]*model.Order, error) {
+	return nil, nil
 }
 
 // GetVolume retrieves the trading volume for a symbol over a timeframe (placeholder)
@@ -39,9 +72,18 @@ func (s *SolanaExchange) GetVolume(ctx context.Context, symbol string, timeframe
 	return 0, fmt.Errorf("volume fetching not implemented for Solana yet")
 }
 
-// GetBalance retrieves the balance for an asset
+// GetBalance retrieves the wallet balance for an asset; only native SOL is supported so far
 func (s *SolanaExchange) GetBalance(ctx context.Context, asset string) (float64, error) {
-	// This would require wallet integration
-	// Placeholder for now
-	return 0, fmt.Errorf("balance fetching not implemented for Solana yet")
+	if s.wallet.IsZero() {
+		return 0, fmt.Errorf("no Solana wallet configured")
+	}
+	if !strings.EqualFold(asset, "SOL") {
+		// SPL token balances would require token account lookups
+		return 0, fmt.Errorf("balance fetching for %s not implemented for Solana yet", asset)
+	}
+	result, err := s.client.GetBalance(ctx, s.wallet, rpc.CommitmentFinalized)
+	if err != nil {
+		return 0, err
+	}
+	return float64(result.Value) / float64(solana.LAMPORTS_PER_SOL), nil
 }
@@ -0,0 +1,208 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidOrder is returned when an order does not meet the trading rules of its symbol.
+var ErrInvalidOrder = errors.New("invalid order")
+
+// SymbolInfo holds the trading rules of a symbol, as published in the exchange info.
+// Zero values mean the exchange sets no such limit.
+type SymbolInfo struct {
+	Symbol           string  `json:"symbol"`
+	BaseAsset        string  `json:"base_asset"`
+	QuoteAsset       string  `json:"quote_asset"`
+	TickSize         float64 `json:"tick_size"` // Prices must be a multiple of it
+	MinPrice         float64 `json:"min_price"`
+	MaxPrice         float64 `json:"max_price"`
+	StepSize         float64 `json:"step_size"` // Quantities must be a multiple of it
+	MinQty           float64 `json:"min_qty"`
+	MaxQty           float64 `json:"max_qty"`
+	MinNotional      float64 `json:"min_notional"` // Minimum quantity times price, in the quote asset
+	ApplyMinToMarket bool    `json:"apply_min_to_market"`
+	OCOAllowed       bool    `json:"oco_allowed"`
+}
+
+// SymbolInfoProvider is implemented by exchanges that publish the trading rules of their symbols.
+type SymbolInfoProvider interface {
+	SymbolInfo(ctx context.Context, symbol string) (SymbolInfo, error)
+}
+
+// RoundQuantity rounds a quantity down to the step size, so an order never exceeds the amount asked for.
+func (s SymbolInfo) RoundQuantity(quantity float64) float64 {
+	return roundToStep(quantity, s.StepSize, math.Floor)
+}
+
+// RoundPrice rounds a price to the nearest tick.
+func (s SymbolInfo) RoundPrice(price float64) float64 {
+	return roundToStep(price, s.TickSize, math.Round)
+}
+
+// NormalizeOrder rounds the quantity and prices of an order to the symbol's steps and checks its limits.
+// marketPrice values market orders for the minimum notional; with 0 the check is skipped for them.
+func (s SymbolInfo) NormalizeOrder(req *OrderRequest, marketPrice float64) error {
+	quantity := req.Quantity
+	req.Quantity = s.RoundQuantity(req.Quantity)
+	if err := s.checkQuantity(quantity, req.Quantity); err != nil {
+		return err
+	}
+	if req.StopPrice > 0 {
+		req.StopPrice = s.RoundPrice(req.StopPrice)
+		if err := s.checkPrice("stop price", req.StopPrice); err != nil {
+			return err
+		}
+	}
+	if req.Type == OrderTypeMarket {
+		if s.ApplyMinToMarket && marketPrice > 0 {
+			return s.checkNotional(req.Quantity, marketPrice)
+		}
+		return nil
+	}
+	req.Price = s.RoundPrice(req.Price)
+	if err := s.checkPrice("price", req.Price); err != nil {
+		return err
+	}
+	return s.checkNotional(req.Quantity, req.Price)
+}
+
+// NormalizeOCO rounds the quantity and prices of a bracket to the symbol's steps and checks its limits.
+func (s SymbolInfo) NormalizeOCO(req *OCORequest) error {
+	if !s.OCOAllowed {
+		return fmt.Errorf("%w: OCO orders are not allowed on %s", ErrInvalidOrder, s.Symbol)
+	}
+	quantity := req.Quantity
+	req.Quantity = s.RoundQuantity(req.Quantity)
+	if err := s.checkQuantity(quantity, req.Quantity); err != nil {
+		return err
+	}
+	req.Price = s.RoundPrice(req.Price)
+	req.StopPrice = s.RoundPrice(req.StopPrice)
+	req.StopLimitPrice = s.RoundPrice(req.StopLimitPrice)
+	for _, p := range []struct {
+		name  string
+		price float64
+	}{{"price", req.Price}, {"stop price", req.StopPrice}, {"stop limit price", req.StopLimitPrice}} {
+		if err := s.checkPrice(p.name, p.price); err != nil {
+			return err
+		}
+	}
+	// Each leg must meet the minimum notional on its own
+	if err := s.checkNotional(req.Quantity, req.Price); err != nil {
+		return err
+	}
+	return s.checkNotional(req.Quantity, req.StopLimitPrice)
+}
+
+func (s SymbolInfo) checkQuantity(requested, rounded float64) error {
+	if rounded <= 0 {
+		return fmt.Errorf("%w: quantity %s %s rounds to 0 with the %s step size %s",
+			ErrInvalidOrder, formatFloat(requested), s.BaseAsset, s.Symbol, formatFloat(s.StepSize))
+	}
+	if s.MinQty > 0 && rounded < s.MinQty {
+		return fmt.Errorf("%w: quantity %s %s is below the %s minimum of %s",
+			ErrInvalidOrder, formatFloat(rounded), s.BaseAsset, s.Symbol, formatFloat(s.MinQty))
+	}
+	if s.MaxQty > 0 && rounded > s.MaxQty {
+		return fmt.Errorf("%w: quantity %s %s is above the %s maximum of %s",
+			ErrInvalidOrder, formatFloat(rounded), s.BaseAsset, s.Symbol, formatFloat(s.MaxQty))
+	}
+	return nil
+}
+
+func (s SymbolInfo) checkPrice(name string, price float64) error {
+	if price <= 0 {
+		return fmt.Errorf("%w: %s rounds to 0 with the %s tick size %s", ErrInvalidOrder, name, s.Symbol, formatFloat(s.TickSize))
+	}
+	if s.MinPrice > 0 && price < s.MinPrice {
+		return fmt.Errorf("%w: %s %s %s is below the %s minimum of %s",
+			ErrInvalidOrder, name, formatFloat(price), s.QuoteAsset, s.Symbol, formatFloat(s.MinPrice))
+	}
+	if s.MaxPrice > 0 && price > s.MaxPrice {
+		return fmt.Errorf("%w: %s %s %s is above the %s maximum of %s",
+			ErrInvalidOrder, name, formatFloat(price), s.QuoteAsset, s.Symbol, formatFloat(s.MaxPrice))
+	}
+	return nil
+}
+
+func (s SymbolInfo) checkNotional(quantity, price float64) error {
+	if notional := quantity * price; s.MinNotional > 0 && notional < s.MinNotional {
+		return fmt.Errorf("%w: order value %.8g %s is below the %s minimum notional of %s %s",
+			ErrInvalidOrder, notional, s.QuoteAsset, s.Symbol, formatFloat(s.MinNotional), s.QuoteAsset)
+	}
+	return nil
+}
+
+// roundToStep rounds value to a multiple of step with the given rounding function,
+// then to the step's decimal places so that it formats without floating point noise.
+func roundToStep(value, step float64, round func(float64) float64) float64 {
+	if step <= 0 {
+		return value
+	}
+	// The small relative epsilon keeps values that are already on a step, such as 0.3 / 0.1 or 10000 / 0.00001,
+	// from flooring a step down
+	steps := round(value / step * (1 + 1e-12))
+	scale := math.Pow(10, float64(decimals(step)))
+	return math.Round(steps*step*scale) / scale
+}
+
+// decimals returns the number of decimal places of a step such as 0.001.
+func decimals(step float64) int {
+	s := strconv.FormatFloat(step, 'f', -1, 64)
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return len(s) - i - 1
+	}
+	return 0
+}
+
+// formatFloat formats a quantity or price for an exchange API and error messages.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// SymbolLoader loads the trading rules of every symbol on an exchange.
+type SymbolLoader func(ctx context.Context) (map[string]SymbolInfo, error)
+
+// SymbolCache caches the trading rules of an exchange's symbols and reloads them once they are older than its TTL.
+// If a reload fails, the rules already loaded keep being served and the reload is retried a minute later.
+type SymbolCache struct {
+	load     SymbolLoader
+	ttl      time.Duration
+	symbols  map[string]SymbolInfo
+	loadedAt time.Time
+	mu       sync.Mutex
+}
+
+// NewSymbolCache creates a cache that loads symbols on first use and refreshes them every ttl.
+func NewSymbolCache(load SymbolLoader, ttl time.Duration) *SymbolCache {
+	return &SymbolCache{load: load, ttl: ttl}
+}
+
+// Get returns the trading rules of symbol.
+func (c *SymbolCache) Get(ctx context.Context, symbol string) (SymbolInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.symbols == nil || time.Since(c.loadedAt) > c.ttl {
+		symbols, err := c.load(ctx)
+		switch {
+		case err == nil:
+			c.symbols, c.loadedAt = symbols, time.Now()
+		case c.symbols == nil:
+			return SymbolInfo{}, fmt.Errorf("failed to load exchange info: %w", err)
+		default:
+			c.loadedAt = time.Now().Add(time.Minute - c.ttl)
+		}
+	}
+	info, ok := c.symbols[symbol]
+	if !ok {
+		return SymbolInfo{}, fmt.Errorf("%w: unknown symbol %s", ErrInvalidOrder, symbol)
+	}
+	return info, nil
+}
@@ -0,0 +1,226 @@
+package exchange
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestRoundToStep(t *testing.T) {
+	tests := []struct {
+		name        string
+		value, step float64
+		round       func(float64) float64
+		want        float64
+	}{
+		{"on step", 0.3, 0.1, math.Floor, 0.3},
+		{"on step below a float multiple", 0.7, 0.1, math.Floor, 0.7},
+		{"on hundredth step", 1.15, 0.01, math.Floor, 1.15},
+		{"on step after many steps", 10000, 0.00001, math.Floor, 10000},
+		{"just below a step", 0.29999, 0.1, math.Floor, 0.2},
+		{"floors between steps", 0.19, 0.1, math.Floor, 0.1},
+		{"rounds between steps", 0.26, 0.1, math.Round, 0.3},
+		{"sub-step floors to zero", 0.05, 0.1, math.Floor, 0},
+		{"whole step", 12.7, 1, math.Floor, 12},
+		{"tiny step floors", 0.123456789, 1e-8, math.Floor, 0.12345678},
+		{"tiny step rounds", 0.000000129, 1e-8, math.Round, 0.00000013},
+		{"no step", 0.123456789, 0, math.Floor, 0.123456789},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := roundToStep(tt.value, tt.step, tt.round); got != tt.want {
+				t.Errorf("roundToStep(%v, %v) = %v, want %v", tt.value, tt.step, got, tt.want)
+			}
+		})
+	}
+}
+
+// btcusdt has trading rules like those Binance publishes for BTCUSDT.
+var btcusdt = SymbolInfo{
+	Symbol:           "BTCUSDT",
+	BaseAsset:        "BTC",
+	QuoteAsset:       "USDT",
+	TickSize:         0.01,
+	MinPrice:         0.01,
+	MaxPrice:         1000000,
+	StepSize:         0.00001,
+	MinQty:           0.00001,
+	MaxQty:           9000,
+	MinNotional:      5,
+	ApplyMinToMarket: true,
+	OCOAllowed:       true,
+}
+
+func TestNormalizeOrder(t *testing.T) {
+	shibusdt := SymbolInfo{Symbol: "SHIBUSDT", BaseAsset: "SHIB", QuoteAsset: "USDT", TickSize: 1e-8, StepSize: 1, MinNotional: 5}
+
+	tests := []struct {
+		name        string
+		info        SymbolInfo
+		req         OrderRequest
+		marketPrice float64
+		want        OrderRequest
+		wantErr     string
+	}{
+		{
+			name: "limit rounded to steps",
+			info: btcusdt,
+			req:  OrderRequest{Type: OrderTypeLimit, Quantity: 0.123456789, Price: 30000.456},
+			want: OrderRequest{Type: OrderTypeLimit, Quantity: 0.12345, Price: 30000.46},
+		},
+		{
+			name: "limit on steps",
+			info: btcusdt,
+			req:  OrderRequest{Type: OrderTypeLimit, Quantity: 0.3, Price: 30000.1},
+			want: OrderRequest{Type: OrderTypeLimit, Quantity: 0.3, Price: 30000.1},
+		},
+		{
+			name: "tiny tick size",
+			info: shibusdt,
+			req:  OrderRequest{Type: OrderTypeLimit, Quantity: 1234567.8, Price: 0.000012345678},
+			want: OrderRequest{Type: OrderTypeLimit, Quantity: 1234567, Price: 0.00001235},
+		},
+		{
+			name: "stop price rounded",
+			info: btcusdt,
+			req:  OrderRequest{Type: OrderTypeStopLossLimit, Quantity: 0.01, Price: 29000.004, StopPrice: 29100.006},
+			want: OrderRequest{Type: OrderTypeStopLossLimit, Quantity: 0.01, Price: 29000, StopPrice: 29100.01},
+		},
+		{
+			name: "market price left alone",
+			info: btcusdt,
+			req:  OrderRequest{Type: OrderTypeMarket, Quantity: 0.012345678, Price: 30000.456},
+			want: OrderRequest{Type: OrderTypeMarket, Quantity: 0.01234, Price: 30000.456},
+		},
+		{
+			name:        "market without a price skips the notional",
+			info:        btcusdt,
+			req:         OrderRequest{Type: OrderTypeMarket, Quantity: 0.0001},
+			marketPrice: 0,
+			want:        OrderRequest{Type: OrderTypeMarket, Quantity: 0.0001},
+		},
+		{
+			name:    "sub-step quantity",
+			info:    btcusdt,
+			req:     OrderRequest{Type: OrderTypeLimit, Quantity: 0.000004, Price: 30000},
+			wantErr: "invalid order: quantity 0.000004 BTC rounds to 0 with the BTCUSDT step size 0.00001",
+		},
+		{
+			name:    "quantity above maximum",
+			info:    btcusdt,
+			req:     OrderRequest{Type: OrderTypeLimit, Quantity: 10000, Price: 30000},
+			wantErr: "invalid order: quantity 10000 BTC is above the BTCUSDT maximum of 9000",
+		},
+		{
+			name:    "quantity below minimum",
+			info:    SymbolInfo{Symbol: "ETHUSDT", BaseAsset: "ETH", StepSize: 0.0001, MinQty: 0.001},
+			req:     OrderRequest{Type: OrderTypeLimit, Quantity: 0.00055, Price: 2000},
+			wantErr: "invalid order: quantity 0.0005 ETH is below the ETHUSDT minimum of 0.001",
+		},
+		{
+			name:    "price rounds to zero",
+			info:    btcusdt,
+			req:     OrderRequest{Type: OrderTypeLimit, Quantity: 1, Price: 0.004},
+			wantErr: "invalid order: price rounds to 0 with the BTCUSDT tick size 0.01",
+		},
+		{
+			name:    "stop price above maximum",
+			info:    btcusdt,
+			req:     OrderRequest{Type: OrderTypeStopLossLimit, Quantity: 0.01, Price: 29000, StopPrice: 2000000},
+			wantErr: "invalid order: stop price 2000000 USDT is above the BTCUSDT maximum of 1000000",
+		},
+		{
+			name:    "limit below minimum notional",
+			info:    btcusdt,
+			req:     OrderRequest{Type: OrderTypeLimit, Quantity: 0.0001, Price: 30000},
+			wantErr: "invalid order: order value 3 USDT is below the BTCUSDT minimum notional of 5 USDT",
+		},
+		{
+			name:        "market below minimum notional",
+			info:        btcusdt,
+			req:         OrderRequest{Type: OrderTypeMarket, Quantity: 0.0001},
+			marketPrice: 30000,
+			wantErr:     "invalid order: order value 3 USDT is below the BTCUSDT minimum notional of 5 USDT",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			err := tt.info.NormalizeOrder(&req, tt.marketPrice)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr || !errors.Is(err, ErrInvalidOrder) {
+					t.Fatalf("NormalizeOrder error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeOrder error = %v", err)
+			}
+			if req != tt.want {
+				t.Errorf("NormalizeOrder = %+v, want %+v", req, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeOCO(t *testing.T) {
+	noOCO := btcusdt
+	noOCO.OCOAllowed = false
+
+	tests := []struct {
+		name    string
+		info    SymbolInfo
+		req     OCORequest
+		want    OCORequest
+		wantErr string
+	}{
+		{
+			name: "rounded to steps",
+			info: btcusdt,
+			req:  OCORequest{Quantity: 0.0123456, Price: 31000.004, StopPrice: 29000.006, StopLimitPrice: 28900.001},
+			want: OCORequest{Quantity: 0.01234, Price: 31000, StopPrice: 29000.01, StopLimitPrice: 28900},
+		},
+		{
+			name:    "not allowed",
+			info:    noOCO,
+			req:     OCORequest{Quantity: 0.01, Price: 31000, StopPrice: 29000, StopLimitPrice: 28900},
+			wantErr: "invalid order: OCO orders are not allowed on BTCUSDT",
+		},
+		{
+			name:    "sub-step quantity",
+			info:    btcusdt,
+			req:     OCORequest{Quantity: 0.000009, Price: 31000, StopPrice: 29000, StopLimitPrice: 28900},
+			wantErr: "invalid order: quantity 0.000009 BTC rounds to 0 with the BTCUSDT step size 0.00001",
+		},
+		{
+			name:    "stop limit price rounds to zero",
+			info:    btcusdt,
+			req:     OCORequest{Quantity: 0.01, Price: 31000, StopPrice: 29000, StopLimitPrice: 0.001},
+			wantErr: "invalid order: stop limit price rounds to 0 with the BTCUSDT tick size 0.01",
+		},
+		{
+			name:    "stop leg below minimum notional",
+			info:    btcusdt,
+			req:     OCORequest{Quantity: 0.0002, Price: 31000, StopPrice: 24100, StopLimitPrice: 24000},
+			wantErr: "invalid order: order value 4.8 USDT is below the BTCUSDT minimum notional of 5 USDT",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			err := tt.info.NormalizeOCO(&req)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr || !errors.Is(err, ErrInvalidOrder) {
+					t.Fatalf("NormalizeOCO error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeOCO error = %v", err)
+			}
+			if req != tt.want {
+				t.Errorf("NormalizeOCO = %+v, want %+v", req, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package indicators
+
+import (
+	"math"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+)
+
+// ADXValue is a value of the average directional index.
+type ADXValue struct {
+	ADX     float64 // Strength of the trend, from 0 to 100, whatever its direction
+	PlusDI  float64 // Positive directional indicator
+	MinusDI float64 // Negative directional indicator
+}
+
+// ADXStream is Wilder's average directional index of candles. The directional indicators are ready after
+// period moves, and the index, Wilder's average of their directional index, after period more.
+type ADXStream struct {
+	period              int
+	tr, plusDM, minusDM wilderSum
+	adx                 wilder
+	prev                model.Candle
+	started             bool
+	value               ADXValue
+}
+
+// wilderSum is a running sum with Wilder's smoothing: the sum of the first period values, then losing
+// 1/period of itself and gaining each new value. Ratios of such sums equal ratios of Wilder's averages.
+type wilderSum struct {
+	period int
+	count  int
+	value  float64
+}
+
+func (w *wilderSum) update(v float64) {
+	w.count++
+	if w.count <= w.period {
+		w.value += v
+	} else {
+		w.value += v - w.value/float64(w.period)
+	}
+}
+
+func (w *wilderSum) ready() bool { return w.count >= w.period }
+
+// NewADXStream creates an average directional index over period candles, commonly 14.
+func NewADXStream(period int) *ADXStream {
+	period = validPeriod(period)
+	return &ADXStream{
+		period:  period,
+		tr:      wilderSum{period: period},
+		plusDM:  wilderSum{period: period},
+		minusDM: wilderSum{period: period},
+		adx:     wilder{period: period},
+		value:   ADXValue{ADX: nan, PlusDI: nan, MinusDI: nan},
+	}
+}
+
+// Update adds a candle and returns the index and directional indicators, NaN until they are ready.
+func (s *ADXStream) Update(c model.Candle) ADXValue {
+	if !s.started {
+		s.prev, s.started = c, true
+		return s.value
+	}
+	up, down := c.High-s.prev.High, s.prev.Low-c.Low
+	plusDM, minusDM := 0.0, 0.0
+	if up > down && up > 0 {
+		plusDM = up
+	}
+	if down > up && down > 0 {
+		minusDM = down
+	}
+	s.tr.update(trueRange(c, s.prev.Close))
+	s.plusDM.update(plusDM)
+	s.minusDM.update(minusDM)
+	s.prev = c
+	if !s.tr.ready() {
+		return s.value
+	}
+
+	plusDI, minusDI := 0.0, 0.0
+	if s.tr.value > 0 {
+		plusDI, minusDI = 100*s.plusDM.value/s.tr.value, 100*s.minusDM.value/s.tr.value
+	}
+	dx := 0.0
+	if sum := plusDI + minusDI; sum > 0 {
+		dx = 100 * math.Abs(plusDI-minusDI) / sum
+	}
+	s.adx.update(dx)
+	s.value = ADXValue{ADX: nan, PlusDI: plusDI, MinusDI: minusDI}
+	if s.adx.ready() {
+		s.value.ADX = s.adx.value
+	}
+	return s.value
+}
+
+// Value returns the current index and directional indicators.
+func (s *ADXStream) Value() ADXValue { return s.value }
+
+// Ready reports whether the index is ready.
+func (s *ADXStream) Ready() bool { return s.adx.ready() }
+
+// ADX returns the average directional index of candles over period.
+func ADX(candles []model.Candle, period int) []ADXValue {
+	s := NewADXStream(period)
+	out := make([]ADXValue, len(candles))
+	for i, c := range candles {
+		out[i] = s.Update(c)
+	}
+	return out
+}
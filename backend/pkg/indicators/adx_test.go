@@ -0,0 +1,40 @@
+package indicators
+
+import "testing"
+
+func TestADX(t *testing.T) {
+	tests := []struct {
+		i                    int
+		adx, plusDI, minusDI float64
+	}{
+		{13, nan, nan, nan},
+		{14, nan, 26.067212, 25.613079},
+		{26, nan, 15.10867, 34.834194},
+		{27, 28.608497, 15.22997, 32.275075},
+		{28, 29.517788, 14.20061, 34.21488},
+		{45, 25.304866, 20.232537, 28.099632},
+		{59, 30.719791, 8.725052, 31.648852},
+	}
+	got := ADX(referenceCandles(), 14)
+	for _, tt := range tests {
+		v := got[tt.i]
+		if !equal(v.ADX, tt.adx) || !equal(v.PlusDI, tt.plusDI) || !equal(v.MinusDI, tt.minusDI) {
+			t.Errorf("ADX[%d] = %+v, want {ADX:%v PlusDI:%v MinusDI:%v}", tt.i, v, tt.adx, tt.plusDI, tt.minusDI)
+		}
+	}
+}
+
+func TestADXStream(t *testing.T) {
+	candles := referenceCandles()
+	batch := ADX(candles, 14)
+	s := NewADXStream(14)
+	for i, c := range candles {
+		got := s.Update(c)
+		if !equal(got.ADX, batch[i].ADX) || !equal(got.PlusDI, batch[i].PlusDI) || !equal(got.MinusDI, batch[i].MinusDI) || !equal(s.Value().ADX, got.ADX) {
+			t.Fatalf("ADX stream at %d = %+v, batch %+v", i, got, batch[i])
+		}
+		if s.Ready() != (i >= 27) {
+			t.Fatalf("ADX stream Ready at %d = %v", i, s.Ready())
+		}
+	}
+}
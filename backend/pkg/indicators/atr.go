@@ -0,0 +1,75 @@
+package indicators
+
+import (
+	"math"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+)
+
+// trueRange returns the largest of the candle's range and its distances from the previous close.
+func trueRange(c model.Candle, prevClose float64) float64 {
+	return math.Max(c.High-c.Low, math.Max(math.Abs(c.High-prevClose), math.Abs(c.Low-prevClose)))
+}
+
+// wilder is a running average with Wilder's smoothing: the simple average of the first period values,
+// then moving by 1/period of each new value.
+type wilder struct {
+	period int
+	count  int
+	value  float64
+}
+
+func (w *wilder) update(v float64) {
+	w.count++
+	n := float64(w.period)
+	if w.count <= w.period {
+		w.value += v / n
+	} else {
+		w.value += (v - w.value) / n
+	}
+}
+
+func (w *wilder) ready() bool { return w.count >= w.period }
+
+// ATRStream is the average true range of candles, with Wilder's smoothing. The true range needs the previous
+// close, so the first candle only starts the series.
+type ATRStream struct {
+	avg       wilder
+	prevClose float64
+	started   bool
+}
+
+// NewATRStream creates an average true range over period candles, commonly 14.
+func NewATRStream(period int) *ATRStream {
+	return &ATRStream{avg: wilder{period: validPeriod(period)}}
+}
+
+// Update adds a candle and returns the average true range, NaN until period true ranges have been seen.
+func (s *ATRStream) Update(c model.Candle) float64 {
+	if s.started {
+		s.avg.update(trueRange(c, s.prevClose))
+	}
+	s.prevClose, s.started = c.Close, true
+	return s.Value()
+}
+
+// Value returns the current average true range, NaN until period true ranges have been seen.
+func (s *ATRStream) Value() float64 {
+	if !s.avg.ready() {
+		return nan
+	}
+	return s.avg.value
+}
+
+// Ready reports whether period true ranges have been seen.
+func (s *ATRStream) Ready() bool { return s.avg.ready() }
+
+// ATR returns the average true range of candles over period.
+func ATR(candles []model.Candle, period int) []float64 {
+	s := NewATRStream(period)
+	out := make([]float64, len(candles))
+	for i, c := range candles {
+		out[i] = s.Update(c)
+	}
+	return out
+}
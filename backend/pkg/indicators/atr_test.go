@@ -0,0 +1,36 @@
+package indicators
+
+import (
+	"testing"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+)
+
+func TestATR(t *testing.T) {
+	candles := referenceCandles()
+	batch := ATR(candles, 14)
+	checkGolden(t, "ATR", batch, []golden{{0, nan}, {13, nan}, {14, 1.572857}, {15, 1.656224}, {30, 1.724235}, {59, 1.752767}})
+
+	s := NewATRStream(14)
+	checkStream(t, "ATR", batch, func(i int) float64 { return s.Update(candles[i]) }, s.Value, s.Ready)
+}
+
+func TestTrueRange(t *testing.T) {
+	tests := []struct {
+		name      string
+		candle    model.Candle
+		prevClose float64
+		want      float64
+	}{
+		{"range", model.Candle{High: 12, Low: 10}, 11, 2},
+		{"gap up", model.Candle{High: 15, Low: 14}, 11, 4},
+		{"gap down", model.Candle{High: 9, Low: 8}, 11, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := trueRange(tt.candle, tt.prevClose); got != tt.want {
+				t.Errorf("trueRange = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package indicators
+
+import "math"
+
+// BollingerValue is a value of the Bollinger bands.
+type BollingerValue struct {
+	Middle float64 // Simple moving average
+	Upper  float64 // Middle plus the standard deviation times the factor
+	Lower  float64 // Middle minus the standard deviation times the factor
+}
+
+// BollingerStream is the Bollinger bands of a series: its simple moving average, with bands a number of
+// population standard deviations above and below it.
+type BollingerStream struct {
+	window *window
+	factor float64
+	sum    float64
+}
+
+// NewBollingerStream creates Bollinger bands over period values, factor standard deviations wide, commonly 20 and 2.
+func NewBollingerStream(period int, factor float64) *BollingerStream {
+	return &BollingerStream{window: newWindow(period), factor: factor}
+}
+
+// Update adds a price and returns the bands, NaN until period prices have been added.
+func (s *BollingerStream) Update(price float64) BollingerValue {
+	evicted, full := s.window.push(price)
+	s.sum += price
+	if full {
+		s.sum -= evicted
+	}
+	return s.Value()
+}
+
+// Value returns the current bands, NaN until period prices have been added.
+func (s *BollingerStream) Value() BollingerValue {
+	if !s.window.full() {
+		return BollingerValue{Middle: nan, Upper: nan, Lower: nan}
+	}
+	n := float64(len(s.window.values))
+	mean := s.sum / n
+	// The deviation is summed over the window rather than kept as a running sum of squares,
+	// which loses precision at the prices of assets such as BTC
+	variance := 0.0
+	for _, v := range s.window.values {
+		variance += (v - mean) * (v - mean)
+	}
+	stdDev := math.Sqrt(variance / n)
+	return BollingerValue{Middle: mean, Upper: mean + s.factor*stdDev, Lower: mean - s.factor*stdDev}
+}
+
+// Ready reports whether the bands cover a full period.
+func (s *BollingerStream) Ready() bool { return s.window.full() }
+
+// Bollinger returns the Bollinger bands of prices.
+func Bollinger(prices []float64, period int, factor float64) []BollingerValue {
+	s := NewBollingerStream(period, factor)
+	out := make([]BollingerValue, len(prices))
+	for i, p := range prices {
+		out[i] = s.Update(p)
+	}
+	return out
+}
@@ -0,0 +1,50 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBollinger(t *testing.T) {
+	tests := []struct {
+		i                    int
+		middle, upper, lower float64
+	}{
+		{18, nan, nan, nan},
+		{19, 97.991, 103.282207, 92.699793},
+		{20, 97.58, 103.51705, 91.64295},
+		{40, 89.6885, 93.120277, 86.256723},
+		{59, 88.3225, 93.087971, 83.557029},
+	}
+	got := Bollinger(referenceCloses(), 20, 2)
+	for _, tt := range tests {
+		v := got[tt.i]
+		if !equal(v.Middle, tt.middle) || !equal(v.Upper, tt.upper) || !equal(v.Lower, tt.lower) {
+			t.Errorf("Bollinger[%d] = %+v, want {Middle:%v Upper:%v Lower:%v}", tt.i, v, tt.middle, tt.upper, tt.lower)
+		}
+	}
+}
+
+func TestBollingerStream(t *testing.T) {
+	closes := referenceCloses()
+	batch := Bollinger(closes, 20, 2)
+	s := NewBollingerStream(20, 2)
+	for i, p := range closes {
+		got := s.Update(p)
+		if !equal(got.Middle, batch[i].Middle) || !equal(got.Upper, batch[i].Upper) || !equal(got.Lower, batch[i].Lower) || !equal(s.Value().Upper, got.Upper) {
+			t.Fatalf("Bollinger stream at %d = %+v, batch %+v", i, got, batch[i])
+		}
+		if s.Ready() == math.IsNaN(got.Middle) {
+			t.Fatalf("Bollinger stream Ready at %d = %v", i, s.Ready())
+		}
+	}
+}
+
+func TestBollingerPrecisionAtHighPrices(t *testing.T) {
+	// A running sum of squares loses the deviation of small moves at BTC prices
+	prices := []float64{100000.01, 100000.02, 100000.03}
+	band := Bollinger(prices, 3, 1)[2]
+	if want := math.Sqrt(2.0/3) * 0.01; math.Abs(band.Upper-band.Middle-want) > 1e-9 {
+		t.Errorf("deviation = %v, want %v", band.Upper-band.Middle, want)
+	}
+}
@@ -0,0 +1,73 @@
+package indicators
+
+import "github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+
+// IchimokuValue is a value of the Ichimoku cloud, as computed at a candle. Charts plot the two leading spans
+// kijun periods ahead of the candle they are computed at, and the lagging span is the close plotted kijun periods back.
+type IchimokuValue struct {
+	Tenkan  float64 // Conversion line: midpoint of the high and low over the tenkan period
+	Kijun   float64 // Base line: midpoint of the high and low over the kijun period
+	SenkouA float64 // Leading span A: midpoint of the conversion and base lines
+	SenkouB float64 // Leading span B: midpoint of the high and low over the senkou period
+}
+
+// IchimokuStream is the Ichimoku cloud of candles.
+type IchimokuStream struct {
+	tenkanHighs, tenkanLows *window
+	kijunHighs, kijunLows   *window
+	senkouHighs, senkouLows *window
+}
+
+// NewIchimokuStream creates an Ichimoku cloud with the given periods, commonly 9, 26 and 52.
+func NewIchimokuStream(tenkan, kijun, senkou int) *IchimokuStream {
+	return &IchimokuStream{
+		tenkanHighs: newWindow(tenkan), tenkanLows: newWindow(tenkan),
+		kijunHighs: newWindow(kijun), kijunLows: newWindow(kijun),
+		senkouHighs: newWindow(senkou), senkouLows: newWindow(senkou),
+	}
+}
+
+// Update adds a candle and returns the cloud. Each line is NaN until its period is full.
+func (s *IchimokuStream) Update(c model.Candle) IchimokuValue {
+	for _, w := range []*window{s.tenkanHighs, s.kijunHighs, s.senkouHighs} {
+		w.push(c.High)
+	}
+	for _, w := range []*window{s.tenkanLows, s.kijunLows, s.senkouLows} {
+		w.push(c.Low)
+	}
+	return s.Value()
+}
+
+// Value returns the current cloud.
+func (s *IchimokuStream) Value() IchimokuValue {
+	v := IchimokuValue{
+		Tenkan:  midpoint(s.tenkanHighs, s.tenkanLows),
+		Kijun:   midpoint(s.kijunHighs, s.kijunLows),
+		SenkouB: midpoint(s.senkouHighs, s.senkouLows),
+	}
+	// NaN lines leave span A NaN as well
+	v.SenkouA = (v.Tenkan + v.Kijun) / 2
+	return v
+}
+
+// Ready reports whether every line is ready.
+func (s *IchimokuStream) Ready() bool {
+	return s.tenkanHighs.full() && s.kijunHighs.full() && s.senkouHighs.full()
+}
+
+func midpoint(highs, lows *window) float64 {
+	if !highs.full() {
+		return nan
+	}
+	return (highs.max() + lows.min()) / 2
+}
+
+// Ichimoku returns the Ichimoku cloud of candles, each value as computed at its candle.
+func Ichimoku(candles []model.Candle, tenkan, kijun, senkou int) []IchimokuValue {
+	s := NewIchimokuStream(tenkan, kijun, senkou)
+	out := make([]IchimokuValue, len(candles))
+	for i, c := range candles {
+		out[i] = s.Update(c)
+	}
+	return out
+}
@@ -0,0 +1,39 @@
+package indicators
+
+import "testing"
+
+func TestIchimoku(t *testing.T) {
+	tests := []struct {
+		i                      int
+		tenkan, kijun, senkouB float64
+	}{
+		{7, nan, nan, nan},
+		{8, 99.8, nan, nan},
+		{25, 93.475, 96.075, nan},
+		{51, 89.79, 89.685, 94.21},
+		{59, 86.32, 87.885, 91.955},
+	}
+	got := Ichimoku(referenceCandles(), 9, 26, 52)
+	for _, tt := range tests {
+		v := got[tt.i]
+		if !equal(v.Tenkan, tt.tenkan) || !equal(v.Kijun, tt.kijun) || !equal(v.SenkouB, tt.senkouB) || !equal(v.SenkouA, (tt.tenkan+tt.kijun)/2) {
+			t.Errorf("Ichimoku[%d] = %+v, want {Tenkan:%v Kijun:%v SenkouB:%v}", tt.i, v, tt.tenkan, tt.kijun, tt.senkouB)
+		}
+	}
+}
+
+func TestIchimokuStream(t *testing.T) {
+	candles := referenceCandles()
+	batch := Ichimoku(candles, 9, 26, 52)
+	s := NewIchimokuStream(9, 26, 52)
+	for i, c := range candles {
+		got := s.Update(c)
+		want := batch[i]
+		if !equal(got.Tenkan, want.Tenkan) || !equal(got.Kijun, want.Kijun) || !equal(got.SenkouA, want.SenkouA) || !equal(got.SenkouB, want.SenkouB) || !equal(s.Value().SenkouB, got.SenkouB) {
+			t.Fatalf("Ichimoku stream at %d = %+v, batch %+v", i, got, want)
+		}
+		if s.Ready() != (i >= 51) {
+			t.Fatalf("Ichimoku stream Ready at %d = %v", i, s.Ready())
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+)
+
+// wilderCloses are the closing prices of the 14-period RSI example published by StockCharts after Wilder's
+// "New Concepts in Technical Trading Systems".
+var wilderCloses = []float64{
+	44.34, 44.09, 44.15, 43.61, 44.33, 44.83, 45.10, 45.42, 45.84, 46.08, 45.89, 46.03, 45.61, 46.28, 46.28,
+	46.00, 46.03, 46.41, 46.22, 45.64, 46.21, 46.25, 45.71, 46.45, 45.78, 45.35, 44.03, 44.18, 44.22, 44.57,
+	43.42, 42.66, 43.13,
+}
+
+// ohlcv is the open, high, low, close and volume of the 60 reference candles. The expected values of the
+// indicators on them were computed independently, straight from each indicator's textbook definition.
+var ohlcv = [][5]float64{
+	{100, 100.31, 99.55, 99.69, 1711},
+	{99.69, 99.83, 99.16, 99.31, 1663},
+	{99.31, 100.7, 99.07, 100.55, 1522},
+	{100.55, 100.91, 100.28, 100.77, 1180},
+	{100.77, 101.82, 98.19, 98.72, 2460},
+	{98.72, 99.84, 97.83, 98.17, 1292},
+	{98.17, 98.76, 97.78, 98.53, 1107},
+	{98.53, 101.31, 98.12, 100.56, 1433},
+	{100.56, 100.62, 99.76, 100.14, 1957},
+	{100.14, 100.91, 99.91, 100.44, 1795},
+	{100.44, 100.59, 99.22, 99.47, 1215},
+	{99.47, 100.12, 97.37, 97.69, 2288},
+	{97.69, 98.17, 97.28, 97.57, 1511},
+	{97.57, 97.59, 97.16, 97.5, 1631},
+	{97.5, 99.41, 97.43, 99.19, 2288},
+	{99.19, 99.55, 96.81, 97.64, 1810},
+	{97.64, 97.95, 95.76, 96.51, 1606},
+	{96.51, 96.62, 93.99, 94.16, 1993},
+	{94.16, 95.58, 91.81, 92.01, 1456},
+	{92.01, 92.07, 91.1, 91.2, 1585},
+	{91.2, 91.71, 90.33, 91.47, 1020},
+	{91.47, 92.16, 90.92, 92.15, 2281},
+	{92.15, 93.51, 91.06, 93.21, 2193},
+	{93.21, 93.51, 92.16, 92.5, 1506},
+	{92.5, 93.39, 90.74, 91.04, 1100},
+	{91.04, 91.09, 90.49, 90.88, 1624},
+	{90.88, 91.11, 89.6, 90.16, 1428},
+	{90.16, 91.41, 89.62, 90.19, 1974},
+	{90.19, 90.26, 88.62, 88.7, 2329},
+	{88.7, 88.9, 87.32, 88.38, 2156},
+	{88.38, 88.71, 86.96, 87.55, 906},
+	{87.55, 88.78, 87.4, 88.45, 1076},
+	{88.45, 88.6, 87.96, 88.26, 972},
+	{88.26, 88.34, 88.17, 88.26, 2286},
+	{88.26, 88.46, 87.8, 87.81, 1419},
+	{87.81, 89.52, 87.1, 88.78, 2488},
+	{88.78, 88.91, 87.37, 87.58, 1382},
+	{87.58, 87.92, 86.6, 87.83, 1074},
+	{87.83, 90.58, 87.54, 90.39, 1723},
+	{90.39, 91.05, 90.17, 90.28, 2463},
+	{90.28, 92.01, 90.24, 91.37, 1084},
+	{91.37, 92.51, 90.7, 92.42, 2124},
+	{92.42, 92.77, 91.44, 92.04, 2249},
+	{92.04, 92.39, 88.15, 89.08, 2058},
+	{89.08, 89.91, 89, 89.27, 847},
+	{89.27, 89.55, 88.86, 89.47, 1977},
+	{89.47, 90.76, 88, 90.6, 2424},
+	{90.6, 90.85, 89.07, 89.35, 1186},
+	{89.35, 89.93, 88.53, 89.59, 2229},
+	{89.59, 90.37, 87.87, 87.97, 2159},
+	{87.97, 89.71, 87.19, 89.31, 2075},
+	{89.31, 89.64, 88.26, 88.31, 2142},
+	{88.31, 89.14, 86.85, 87.37, 1482},
+	{87.37, 88.17, 86.9, 87.18, 1089},
+	{87.18, 87.82, 86.4, 87.6, 1048},
+	{87.6, 88.28, 85.24, 86.53, 1917},
+	{86.53, 87.06, 85.7, 85.76, 2451},
+	{85.76, 86.26, 85.25, 85.89, 2387},
+	{85.89, 86.31, 83.82, 83.98, 1228},
+	{83.98, 84.08, 83, 83.36, 1797},
+}
+
+// referenceCandles returns the reference candles, one hour apart.
+func referenceCandles() []model.Candle {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	candles := make([]model.Candle, len(ohlcv))
+	for i, v := range ohlcv {
+		open := start.Add(time.Duration(i) * time.Hour)
+		candles[i] = model.Candle{
+			Symbol: "TESTUSDT", Interval: "1h", OpenTime: open, CloseTime: open.Add(time.Hour - time.Millisecond),
+			Open: v[0], High: v[1], Low: v[2], Close: v[3], Volume: v[4],
+		}
+	}
+	return candles
+}
+
+// referenceCloses returns the closing prices of the reference candles.
+func referenceCloses() []float64 {
+	return model.ClosePrices(referenceCandles())
+}
+
+// golden is an expected value of an indicator at an index of its input; NaN expects the indicator to be warming up.
+type golden struct {
+	i    int
+	want float64
+}
+
+// tolerance is the largest difference accepted from a reference value, which is rounded to 6 decimals.
+const tolerance = 1e-5
+
+// equal reports whether got matches want within the tolerance, NaN matching only NaN.
+func equal(got, want float64) bool {
+	if math.IsNaN(want) || math.IsNaN(got) {
+		return math.IsNaN(want) && math.IsNaN(got)
+	}
+	return math.Abs(got-want) <= tolerance
+}
+
+// checkGolden compares a batch series with its reference values.
+func checkGolden(t *testing.T, name string, got []float64, want []golden) {
+	t.Helper()
+	for _, g := range want {
+		if !equal(got[g.i], g.want) {
+			t.Errorf("%s[%d] = %v, want %v", name, g.i, got[g.i], g.want)
+		}
+	}
+}
+
+// checkStream feeds every input to a stream and checks that Update, Value and Ready agree with the batch series
+// at each of them. Ready must hold exactly when the batch value is defined.
+func checkStream(t *testing.T, name string, batch []float64, update func(i int) float64, value func() float64, ready func() bool) {
+	t.Helper()
+	for i, want := range batch {
+		if got := update(i); !equal(got, want) {
+			t.Fatalf("%s stream Update at %d = %v, batch %v", name, i, got, want)
+		}
+		if got := value(); !equal(got, want) {
+			t.Fatalf("%s stream Value at %d = %v, batch %v", name, i, got, want)
+		}
+		if ready != nil && ready() == math.IsNaN(want) {
+			t.Fatalf("%s stream Ready at %d = %v with batch value %v", name, i, ready(), want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package indicators
+
+// MACDValue is a value of the moving average convergence divergence.
+type MACDValue struct {
+	MACD      float64 // Fast EMA minus slow EMA
+	Signal    float64 // EMA of the MACD
+	Histogram float64 // MACD minus signal
+}
+
+// MACDStream is the moving average convergence divergence of a series.
+type MACDStream struct {
+	fast, slow, signal *EMAStream
+	value              MACDValue
+}
+
+// NewMACDStream creates a MACD with the given EMA periods, commonly 12, 26 and 9.
+func NewMACDStream(fastPeriod, slowPeriod, signalPeriod int) *MACDStream {
+	return &MACDStream{
+		fast:   NewEMAStream(fastPeriod),
+		slow:   NewEMAStream(slowPeriod),
+		signal: NewEMAStream(signalPeriod),
+		value:  MACDValue{MACD: nan, Signal: nan, Histogram: nan},
+	}
+}
+
+// Update adds a price and returns the MACD. The MACD is NaN until the slow EMA is ready, and the signal
+// and histogram until the signal EMA has seen signalPeriod MACD values.
+func (s *MACDStream) Update(price float64) MACDValue {
+	fast, slow := s.fast.Update(price), s.slow.Update(price)
+	if !s.slow.Ready() || !s.fast.Ready() {
+		return s.value
+	}
+	macd := fast - slow
+	signal := s.signal.Update(macd)
+	s.value = MACDValue{MACD: macd, Signal: signal, Histogram: macd - signal}
+	return s.value
+}
+
+// Value returns the current MACD.
+func (s *MACDStream) Value() MACDValue { return s.value }
+
+// Ready reports whether the signal line is ready.
+func (s *MACDStream) Ready() bool { return s.signal.Ready() }
+
+// MACD returns the moving average convergence divergence of prices.
+func MACD(prices []float64, fastPeriod, slowPeriod, signalPeriod int) []MACDValue {
+	s := NewMACDStream(fastPeriod, slowPeriod, signalPeriod)
+	out := make([]MACDValue, len(prices))
+	for i, p := range prices {
+		out[i] = s.Update(p)
+	}
+	return out
+}
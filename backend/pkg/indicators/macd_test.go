@@ -0,0 +1,39 @@
+package indicators
+
+import "testing"
+
+func TestMACD(t *testing.T) {
+	tests := []struct {
+		i                       int
+		macd, signal, histogram float64
+	}{
+		{24, nan, nan, nan},
+		{25, -3.260984, nan, nan},
+		{33, -3.143422, -3.275819, 0.132397},
+		{34, -3.067938, -3.234243, 0.166304},
+		{45, -1.136783, -1.646524, 0.509741},
+		{59, -1.758967, -1.398109, -0.360858},
+	}
+	got := MACD(referenceCloses(), 12, 26, 9)
+	for _, tt := range tests {
+		v := got[tt.i]
+		if !equal(v.MACD, tt.macd) || !equal(v.Signal, tt.signal) || !equal(v.Histogram, tt.histogram) {
+			t.Errorf("MACD[%d] = %+v, want {MACD:%v Signal:%v Histogram:%v}", tt.i, v, tt.macd, tt.signal, tt.histogram)
+		}
+	}
+}
+
+func TestMACDStream(t *testing.T) {
+	closes := referenceCloses()
+	batch := MACD(closes, 12, 26, 9)
+	s := NewMACDStream(12, 26, 9)
+	for i, p := range closes {
+		got := s.Update(p)
+		if !equal(s.Value().Histogram, got.Histogram) || !equal(got.MACD, batch[i].MACD) || !equal(got.Signal, batch[i].Signal) || !equal(got.Histogram, batch[i].Histogram) {
+			t.Fatalf("MACD stream at %d = %+v, batch %+v", i, got, batch[i])
+		}
+		if s.Ready() != (i >= 33) {
+			t.Fatalf("MACD stream Ready at %d = %v", i, s.Ready())
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package indicators
+
+// SMAStream is the simple moving average of the last period values.
+type SMAStream struct {
+	window *window
+	sum    float64
+}
+
+// NewSMAStream creates a simple moving average over period values.
+func NewSMAStream(period int) *SMAStream {
+	return &SMAStream{window: newWindow(period)}
+}
+
+// Update adds a value and returns the average, NaN until period values have been added.
+func (s *SMAStream) Update(v float64) float64 {
+	evicted, full := s.window.push(v)
+	s.sum += v
+	if full {
+		s.sum -= evicted
+	}
+	return s.Value()
+}
+
+// Value returns the current average, NaN until period values have been added.
+func (s *SMAStream) Value() float64 {
+	if !s.window.full() {
+		return nan
+	}
+	return s.sum / float64(len(s.window.values))
+}
+
+// Ready reports whether the average covers a full period.
+func (s *SMAStream) Ready() bool { return s.window.full() }
+
+// SMA returns the simple moving average of values over period.
+func SMA(values []float64, period int) []float64 {
+	s := NewSMAStream(period)
+	return series(values, s.Update)
+}
+
+// WMAStream is the linearly weighted moving average of the last period values: the newest value weighs period,
+// the oldest 1.
+type WMAStream struct {
+	window   *window
+	sum      float64 // Sum of the values in the window
+	weighted float64 // Sum of the values times their weights
+}
+
+// NewWMAStream creates a weighted moving average over period values.
+func NewWMAStream(period int) *WMAStream {
+	return &WMAStream{window: newWindow(period)}
+}
+
+// Update adds a value and returns the average, NaN until period values have been added.
+func (s *WMAStream) Update(v float64) float64 {
+	n := float64(len(s.window.values))
+	evicted, full := s.window.push(v)
+	if full {
+		// Every value loses one weight, the evicted one all of its weight 1, and the new value comes in at n
+		s.weighted += n*v - s.sum
+		s.sum += v - evicted
+	} else {
+		s.weighted += float64(s.window.count) * v
+		s.sum += v
+	}
+	return s.Value()
+}
+
+// Value returns the current average, NaN until period values have been added.
+func (s *WMAStream) Value() float64 {
+	if !s.window.full() {
+		return nan
+	}
+	n := float64(len(s.window.values))
+	return s.weighted / (n * (n + 1) / 2)
+}
+
+// Ready reports whether the average covers a full period.
+func (s *WMAStream) Ready() bool { return s.window.full() }
+
+// WMA returns the weighted moving average of values over period.
+func WMA(values []float64, period int) []float64 {
+	s := NewWMAStream(period)
+	return series(values, s.Update)
+}
+
+// EMAStream is the exponential moving average of a series, with the smoothing factor 2/(period+1).
+// It starts as the simple average of the first period values.
+type EMAStream struct {
+	period int
+	alpha  float64
+	count  int
+	sum    float64
+	value  float64
+}
+
+// NewEMAStream creates an exponential moving average over period values.
+func NewEMAStream(period int) *EMAStream {
+	period = validPeriod(period)
+	return &EMAStream{period: period, alpha: 2 / (float64(period) + 1)}
+}
+
+// Update adds a value and returns the average, NaN until period values have been added.
+func (s *EMAStream) Update(v float64) float64 {
+	s.count++
+	switch {
+	case s.count < s.period:
+		s.sum += v
+	case s.count == s.period:
+		s.value = (s.sum + v) / float64(s.period)
+	default:
+		s.value += (v - s.value) * s.alpha
+	}
+	return s.Value()
+}
+
+// Value returns the current average, NaN until period values have been added.
+func (s *EMAStream) Value() float64 {
+	if !s.Ready() {
+		return nan
+	}
+	return s.value
+}
+
+// Ready reports whether period values have been added.
+func (s *EMAStream) Ready() bool { return s.count >= s.period }
+
+// EMA returns the exponential moving average of values over period.
+func EMA(values []float64, period int) []float64 {
+	s := NewEMAStream(period)
+	return series(values, s.Update)
+}
+
+// series applies update to every value in turn and returns the outputs.
+func series(values []float64, update func(float64) float64) []float64 {
+	out := make([]float64, len(values))
+	for i, v := range values {
+		out[i] = update(v)
+	}
+	return out
+}
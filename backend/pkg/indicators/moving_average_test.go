@@ -0,0 +1,42 @@
+package indicators
+
+import "testing"
+
+func TestMovingAverages(t *testing.T) {
+	closes := referenceCloses()
+	tests := []struct {
+		name   string
+		batch  func([]float64, int) []float64
+		period int
+		want   []golden
+	}{
+		{"SMA", SMA, 5, []golden{{3, nan}, {4, 99.808}, {5, 99.504}, {20, 93.07}, {59, 85.104}}},
+		{"WMA", WMA, 5, []golden{{3, nan}, {4, 99.776}, {5, 99.23}, {20, 92.200667}, {59, 84.562667}}},
+		{"EMA", EMA, 10, []golden{{8, nan}, {9, 99.688}, {10, 99.648364}, {30, 90.23872}, {59, 86.113584}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkGolden(t, tt.name, tt.batch(closes, tt.period), tt.want)
+		})
+	}
+}
+
+func TestMovingAverageStreams(t *testing.T) {
+	closes := referenceCloses()
+	sma, wma, ema := NewSMAStream(5), NewWMAStream(5), NewEMAStream(10)
+	checkStream(t, "SMA", SMA(closes, 5), func(i int) float64 { return sma.Update(closes[i]) }, sma.Value, sma.Ready)
+	checkStream(t, "WMA", WMA(closes, 5), func(i int) float64 { return wma.Update(closes[i]) }, wma.Value, wma.Ready)
+	checkStream(t, "EMA", EMA(closes, 10), func(i int) float64 { return ema.Update(closes[i]) }, ema.Value, ema.Ready)
+}
+
+func TestMovingAveragePeriodBelowOne(t *testing.T) {
+	values := []float64{3, 1, 4}
+	for name, batch := range map[string]func([]float64, int) []float64{"SMA": SMA, "WMA": WMA, "EMA": EMA} {
+		got := batch(values, 0)
+		for i, v := range values {
+			if got[i] != v {
+				t.Errorf("%s with period 0 [%d] = %v, want %v", name, i, got[i], v)
+			}
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package indicators
+
+// RSIStream is the relative strength index with Wilder's smoothing: the average gain and loss start as the
+// simple averages of the first period changes, and then move by 1/period of each new change.
+type RSIStream struct {
+	period  int
+	changes int
+	prev    float64
+	avgGain float64
+	avgLoss float64
+	hasPrev bool
+}
+
+// NewRSIStream creates a relative strength index over period changes.
+func NewRSIStream(period int) *RSIStream {
+	return &RSIStream{period: validPeriod(period)}
+}
+
+// Update adds a price and returns the index, from 0 to 100, NaN until period changes have been seen.
+func (s *RSIStream) Update(price float64) float64 {
+	if !s.hasPrev {
+		s.prev, s.hasPrev = price, true
+		return nan
+	}
+	change := price - s.prev
+	s.prev = price
+	gain, loss := 0.0, 0.0
+	if change > 0 {
+		gain = change
+	} else {
+		loss = -change
+	}
+
+	s.changes++
+	n := float64(s.period)
+	if s.changes <= s.period {
+		s.avgGain += gain / n
+		s.avgLoss += loss / n
+	} else {
+		s.avgGain = (s.avgGain*(n-1) + gain) / n
+		s.avgLoss = (s.avgLoss*(n-1) + loss) / n
+	}
+	return s.Value()
+}
+
+// Value returns the current index, NaN until period changes have been seen. A series that did not move is at 50.
+func (s *RSIStream) Value() float64 {
+	switch {
+	case !s.Ready():
+		return nan
+	case s.avgLoss == 0 && s.avgGain == 0:
+		return 50
+	case s.avgLoss == 0:
+		return 100
+	}
+	return 100 - 100/(1+s.avgGain/s.avgLoss)
+}
+
+// Ready reports whether period changes have been seen.
+func (s *RSIStream) Ready() bool { return s.changes >= s.period }
+
+// RSI returns the relative strength index of prices over period, with Wilder's smoothing.
+func RSI(prices []float64, period int) []float64 {
+	s := NewRSIStream(period)
+	return series(prices, s.Update)
+}
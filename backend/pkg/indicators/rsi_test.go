@@ -0,0 +1,46 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRSIStockCharts(t *testing.T) {
+	// StockCharts rounds its first average gain and loss to 0.24 and 0.10, which moves its published values by up to 0.07
+	published := []float64{70.53, 66.32, 66.55, 69.41, 66.36, 57.97, 62.93, 63.26, 56.06, 62.38, 54.71, 50.42, 39.99,
+		41.46, 41.87, 45.46, 37.30, 33.08, 37.77}
+	got := RSI(wilderCloses, 14)
+	for i := 0; i < 14; i++ {
+		if !math.IsNaN(got[i]) {
+			t.Errorf("RSI[%d] = %v during the warm-up, want NaN", i, got[i])
+		}
+	}
+	for i, want := range published {
+		if math.Abs(got[14+i]-want) > 0.1 {
+			t.Errorf("RSI[%d] = %.4f, want %.2f", 14+i, got[14+i], want)
+		}
+	}
+}
+
+func TestRSI(t *testing.T) {
+	tests := []struct {
+		name   string
+		prices []float64
+		period int
+		want   []golden
+	}{
+		{"wilder", wilderCloses, 14, []golden{{13, nan}, {14, 70.464135}, {15, 66.249619}, {26, 40.019424}, {32, 37.788772}}},
+		{"reference", referenceCloses(), 14, []golden{{13, nan}, {14, 47.947455}, {40, 50.543031}, {59, 28.542908}}},
+		{"flat", []float64{5, 5, 5, 5}, 3, []golden{{2, nan}, {3, 50}}},
+		{"rising", []float64{1, 2, 3, 4}, 3, []golden{{3, 100}}},
+		{"falling", []float64{4, 3, 2, 1}, 3, []golden{{3, 0}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batch := RSI(tt.prices, tt.period)
+			checkGolden(t, "RSI", batch, tt.want)
+			s := NewRSIStream(tt.period)
+			checkStream(t, "RSI", batch, func(i int) float64 { return s.Update(tt.prices[i]) }, s.Value, s.Ready)
+		})
+	}
+}
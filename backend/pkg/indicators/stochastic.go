@@ -0,0 +1,63 @@
+package indicators
+
+import "github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+
+// StochasticValue is a value of the stochastic oscillator.
+type StochasticValue struct {
+	K float64 // %K: where the close sits in the recent high-low range, from 0 to 100, smoothed
+	D float64 // %D: simple moving average of %K
+}
+
+// StochasticStream is the stochastic oscillator of candles. The raw %K over kPeriod candles is smoothed
+// by a simple moving average of smooth values, 1 for the fast oscillator, and %D averages dPeriod %K values.
+type StochasticStream struct {
+	highs, lows *window
+	k, d        *SMAStream
+	value       StochasticValue
+}
+
+// NewStochasticStream creates a stochastic oscillator, commonly with periods 14, 3 and 3.
+func NewStochasticStream(kPeriod, smooth, dPeriod int) *StochasticStream {
+	return &StochasticStream{
+		highs: newWindow(kPeriod),
+		lows:  newWindow(kPeriod),
+		k:     NewSMAStream(smooth),
+		d:     NewSMAStream(dPeriod),
+		value: StochasticValue{K: nan, D: nan},
+	}
+}
+
+// Update adds a candle and returns the oscillator, NaN until it is ready. A flat range puts the raw %K at 50.
+func (s *StochasticStream) Update(c model.Candle) StochasticValue {
+	s.highs.push(c.High)
+	s.lows.push(c.Low)
+	if !s.highs.full() {
+		return s.value
+	}
+	raw := 50.0
+	if high, low := s.highs.max(), s.lows.min(); high > low {
+		raw = 100 * (c.Close - low) / (high - low)
+	}
+	k := s.k.Update(raw)
+	if !s.k.Ready() {
+		return s.value
+	}
+	s.value = StochasticValue{K: k, D: s.d.Update(k)}
+	return s.value
+}
+
+// Value returns the current oscillator.
+func (s *StochasticStream) Value() StochasticValue { return s.value }
+
+// Ready reports whether %D is ready.
+func (s *StochasticStream) Ready() bool { return s.d.Ready() }
+
+// Stochastic returns the stochastic oscillator of candles.
+func Stochastic(candles []model.Candle, kPeriod, smooth, dPeriod int) []StochasticValue {
+	s := NewStochasticStream(kPeriod, smooth, dPeriod)
+	out := make([]StochasticValue, len(candles))
+	for i, c := range candles {
+		out[i] = s.Update(c)
+	}
+	return out
+}
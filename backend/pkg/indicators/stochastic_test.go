@@ -0,0 +1,49 @@
+package indicators
+
+import (
+	"testing"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+)
+
+func TestStochastic(t *testing.T) {
+	tests := []struct {
+		i    int
+		k, d float64
+	}{
+		{14, nan, nan},
+		{15, 22.475078, nan},
+		{17, 10.371414, 19.004979},
+		{30, 5.60379, 5.081605},
+		{59, 6.149467, 8.397133},
+	}
+	got := Stochastic(referenceCandles(), 14, 3, 3)
+	for _, tt := range tests {
+		if v := got[tt.i]; !equal(v.K, tt.k) || !equal(v.D, tt.d) {
+			t.Errorf("Stochastic[%d] = %+v, want {K:%v D:%v}", tt.i, v, tt.k, tt.d)
+		}
+	}
+}
+
+func TestStochasticStream(t *testing.T) {
+	candles := referenceCandles()
+	batch := Stochastic(candles, 14, 3, 3)
+	s := NewStochasticStream(14, 3, 3)
+	for i, c := range candles {
+		got := s.Update(c)
+		if !equal(got.K, batch[i].K) || !equal(got.D, batch[i].D) || !equal(s.Value().D, got.D) {
+			t.Fatalf("Stochastic stream at %d = %+v, batch %+v", i, got, batch[i])
+		}
+		if s.Ready() != (i >= 17) {
+			t.Fatalf("Stochastic stream Ready at %d = %v", i, s.Ready())
+		}
+	}
+}
+
+func TestStochasticFlatRange(t *testing.T) {
+	flat := model.Candle{High: 10, Low: 10, Close: 10}
+	got := Stochastic([]model.Candle{flat, flat}, 2, 1, 1)
+	if got[1].K != 50 || got[1].D != 50 {
+		t.Errorf("Stochastic of a flat range = %+v, want 50", got[1])
+	}
+}
@@ -0,0 +1,82 @@
+package indicators
+
+import "github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+
+// SuperTrendValue is a value of the SuperTrend.
+type SuperTrendValue struct {
+	Value float64 // The trailing line: below the price in an uptrend, above it in a downtrend
+	Up    bool    // Whether the trend is up
+}
+
+// SuperTrendStream is the SuperTrend of candles: bands a multiple of the average true range above and below
+// the candles' midpoints, which only tighten while the trend lasts. The trend flips when a close crosses the
+// band it trails. It starts up, and is first computed once the average true range is ready.
+type SuperTrendStream struct {
+	atr          *ATRStream
+	multiplier   float64
+	upper, lower float64
+	prevClose    float64
+	value        SuperTrendValue
+	ready        bool
+}
+
+// NewSuperTrendStream creates a SuperTrend over period candles with the given ATR multiplier, commonly 10 and 3.
+func NewSuperTrendStream(period int, multiplier float64) *SuperTrendStream {
+	return &SuperTrendStream{atr: NewATRStream(period), multiplier: multiplier, value: SuperTrendValue{Value: nan}}
+}
+
+// Update adds a candle and returns the SuperTrend, NaN until the average true range is ready.
+func (s *SuperTrendStream) Update(c model.Candle) SuperTrendValue {
+	atr := s.atr.Update(c)
+	defer func() { s.prevClose = c.Close }()
+	if !s.atr.Ready() {
+		return s.value
+	}
+
+	mid := (c.High + c.Low) / 2
+	upper, lower := mid+s.multiplier*atr, mid-s.multiplier*atr
+	if !s.ready {
+		s.upper, s.lower, s.ready = upper, lower, true
+		s.value = SuperTrendValue{Value: lower, Up: true}
+		if c.Close < lower {
+			s.value = SuperTrendValue{Value: upper, Up: false}
+		}
+		return s.value
+	}
+	// A band only moves towards the price, unless the previous close broke through it
+	if upper < s.upper || s.prevClose > s.upper {
+		s.upper = upper
+	}
+	if lower > s.lower || s.prevClose < s.lower {
+		s.lower = lower
+	}
+	up := s.value.Up
+	switch {
+	case up && c.Close < s.lower:
+		up = false
+	case !up && c.Close > s.upper:
+		up = true
+	}
+	if up {
+		s.value = SuperTrendValue{Value: s.lower, Up: true}
+	} else {
+		s.value = SuperTrendValue{Value: s.upper, Up: false}
+	}
+	return s.value
+}
+
+// Value returns the current SuperTrend.
+func (s *SuperTrendStream) Value() SuperTrendValue { return s.value }
+
+// Ready reports whether the SuperTrend is computed.
+func (s *SuperTrendStream) Ready() bool { return s.ready }
+
+// SuperTrend returns the SuperTrend of candles.
+func SuperTrend(candles []model.Candle, period int, multiplier float64) []SuperTrendValue {
+	s := NewSuperTrendStream(period, multiplier)
+	out := make([]SuperTrendValue, len(candles))
+	for i, c := range candles {
+		out[i] = s.Update(c)
+	}
+	return out
+}
@@ -0,0 +1,53 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSuperTrend(t *testing.T) {
+	tests := []struct {
+		i     int
+		value float64
+		up    bool
+	}{
+		{10, 95.114, true},
+		{11, 95.114, true},
+		{25, 96.08015, false},
+		{40, 91.640303, false},
+		{59, 88.808126, false},
+	}
+	got := SuperTrend(referenceCandles(), 10, 3)
+	if !math.IsNaN(got[9].Value) {
+		t.Errorf("SuperTrend[9] = %+v during the warm-up, want NaN", got[9])
+	}
+	for _, tt := range tests {
+		if v := got[tt.i]; !equal(v.Value, tt.value) || v.Up != tt.up {
+			t.Errorf("SuperTrend[%d] = %+v, want {Value:%v Up:%v}", tt.i, v, tt.value, tt.up)
+		}
+	}
+	flips := 0
+	for i := 11; i < len(got); i++ {
+		if got[i].Up != got[i-1].Up {
+			flips++
+		}
+	}
+	if flips != 3 {
+		t.Errorf("SuperTrend flipped %d times, want 3", flips)
+	}
+}
+
+func TestSuperTrendStream(t *testing.T) {
+	candles := referenceCandles()
+	batch := SuperTrend(candles, 10, 3)
+	s := NewSuperTrendStream(10, 3)
+	for i, c := range candles {
+		got := s.Update(c)
+		if !equal(got.Value, batch[i].Value) || got.Up != batch[i].Up || !equal(s.Value().Value, got.Value) {
+			t.Fatalf("SuperTrend stream at %d = %+v, batch %+v", i, got, batch[i])
+		}
+		if s.Ready() == math.IsNaN(got.Value) {
+			t.Fatalf("SuperTrend stream Ready at %d = %v", i, s.Ready())
+		}
+	}
+}
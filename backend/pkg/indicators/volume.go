@@ -0,0 +1,91 @@
+package indicators
+
+import "github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+
+// OBVStream is the on-balance volume of candles: the running total of the volume of candles that closed up,
+// minus that of candles that closed down. It starts at 0 on the first candle.
+type OBVStream struct {
+	value     float64
+	prevClose float64
+	started   bool
+}
+
+// NewOBVStream creates an on-balance volume.
+func NewOBVStream() *OBVStream {
+	return &OBVStream{}
+}
+
+// Update adds a candle and returns the on-balance volume.
+func (s *OBVStream) Update(c model.Candle) float64 {
+	if s.started {
+		switch {
+		case c.Close > s.prevClose:
+			s.value += c.Volume
+		case c.Close < s.prevClose:
+			s.value -= c.Volume
+		}
+	}
+	s.prevClose, s.started = c.Close, true
+	return s.value
+}
+
+// Value returns the current on-balance volume.
+func (s *OBVStream) Value() float64 { return s.value }
+
+// OBV returns the on-balance volume of candles.
+func OBV(candles []model.Candle) []float64 {
+	s := NewOBVStream()
+	out := make([]float64, len(candles))
+	for i, c := range candles {
+		out[i] = s.Update(c)
+	}
+	return out
+}
+
+// VWAPStream is the volume weighted average price of candles since the start or the last Reset, each candle
+// priced at its typical price, the average of its high, low and close. Reset it at the start of every session.
+type VWAPStream struct {
+	volume, priceVolume float64
+	last                float64
+	started             bool
+}
+
+// NewVWAPStream creates a volume weighted average price.
+func NewVWAPStream() *VWAPStream {
+	return &VWAPStream{}
+}
+
+// Update adds a candle and returns the average price. Until volume has traded, it is the typical price of the last candle.
+func (s *VWAPStream) Update(c model.Candle) float64 {
+	typical := (c.High + c.Low + c.Close) / 3
+	s.volume += c.Volume
+	s.priceVolume += typical * c.Volume
+	s.last, s.started = typical, true
+	return s.Value()
+}
+
+// Value returns the current average price, NaN before the first candle.
+func (s *VWAPStream) Value() float64 {
+	switch {
+	case !s.started:
+		return nan
+	case s.volume == 0:
+		return s.last
+	}
+	return s.priceVolume / s.volume
+}
+
+// Reset starts a new session.
+func (s *VWAPStream) Reset() {
+	*s = VWAPStream{}
+}
+
+// VWAP returns the volume weighted average price of candles, as one session.
+func VWAP(candles []model.Candle) []float64 {
+	s := NewVWAPStream()
+	out := make([]float64, len(candles))
+	for i, c := range candles {
+		out[i] = s.Update(c)
+	}
+	return out
+}
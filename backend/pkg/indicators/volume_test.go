@@ -0,0 +1,54 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+)
+
+func TestOBV(t *testing.T) {
+	candles := referenceCandles()
+	batch := OBV(candles)
+	checkGolden(t, "OBV", batch, []golden{{0, 0}, {1, -1663}, {2, -141}, {30, -16723}, {59, -20161}})
+
+	s := NewOBVStream()
+	checkStream(t, "OBV", batch, func(i int) float64 { return s.Update(candles[i]) }, s.Value, nil)
+}
+
+func TestVWAP(t *testing.T) {
+	candles := referenceCandles()
+	batch := VWAP(candles)
+	checkGolden(t, "VWAP", batch, []golden{{0, 99.85}, {1, 99.644631}, {30, 95.489412}, {59, 92.048712}})
+
+	s := NewVWAPStream()
+	if !math.IsNaN(s.Value()) {
+		t.Errorf("VWAP before the first candle = %v, want NaN", s.Value())
+	}
+	checkStream(t, "VWAP", batch, func(i int) float64 { return s.Update(candles[i]) }, s.Value, nil)
+}
+
+func TestVWAPReset(t *testing.T) {
+	candles := referenceCandles()
+	s := NewVWAPStream()
+	for _, c := range candles[:30] {
+		s.Update(c)
+	}
+	s.Reset()
+	if !math.IsNaN(s.Value()) {
+		t.Fatalf("VWAP after Reset = %v, want NaN", s.Value())
+	}
+	session := VWAP(candles[30:])
+	for i, c := range candles[30:] {
+		if got := s.Update(c); !equal(got, session[i]) {
+			t.Fatalf("VWAP after Reset at %d = %v, want %v", i, got, session[i])
+		}
+	}
+}
+
+func TestVWAPWithoutVolume(t *testing.T) {
+	got := VWAP([]model.Candle{{High: 12, Low: 9, Close: 9}})
+	if got[0] != 10 {
+		t.Errorf("VWAP without volume = %v, want the typical price 10", got[0])
+	}
+}
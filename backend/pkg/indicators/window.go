@@ -0,0 +1,64 @@
+// Package indicators implements technical indicators in two forms: batch functions over a whole series,
+// and streams that are updated one value or candle at a time and keep only the state they need.
+//
+// Batch functions return one output per input, aligned with it; outputs during an indicator's warm-up,
+// before it has seen enough data, are NaN. A batch function gives the same values as feeding its stream
+// every input in order. Periods below 1 are taken as 1.
+package indicators
+
+import "math"
+
+// nan is the output of an indicator that is not ready yet
+var nan = math.NaN()
+
+// window holds the last values of a series, up to its size.
+type window struct {
+	values []float64
+	next   int
+	count  int
+}
+
+func newWindow(size int) *window {
+	return &window{values: make([]float64, validPeriod(size))}
+}
+
+// push adds a value, and returns the value it evicted, if the window was full.
+func (w *window) push(v float64) (evicted float64, full bool) {
+	evicted, full = w.values[w.next], w.count == len(w.values)
+	w.values[w.next] = v
+	w.next = (w.next + 1) % len(w.values)
+	if !full {
+		w.count++
+	}
+	return evicted, full
+}
+
+// full reports whether the window holds as many values as its size.
+func (w *window) full() bool {
+	return w.count == len(w.values)
+}
+
+// max returns the largest value in the window.
+func (w *window) max() float64 {
+	m := math.Inf(-1)
+	for i := 0; i < w.count; i++ {
+		m = math.Max(m, w.values[i])
+	}
+	return m
+}
+
+// min returns the smallest value in the window.
+func (w *window) min() float64 {
+	m := math.Inf(1)
+	for i := 0; i < w.count; i++ {
+		m = math.Min(m, w.values[i])
+	}
+	return m
+}
+
+func validPeriod(period int) int {
+	if period < 1 {
+		return 1
+	}
+	return period
+}
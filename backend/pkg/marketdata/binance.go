@@ -0,0 +1,103 @@
+package marketdata
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance/v2"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+)
+
+// BinanceSource streams market data from the public Binance WebSocket streams.
+type BinanceSource struct{}
+
+// NewBinanceSource creates a new Binance WebSocket source.
+func NewBinanceSource() *BinanceSource {
+	return &BinanceSource{}
+}
+
+// Stream opens the Binance stream and passes its events to publish until ctx is cancelled or the connection drops.
+func (s *BinanceSource) Stream(ctx context.Context, stream Stream, publish func(Event)) error {
+	var (
+		streamErr error
+		errMu     sync.Mutex
+	)
+	onError := func(err error) {
+		errMu.Lock()
+		defer errMu.Unlock()
+		streamErr = err
+	}
+
+	var (
+		doneC, stopC chan struct{}
+		err          error
+	)
+	switch stream.Kind {
+	case KindTrade:
+		doneC, stopC, err = binance.WsTradeServe(stream.Symbol, func(e *binance.WsTradeEvent) {
+			publish(Event{Price: parseFloat(e.Price), Time: time.UnixMilli(e.TradeTime)})
+		}, onError)
+	case KindTicker:
+		doneC, stopC, err = binance.WsMarketStatServe(stream.Symbol, func(e *binance.WsMarketStatEvent) {
+			publish(Event{
+				Price: parseFloat(e.LastPrice),
+				Time:  time.UnixMilli(e.Time),
+				Ticker: &Ticker{
+					Open:          parseFloat(e.OpenPrice),
+					High:          parseFloat(e.HighPrice),
+					Low:           parseFloat(e.LowPrice),
+					Volume:        parseFloat(e.BaseVolume),
+					ChangePercent: parseFloat(e.PriceChangePercent),
+				},
+			})
+		}, onError)
+	case KindKline:
+		doneC, stopC, err = binance.WsKlineServe(stream.Symbol, stream.Interval, func(e *binance.WsKlineEvent) {
+			k := e.Kline
+			publish(Event{
+				Price: parseFloat(k.Close),
+				Time:  time.UnixMilli(e.Time),
+				Candle: &model.Candle{
+					Symbol:     stream.Symbol,
+					Interval:   stream.Interval,
+					OpenTime:   time.UnixMilli(k.StartTime),
+					CloseTime:  time.UnixMilli(k.EndTime),
+					Open:       parseFloat(k.Open),
+					High:       parseFloat(k.High),
+					Low:        parseFloat(k.Low),
+					Close:      parseFloat(k.Close),
+					Volume:     parseFloat(k.Volume),
+					TradeCount: k.TradeNum,
+				},
+				Closed: k.IsFinal,
+			})
+		}, onError)
+	default:
+		return fmt.Errorf("unknown stream kind %q", stream.Kind)
+	}
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		close(stopC)
+		<-doneC
+		return nil
+	case <-doneC:
+		errMu.Lock()
+		defer errMu.Unlock()
+		if streamErr == nil {
+			return fmt.Errorf("stream closed")
+		}
+		return streamErr
+	}
+}
+
+func parseFloat(s string) float64 {
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
@@ -0,0 +1,270 @@
+package marketdata
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+)
+
+// Stream kinds
+const (
+	KindTrade  = "trade"  // Every trade, with its price
+	KindTicker = "ticker" // Rolling 24h statistics, about once a second
+	KindKline  = "kline"  // Updates of the current candle of an interval, and its close
+)
+
+// Reconnect backoff of the upstream stream
+const (
+	minBackoff = time.Second
+	maxBackoff = time.Minute
+	// stableAfter is how long a connection must last for the backoff to start again from minBackoff
+	stableAfter = time.Minute
+)
+
+// subscriberBuffer is the number of events buffered per subscriber; a slow subscriber misses events beyond it.
+const subscriberBuffer = 64
+
+// Stream identifies an upstream market data stream.
+type Stream struct {
+	Symbol   string // e.g. BTCUSDT
+	Kind     string // KindTrade, KindTicker or KindKline
+	Interval string // Candle interval of kline streams, e.g. 5m
+}
+
+// String returns the stream name, e.g. btcusdt@kline_5m.
+func (s Stream) String() string {
+	name := strings.ToLower(s.Symbol) + "@" + s.Kind
+	if s.Kind == KindKline {
+		name += "_" + s.Interval
+	}
+	return name
+}
+
+// Validate checks that the stream is one the hub can serve.
+func (s Stream) Validate() error {
+	if s.Symbol == "" {
+		return fmt.Errorf("stream symbol is required")
+	}
+	switch s.Kind {
+	case KindTrade, KindTicker:
+		return nil
+	case KindKline:
+		_, err := model.IntervalDuration(s.Interval)
+		return err
+	}
+	return fmt.Errorf("unknown stream kind %q", s.Kind)
+}
+
+// Ticker holds rolling 24h statistics of a symbol.
+type Ticker struct {
+	Open          float64 `json:"open"`
+	High          float64 `json:"high"`
+	Low           float64 `json:"low"`
+	Volume        float64 `json:"volume"`
+	ChangePercent float64 `json:"change_percent"`
+}
+
+// Event is a market data update of a stream.
+type Event struct {
+	Stream Stream
+	Price  float64       // Last price: the trade price, the ticker's last price or the candle's close
+	Time   time.Time     // Event time
+	Ticker *Ticker       // Set on ticker events
+	Candle *model.Candle // Set on kline events
+	Closed bool          // Whether the candle of a kline event has closed
+}
+
+// Source is an upstream provider of market data.
+type Source interface {
+	// Stream passes the events of a stream to publish until ctx is cancelled or the stream fails.
+	// It returns nil only when ctx is cancelled.
+	Stream(ctx context.Context, stream Stream, publish func(Event)) error
+}
+
+// Subscription receives the events of one stream until it is closed.
+type Subscription struct {
+	C      <-chan Event
+	hub    *Hub
+	stream Stream
+	ch     chan Event
+	once   sync.Once
+}
+
+// Close stops the subscription. The upstream stream is closed when its last subscriber leaves.
+func (s *Subscription) Close() {
+	s.once.Do(func() { s.hub.unsubscribe(s) })
+}
+
+// feed is an upstream stream shared by its subscribers.
+type feed struct {
+	subs   map[*Subscription]bool
+	last   *Event
+	cancel context.CancelFunc
+}
+
+// Hub fans market data out to every interested consumer, such as WebSocket clients, workers and bots.
+// It opens one upstream stream per symbol and kind, counts the subscribers of each, and closes the stream
+// when the last one leaves. A failed stream is reconnected with exponential backoff, and while it is down
+// the fallback source (polling) keeps the subscribers supplied.
+type Hub struct {
+	primary  Source
+	fallback Source
+	feeds    map[Stream]*feed
+	closed   bool
+	mu       sync.Mutex
+}
+
+// NewHub creates a hub streaming from primary, falling back to fallback while primary is down.
+// Either may be nil, but not both.
+func NewHub(primary, fallback Source) *Hub {
+	return &Hub{
+		primary:  primary,
+		fallback: fallback,
+		feeds:    make(map[Stream]*feed),
+	}
+}
+
+// Subscribe subscribes to a stream, opening it upstream if it is the first subscriber.
+// The last event of the stream, if any, is delivered straight away.
+func (h *Hub) Subscribe(stream Stream) (*Subscription, error) {
+	stream.Symbol = strings.ToUpper(stream.Symbol)
+	if err := stream.Validate(); err != nil {
+		return nil, err
+	}
+
+	ch := make(chan Event, subscriberBuffer)
+	sub := &Subscription{C: ch, hub: h, stream: stream, ch: ch}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, fmt.Errorf("market data hub is closed")
+	}
+	f, ok := h.feeds[stream]
+	if !ok {
+		ctx, cancel := context.WithCancel(context.Background())
+		f = &feed{subs: make(map[*Subscription]bool), cancel: cancel}
+		h.feeds[stream] = f
+		go h.run(ctx, stream)
+		log.Printf("Market data: opened %s", stream)
+	}
+	f.subs[sub] = true
+	if f.last != nil {
+		ch <- *f.last
+	}
+	return sub, nil
+}
+
+// Last returns the most recent event of a stream that is open.
+func (h *Hub) Last(stream Stream) (Event, bool) {
+	stream.Symbol = strings.ToUpper(stream.Symbol)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if f, ok := h.feeds[stream]; ok && f.last != nil {
+		return *f.last, true
+	}
+	return Event{}, false
+}
+
+// Subscribers returns the number of subscribers of every open stream.
+func (h *Hub) Subscribers() map[string]int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	counts := make(map[string]int, len(h.feeds))
+	for stream, f := range h.feeds {
+		counts[stream.String()] = len(f.subs)
+	}
+	return counts
+}
+
+// Close closes every stream and subscription.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for stream, f := range h.feeds {
+		f.cancel()
+		for sub := range f.subs {
+			close(sub.ch)
+		}
+		delete(h.feeds, stream)
+	}
+}
+
+func (h *Hub) unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	f, ok := h.feeds[sub.stream]
+	if !ok || !f.subs[sub] {
+		return
+	}
+	delete(f.subs, sub)
+	close(sub.ch)
+	if len(f.subs) == 0 {
+		f.cancel()
+		delete(h.feeds, sub.stream)
+		log.Printf("Market data: closed %s", sub.stream)
+	}
+}
+
+// publish delivers an event to the subscribers of its stream, dropping it for subscribers whose buffer is full.
+func (h *Hub) publish(e Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	f, ok := h.feeds[e.Stream]
+	if !ok {
+		return
+	}
+	f.last = &e
+	for sub := range f.subs {
+		select {
+		case sub.ch <- e:
+		default:
+		}
+	}
+}
+
+// run keeps a stream supplied until ctx is cancelled: from the primary source while it is up,
+// and from the fallback source while waiting to reconnect.
+func (h *Hub) run(ctx context.Context, stream Stream) {
+	publish := func(e Event) {
+		e.Stream = stream
+		h.publish(e)
+	}
+	if h.primary == nil {
+		if err := h.fallback.Stream(ctx, stream, publish); err != nil {
+			log.Printf("Market data: %s fallback stopped: %v", stream, err)
+		}
+		return
+	}
+
+	backoff := minBackoff
+	for ctx.Err() == nil {
+		started := time.Now()
+		err := h.primary.Stream(ctx, stream, publish)
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(started) > stableAfter {
+			backoff = minBackoff
+		}
+		log.Printf("Market data: %s disconnected, reconnecting in %s: %v", stream, backoff, err)
+
+		wait, cancel := context.WithTimeout(ctx, backoff)
+		if h.fallback != nil {
+			if err := h.fallback.Stream(wait, stream, publish); err != nil {
+				log.Printf("Market data: %s fallback failed: %v", stream, err)
+				<-wait.Done()
+			}
+		} else {
+			<-wait.Done()
+		}
+		cancel()
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
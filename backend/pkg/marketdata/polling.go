@@ -0,0 +1,92 @@
+package marketdata
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+)
+
+// Polling intervals of the fallback source
+const (
+	pricePollInterval = 2 * time.Second
+	klinePollInterval = 5 * time.Second
+)
+
+// PriceFetcher returns the current price of a symbol; any exchange.Exchange satisfies it.
+type PriceFetcher interface {
+	GetPrice(ctx context.Context, symbol string) (float64, error)
+}
+
+// CandleFetcher returns the latest candles of a symbol, oldest first; the FetcherService satisfies it.
+type CandleFetcher interface {
+	FetchCandles(symbol, interval string, limit int) ([]model.Candle, error)
+}
+
+// PollingSource supplies streams by polling REST endpoints. It is the hub's fallback while the
+// WebSocket stream is down. Ticker events only carry the last price.
+type PollingSource struct {
+	prices  PriceFetcher
+	candles CandleFetcher
+}
+
+// NewPollingSource creates a polling source reading prices and candles from the given fetchers.
+func NewPollingSource(prices PriceFetcher, candles CandleFetcher) *PollingSource {
+	return &PollingSource{prices: prices, candles: candles}
+}
+
+// Stream polls the stream's data and passes it to publish until ctx is cancelled.
+// Poll errors are logged and retried, so it only returns when ctx is cancelled.
+func (s *PollingSource) Stream(ctx context.Context, stream Stream, publish func(Event)) error {
+	interval := pricePollInterval
+	poll := func() error {
+		price, err := s.prices.GetPrice(ctx, stream.Symbol)
+		if err != nil {
+			return err
+		}
+		publish(Event{Price: price, Time: time.Now()})
+		return nil
+	}
+	switch stream.Kind {
+	case KindTrade, KindTicker:
+	case KindKline:
+		interval = klinePollInterval
+		var lastClosed time.Time
+		poll = func() error {
+			candles, err := s.candles.FetchCandles(stream.Symbol, stream.Interval, 2)
+			if err != nil {
+				return err
+			}
+			now := time.Now()
+			for i := range candles {
+				c := candles[i]
+				closed := c.CloseTime.Before(now)
+				if closed && !c.OpenTime.After(lastClosed) {
+					continue
+				}
+				if closed {
+					lastClosed = c.OpenTime
+				}
+				publish(Event{Price: c.Close, Time: now, Candle: &c, Closed: closed})
+			}
+			return nil
+		}
+	default:
+		return fmt.Errorf("unknown stream kind %q", stream.Kind)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := poll(); err != nil && ctx.Err() == nil {
+			log.Printf("Market data: error polling %s: %v", stream, err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
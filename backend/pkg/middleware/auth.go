@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
@@ -27,21 +28,55 @@ func JWTMiddleware(secret string) fiber.Handler {
 			return c.Status(401).JSON(fiber.Map{"error": "Invalid authorization header format"})
 		}
 
-		token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-			return []byte(secret), nil
-		})
-
+		claims, err := ParseToken(secret, tokenString)
 		if err != nil {
 			return c.Status(401).JSON(fiber.Map{"error": "Invalid token"})
 		}
 
-		if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
-			c.Locals("user_id", claims.UserID)
-			c.Locals("username", claims.Username)
+		c.Locals("user_id", claims.UserID)
+		c.Locals("username", claims.Username)
+		return c.Next()
+	}
+}
+
+// OptionalJWTMiddleware identifies the user of public routes that do more for a logged in user.
+// Requests without an authorization header pass through anonymously; an invalid token is still rejected.
+func OptionalJWTMiddleware(secret string) fiber.Handler {
+	required := JWTMiddleware(secret)
+	return func(c *fiber.Ctx) error {
+		if c.Get("Authorization") == "" {
 			return c.Next()
 		}
+		return required(c)
+	}
+}
 
-		return c.Status(401).JSON(fiber.Map{"error": "Invalid token claims"})
+// ParseToken validates a JWT signed with secret and returns its claims
+func ParseToken(secret, tokenString string) (*JWTClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	claims, ok := token.Claims.(*JWTClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token claims")
+	}
+	return claims, nil
+}
+
+// AdminMiddleware allows only the listed usernames through. It must run after JWTMiddleware.
+func AdminMiddleware(usernames []string) fiber.Handler {
+	admins := make(map[string]bool, len(usernames))
+	for _, name := range usernames {
+		admins[name] = true
+	}
+	return func(c *fiber.Ctx) error {
+		if username, ok := c.Locals("username").(string); ok && admins[username] {
+			return c.Next()
+		}
+		return c.Status(403).JSON(fiber.Map{"error": "Admin access required"})
 	}
 }
 
@@ -0,0 +1,146 @@
+// Package ml trains forecasting models on candles in pure Go, and validates them walk-forward.
+//
+// Models forecast whether the close rises over the next horizon candles, from a vector of indicator features
+// of the latest candle. Features are scaled to be comparable across prices, so a model trained on one symbol
+// can be read the same way on another, although models are trained and used per symbol and interval.
+package ml
+
+import (
+	"math"
+	"time"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/indicators"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+)
+
+// FeatureNames name the features, in the order of the feature vectors. Models record the names they were
+// trained with, so a model from before a change of features is recognised and not used.
+var FeatureNames = []string{
+	"rsi",            // RSI(14), centred on 0
+	"macd_histogram", // MACD(12, 26, 9) histogram, as a percentage of the close
+	"bollinger_b",    // Position of the close in the Bollinger bands (20, 2), centred on 0
+	"ema_spread",     // EMA(9) above EMA(21), in percent
+	"atr",            // ATR(14), as a percentage of the close
+	"adx",            // ADX(14), from 0 to 1
+	"di_spread",      // +DI above -DI, from -1 to 1
+	"stochastic_k",   // Stochastic %K (14, 3), centred on 0
+	"return_1",       // Change of the close over the last candle, in percent
+	"return_5",       // Change of the close over the last 5 candles, in percent
+	"return_10",      // Change of the close over the last 10 candles, in percent
+	"volume_ratio",   // Log of the volume over its 20-candle average
+}
+
+// Lookback is the number of candles before the first complete feature vector, set by the MACD signal line.
+const Lookback = 34
+
+// Features returns the feature vector of every candle, sorted oldest to newest, aligned with them.
+// The vectors of candles without enough history, or with an undefined feature, are nil.
+func Features(candles []model.Candle) [][]float64 {
+	closes := model.ClosePrices(candles)
+	volumes := make([]float64, len(candles))
+	for i, c := range candles {
+		volumes[i] = c.Volume
+	}
+	rsi := indicators.RSI(closes, 14)
+	macd := indicators.MACD(closes, 12, 26, 9)
+	bands := indicators.Bollinger(closes, 20, 2)
+	fast, slow := indicators.EMA(closes, 9), indicators.EMA(closes, 21)
+	atr := indicators.ATR(candles, 14)
+	adx := indicators.ADX(candles, 14)
+	stochastic := indicators.Stochastic(candles, 14, 3, 3)
+	averageVolume := indicators.SMA(volumes, 20)
+
+	rows := make([][]float64, len(candles))
+	for i, c := range candles {
+		if i < Lookback-1 || c.Close <= 0 {
+			continue
+		}
+		row := []float64{
+			rsi[i]/100 - 0.5,
+			100 * macd[i].Histogram / c.Close,
+			bandPosition(c.Close, bands[i]),
+			100 * (fast[i]/slow[i] - 1),
+			100 * atr[i] / c.Close,
+			adx[i].ADX / 100,
+			(adx[i].PlusDI - adx[i].MinusDI) / 100,
+			stochastic[i].K/100 - 0.5,
+			change(closes, i, 1),
+			change(closes, i, 5),
+			change(closes, i, 10),
+			volumeRatio(c.Volume, averageVolume[i]),
+		}
+		if complete(row) {
+			rows[i] = row
+		}
+	}
+	return rows
+}
+
+// Dataset is a set of feature vectors, each labelled 1 if the close rose over the following horizon candles and 0 otherwise.
+type Dataset struct {
+	X     [][]float64
+	Y     []float64
+	Times []time.Time // Open time of each vector's candle
+}
+
+// NewDataset labels the complete feature vectors of candles, sorted oldest to newest, that have horizon candles after them.
+func NewDataset(candles []model.Candle, horizon int) Dataset {
+	var ds Dataset
+	for i, row := range Features(candles) {
+		if row == nil || i+horizon >= len(candles) {
+			continue
+		}
+		label := 0.0
+		if candles[i+horizon].Close > candles[i].Close {
+			label = 1
+		}
+		ds.X = append(ds.X, row)
+		ds.Y = append(ds.Y, label)
+		ds.Times = append(ds.Times, candles[i].OpenTime)
+	}
+	return ds
+}
+
+// Len returns the number of feature vectors.
+func (ds Dataset) Len() int { return len(ds.X) }
+
+// slice returns the vectors from i to j.
+func (ds Dataset) slice(i, j int) Dataset {
+	return Dataset{X: ds.X[i:j], Y: ds.Y[i:j], Times: ds.Times[i:j]}
+}
+
+func bandPosition(price float64, band indicators.BollingerValue) float64 {
+	width := band.Upper - band.Lower
+	if width == 0 {
+		return 0
+	}
+	// NaN bands during the warm-up leave the feature NaN
+	return (price-band.Lower)/width - 0.5
+}
+
+func change(closes []float64, i, n int) float64 {
+	if i < n || closes[i-n] <= 0 {
+		return math.NaN()
+	}
+	return 100 * (closes[i]/closes[i-n] - 1)
+}
+
+func volumeRatio(volume, average float64) float64 {
+	if math.IsNaN(average) {
+		return average
+	}
+	// Without volume there is nothing to compare
+	if volume <= 0 || average <= 0 {
+		return 0
+	}
+	return math.Log(volume / average)
+}
+
+func complete(row []float64) bool {
+	for _, v := range row {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return false
+		}
+	}
+	return true
+}
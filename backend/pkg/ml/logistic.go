@@ -0,0 +1,130 @@
+package ml
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+)
+
+// TrainConfig configures the training of a logistic regression.
+type TrainConfig struct {
+	Iterations   int     // Full-batch gradient descent steps
+	LearningRate float64 // Step size on the standardised features
+	L2           float64 // Weight decay, keeping the model from leaning on noise
+}
+
+// DefaultTrainConfig returns the training configuration of the API and the trainmodel command.
+func DefaultTrainConfig() TrainConfig {
+	return TrainConfig{Iterations: 500, LearningRate: 0.5, L2: 0.01}
+}
+
+// Logistic is a logistic regression on standardised features: each feature has its training mean subtracted
+// and is divided by its training standard deviation before being weighed.
+type Logistic struct {
+	Features []string  `json:"features"`
+	Mean     []float64 `json:"mean"`
+	Scale    []float64 `json:"scale"`
+	Weights  []float64 `json:"weights"`
+	Bias     float64   `json:"bias"`
+}
+
+// ErrNoData is returned when there is nothing to train on.
+var ErrNoData = errors.New("no complete feature vectors to train on")
+
+// TrainLogistic fits a logistic regression to a dataset by gradient descent on the L2-regularised log loss.
+func TrainLogistic(ds Dataset, cfg TrainConfig) (*Logistic, error) {
+	n := ds.Len()
+	if n == 0 {
+		return nil, ErrNoData
+	}
+	features := len(ds.X[0])
+	m := &Logistic{
+		Features: slices.Clone(FeatureNames),
+		Mean:     make([]float64, features),
+		Scale:    make([]float64, features),
+		Weights:  make([]float64, features),
+	}
+	for _, x := range ds.X {
+		for j, v := range x {
+			m.Mean[j] += v / float64(n)
+		}
+	}
+	for _, x := range ds.X {
+		for j, v := range x {
+			m.Scale[j] += (v - m.Mean[j]) * (v - m.Mean[j]) / float64(n)
+		}
+	}
+	for j := range m.Scale {
+		// A constant feature carries no information; leaving its scale at 1 keeps it at 0
+		if m.Scale[j] = math.Sqrt(m.Scale[j]); m.Scale[j] == 0 {
+			m.Scale[j] = 1
+		}
+	}
+
+	standardised := make([][]float64, n)
+	for i, x := range ds.X {
+		standardised[i] = m.standardise(x)
+	}
+	gradient := make([]float64, features)
+	for it := 0; it < cfg.Iterations; it++ {
+		clear(gradient)
+		biasGradient := 0.0
+		for i, x := range standardised {
+			residual := m.probability(x) - ds.Y[i]
+			for j, v := range x {
+				gradient[j] += residual * v
+			}
+			biasGradient += residual
+		}
+		for j := range m.Weights {
+			m.Weights[j] -= cfg.LearningRate * (gradient[j]/float64(n) + cfg.L2*m.Weights[j])
+		}
+		m.Bias -= cfg.LearningRate * biasGradient / float64(n)
+	}
+	return m, nil
+}
+
+// Check returns an error if the model was trained on other features than the current ones.
+func (m *Logistic) Check() error {
+	if !slices.Equal(m.Features, FeatureNames) || len(m.Weights) != len(FeatureNames) || len(m.Mean) != len(FeatureNames) || len(m.Scale) != len(FeatureNames) {
+		return fmt.Errorf("the model was trained on features %v, not the current %v; train a new version", m.Features, FeatureNames)
+	}
+	return nil
+}
+
+// Predict returns the probability of a rise given a feature vector.
+func (m *Logistic) Predict(x []float64) float64 {
+	return m.probability(m.standardise(x))
+}
+
+// Forecast returns the probability that the close rises over the model's horizon after the last of candles,
+// sorted oldest to newest; at least Lookback candles are needed.
+func (m *Logistic) Forecast(candles []model.Candle) (float64, error) {
+	if err := m.Check(); err != nil {
+		return 0, err
+	}
+	rows := Features(candles)
+	if len(rows) == 0 || rows[len(rows)-1] == nil {
+		return 0, fmt.Errorf("the features of the last candle are incomplete; %d candles are needed", Lookback)
+	}
+	return m.Predict(rows[len(rows)-1]), nil
+}
+
+func (m *Logistic) standardise(x []float64) []float64 {
+	z := make([]float64, len(x))
+	for j, v := range x {
+		z[j] = (v - m.Mean[j]) / m.Scale[j]
+	}
+	return z
+}
+
+func (m *Logistic) probability(z []float64) float64 {
+	logit := m.Bias
+	for j, v := range z {
+		logit += m.Weights[j] * v
+	}
+	return 1 / (1 + math.Exp(-logit))
+}
@@ -0,0 +1,69 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+)
+
+// minTrainSamples is the fewest feature vectors a fold is trained on.
+const minTrainSamples = 50
+
+// WalkForward validates training on a dataset, sorted oldest to newest, the way a model is used: the dataset is cut
+// into folds+1 windows, and each fold is trained on all the windows before its test window and tested on it. The
+// horizon vectors before a test window are left out of training, as their labels look into the test window.
+func WalkForward(ds Dataset, folds, horizon int, cfg TrainConfig) (*model.MLValidation, error) {
+	if folds < 1 {
+		return nil, fmt.Errorf("at least one fold is needed")
+	}
+	size := ds.Len() / (folds + 1)
+	if size == 0 || size-horizon < minTrainSamples {
+		return nil, fmt.Errorf("%d feature vectors are too few for %d folds with a horizon of %d", ds.Len(), folds, horizon)
+	}
+
+	validation := &model.MLValidation{}
+	tested := 0
+	for k := 1; k <= folds; k++ {
+		testStart, testEnd := k*size, (k+1)*size
+		if k == folds {
+			testEnd = ds.Len()
+		}
+		m, err := TrainLogistic(ds.slice(0, testStart-horizon), cfg)
+		if err != nil {
+			return nil, err
+		}
+		fold := evaluate(m, ds.slice(testStart, testEnd))
+		fold.TrainSamples = testStart - horizon
+		validation.Folds = append(validation.Folds, fold)
+
+		w := float64(fold.TestSamples)
+		validation.Accuracy += fold.Accuracy * w
+		validation.LogLoss += fold.LogLoss * w
+		validation.Brier += fold.Brier * w
+		validation.BaseRate += fold.BaseRate * w
+		tested += fold.TestSamples
+	}
+	n := float64(tested)
+	validation.Accuracy, validation.LogLoss, validation.Brier, validation.BaseRate = validation.Accuracy/n, validation.LogLoss/n, validation.Brier/n, validation.BaseRate/n
+	return validation, nil
+}
+
+// evaluate measures a model's forecasts of a test window.
+func evaluate(m *Logistic, test Dataset) model.MLFold {
+	fold := model.MLFold{TestSamples: test.Len(), TestFrom: test.Times[0], TestTo: test.Times[test.Len()-1]}
+	for i, x := range test.X {
+		p, y := m.Predict(x), test.Y[i]
+		if (p >= 0.5) == (y == 1) {
+			fold.Accuracy++
+		}
+		// Probabilities are kept off 0 and 1 so a confident miss costs a large but finite loss
+		clipped := math.Min(math.Max(p, 1e-15), 1-1e-15)
+		fold.LogLoss -= y*math.Log(clipped) + (1-y)*math.Log(1-clipped)
+		fold.Brier += (p - y) * (p - y)
+		fold.BaseRate += y
+	}
+	n := float64(fold.TestSamples)
+	fold.Accuracy, fold.LogLoss, fold.Brier, fold.BaseRate = fold.Accuracy/n, fold.LogLoss/n, fold.Brier/n, fold.BaseRate/n
+	return fold
+}
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// AutoTradeSubscription opts a user in to automatic execution of the worker's signals for a symbol
+type AutoTradeSubscription struct {
+	ID          int       `json:"id" db:"id"`
+	UserID      int       `json:"user_id" db:"user_id"`
+	Symbol      string    `json:"symbol" db:"symbol"`
+	Exchange    string    `json:"exchange" db:"exchange"`
+	RiskPercent float64   `json:"risk_percent" db:"risk_percent"` // Percentage of the quote balance risked per trade
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	// ExitRules are copied to every trade opened for the subscription
+	ExitRules *ExitRules `json:"exit_rules,omitempty" db:"exit_rules"`
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Bot statuses
+const (
+	BotRunning = "RUNNING"
+	BotStopped = "STOPPED"
+)
+
+// Bot describes a strategy trading a symbol on behalf of a user
+type Bot struct {
+	ID        int             `json:"id" db:"id"`
+	UserID    int             `json:"user_id" db:"user_id"`
+	Strategy  string          `json:"strategy" db:"strategy"`
+	Symbol    string          `json:"symbol" db:"symbol"`
+	Exchange  string          `json:"exchange" db:"exchange"`
+	Interval  string          `json:"interval" db:"interval"` // Candle interval passed to the strategy's OnCandle
+	Params    json.RawMessage `json:"params,omitempty" db:"params"`
+	State     json.RawMessage `json:"state,omitempty" db:"state"` // Strategy state, for strategies that keep any
+	Status    string          `json:"status" db:"status"`         // RUNNING or STOPPED
+	StartedAt time.Time       `json:"started_at" db:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at" db:"updated_at"`
+}
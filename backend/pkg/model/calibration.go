@@ -0,0 +1,70 @@
+package model
+
+import "time"
+
+// CalibrationPoint is a step of a calibration: predictions of this raw confidence hit their take profit with this probability.
+type CalibrationPoint struct {
+	Confidence  float64 `json:"confidence"`
+	Probability float64 `json:"probability"`
+	Signals     int     `json:"signals"` // Evaluated signals the point was fitted on
+}
+
+// Calibration maps the raw confidence of predictions to the probability that their signal hits its take profit,
+// as fitted on the outcomes of past signals. An empty symbol or timeframe means it was fitted across all of them.
+type Calibration struct {
+	Symbol    string             `json:"symbol"`
+	Timeframe string             `json:"timeframe"`
+	Method    string             `json:"method"`
+	Signals   int                `json:"signals"`
+	Points    []CalibrationPoint `json:"points"` // Sorted by confidence, with non-decreasing probabilities
+	FittedAt  time.Time          `json:"fitted_at"`
+}
+
+// Probability returns the calibrated probability of a raw confidence, interpolated between the points of the
+// calibration and flat beyond its first and last points.
+func (c *Calibration) Probability(confidence float64) float64 {
+	points := c.Points
+	if len(points) == 0 {
+		return confidence
+	}
+	if confidence <= points[0].Confidence {
+		return points[0].Probability
+	}
+	for i := 1; i < len(points); i++ {
+		if confidence <= points[i].Confidence {
+			a, b := points[i-1], points[i]
+			return a.Probability + (b.Probability-a.Probability)*(confidence-a.Confidence)/(b.Confidence-a.Confidence)
+		}
+	}
+	return points[len(points)-1].Probability
+}
+
+// ReliabilityBucket compares the raw and calibrated confidence of the signals in a confidence range with how often they hit.
+type ReliabilityBucket struct {
+	Lower           float64 `json:"lower"`
+	Upper           float64 `json:"upper"`
+	Signals         int     `json:"signals"`
+	MeanConfidence  float64 `json:"mean_confidence"`  // Average raw confidence
+	MeanProbability float64 `json:"mean_probability"` // Average calibrated probability
+	HitRate         float64 `json:"hit_rate"`         // Share of the signals that hit their take profit
+}
+
+// ReliabilityReport is the reliability diagram of the evaluated signals of a symbol and timeframe, with the Brier score,
+// the mean squared error of the predicted against the realised outcomes, of the raw and the calibrated confidence.
+type ReliabilityReport struct {
+	Symbol          string              `json:"symbol"`
+	Timeframe       string              `json:"timeframe"`
+	Signals         int                 `json:"signals"`
+	Buckets         []ReliabilityBucket `json:"buckets"`
+	BrierRaw        float64             `json:"brier_raw"`
+	BrierCalibrated float64             `json:"brier_calibrated"`
+	Calibration     *Calibration        `json:"calibration,omitempty"`
+}
+
+// CalibrationSample is the raw confidence of an evaluated signal and whether it hit its take profit.
+type CalibrationSample struct {
+	Symbol     string
+	Timeframe  string
+	Confidence float64
+	Hit        bool
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// Candle is a single OHLCV kline for a symbol and interval.
+type Candle struct {
+	Symbol     string    `json:"symbol" db:"symbol"`
+	Interval   string    `json:"interval" db:"interval"`
+	OpenTime   time.Time `json:"open_time" db:"open_time"`
+	CloseTime  time.Time `json:"close_time" db:"close_time"`
+	Open       float64   `json:"open" db:"open"`
+	High       float64   `json:"high" db:"high"`
+	Low        float64   `json:"low" db:"low"`
+	Close      float64   `json:"close" db:"close"`
+	Volume     float64   `json:"volume" db:"volume"`
+	TradeCount int64     `json:"trade_count" db:"trade_count"`
+}
+
+// ClosePrices returns the closing prices of candles in the same order.
+func ClosePrices(candles []Candle) []float64 {
+	prices := make([]float64, len(candles))
+	for i, c := range candles {
+		prices[i] = c.Close
+	}
+	return prices
+}
+
+// ToForexData adapts chronological candles to the close-only ForexData format,
+// sorted newest to oldest and stamped with each candle's closing time.
+func ToForexData(candles []Candle) []ForexData {
+	data := make([]ForexData, len(candles))
+	for i, c := range candles {
+		data[len(candles)-1-i] = ForexData{
+			Timestamp: c.CloseTime.Format("2006-01-02 15:04:05"),
+			Price:     c.Close,
+		}
+	}
+	return data
+}
+
+// intervalDurations maps Binance kline intervals to their length.
+var intervalDurations = map[string]time.Duration{
+	"1m":  time.Minute,
+	"3m":  3 * time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"30m": 30 * time.Minute,
+	"1h":  time.Hour,
+	"2h":  2 * time.Hour,
+	"4h":  4 * time.Hour,
+	"6h":  6 * time.Hour,
+	"8h":  8 * time.Hour,
+	"12h": 12 * time.Hour,
+	"1d":  24 * time.Hour,
+	"3d":  72 * time.Hour,
+	"1w":  7 * 24 * time.Hour,
+}
+
+// IntervalDuration returns the length of a Binance kline interval such as "5m" or "1d".
+func IntervalDuration(interval string) (time.Duration, error) {
+	d, ok := intervalDurations[interval]
+	if !ok {
+		return 0, fmt.Errorf("unsupported interval %q", interval)
+	}
+	return d, nil
+}
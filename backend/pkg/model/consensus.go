@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// Trend directions of a timeframe
+const (
+	TrendUp   = "up"
+	TrendDown = "down"
+	TrendFlat = "flat"
+)
+
+// TimeframeSignal is the latest prediction of one of a symbol's timeframes, as weighed in its consensus.
+type TimeframeSignal struct {
+	Timeframe  string     `json:"timeframe"`
+	Weight     float64    `json:"weight"`
+	Prediction Prediction `json:"prediction"`
+	Trend      string     `json:"trend"`           // Direction of the timeframe's moving averages: "up", "down" or "flat"
+	Stale      bool       `json:"stale,omitempty"` // Too old to count towards the consensus
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// Consensus combines the latest predictions of a symbol's timeframes into one signal.
+type Consensus struct {
+	Pair       string  `json:"pair"`
+	Signal     string  `json:"signal"` // "buy", "sell", "hold"
+	Confidence float64 `json:"confidence"`
+	// Score is the weighted vote of the timeframes, from -1 (sell) to 1 (buy)
+	Score float64 `json:"score"`
+	// Trend is the direction of TrendTimeframe, which a buy or sell must agree with
+	TrendTimeframe string            `json:"trend_timeframe,omitempty"`
+	Trend          string            `json:"trend,omitempty"`
+	Reason         string            `json:"reason"`
+	Components     []TimeframeSignal `json:"components"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
@@ -0,0 +1,183 @@
+package model
+
+import (
+	"fmt"
+	"math"
+)
+
+// Defaults of the ATR trailing stop
+const (
+	DefaultATRInterval = "1h"
+	DefaultATRPeriod   = 14
+)
+
+// ExitRules manage the exit of an open trade beyond its fixed take profit and stop loss.
+// They are stored with the trade together with the state they have reached, and move the trade's
+// StopLoss as the price moves in its favour; the stop never moves back.
+type ExitRules struct {
+	// TrailingStopPercent trails the stop this percentage behind the best price since entry
+	TrailingStopPercent float64 `json:"trailing_stop_percent,omitempty"`
+	// TrailingStopATR trails the stop this many average true ranges behind the best price since entry
+	TrailingStopATR float64 `json:"trailing_stop_atr,omitempty"`
+	ATRInterval     string  `json:"atr_interval,omitempty"` // Candle interval of the ATR, 1h by default
+	ATRPeriod       int     `json:"atr_period,omitempty"`   // Candles averaged by the ATR, 14 by default
+	// BreakEvenPercent moves the stop to the entry price once the trade has gained this percentage
+	BreakEvenPercent float64 `json:"break_even_percent,omitempty"`
+	// TakeProfits close parts of the position as the price reaches each level. With a ladder, the trade's
+	// own take profit is not used, and whatever the ladder leaves open exits by its stop.
+	TakeProfits []TakeProfitLevel `json:"take_profits,omitempty"`
+
+	// State reached by the rules
+	ATR             float64 `json:"atr,omitempty"`              // Average true range when the trade opened
+	InitialQuantity float64 `json:"initial_quantity,omitempty"` // Quantity the trade opened with; ladder percentages are of it
+	BestPrice       float64 `json:"best_price,omitempty"`       // Highest price since entry for longs, lowest for shorts
+	StopReason      string  `json:"stop_reason,omitempty"`      // What last moved the stop: break_even or trailing_stop
+	RealizedPnL     float64 `json:"realized_pnl,omitempty"`     // Gross profit and loss of the parts already closed
+}
+
+// TakeProfitLevel is a rung of a take profit ladder.
+type TakeProfitLevel struct {
+	Price       float64 `json:"price,omitempty"`        // Price of the level
+	GainPercent float64 `json:"gain_percent,omitempty"` // Or its distance from the entry price in the trade's favour
+	Percent     float64 `json:"percent"`                // Percentage of the opening quantity closed at the level
+	Done        bool    `json:"done,omitempty"`
+}
+
+// Copy returns a copy of the rules that shares no state with them.
+func (r *ExitRules) Copy() *ExitRules {
+	c := *r
+	c.TakeProfits = append([]TakeProfitLevel(nil), r.TakeProfits...)
+	return &c
+}
+
+// Validate checks that the rules are usable.
+func (r *ExitRules) Validate() error {
+	if r.TrailingStopPercent < 0 || r.TrailingStopPercent >= 100 {
+		return fmt.Errorf("trailing_stop_percent must be between 0 and 100")
+	}
+	if r.TrailingStopATR < 0 {
+		return fmt.Errorf("trailing_stop_atr must not be negative")
+	}
+	if r.TrailingStopATR > 0 {
+		if r.ATRInterval != "" {
+			if _, err := IntervalDuration(r.ATRInterval); err != nil {
+				return err
+			}
+		}
+		if r.ATRPeriod < 0 {
+			return fmt.Errorf("atr_period must not be negative")
+		}
+	}
+	if r.BreakEvenPercent < 0 {
+		return fmt.Errorf("break_even_percent must not be negative")
+	}
+	total := 0.0
+	for i, level := range r.TakeProfits {
+		if level.Percent <= 0 {
+			return fmt.Errorf("take profit %d: percent must be positive", i+1)
+		}
+		if (level.Price > 0) == (level.GainPercent > 0) {
+			return fmt.Errorf("take profit %d: set either price or gain_percent", i+1)
+		}
+		if level.Price < 0 || level.GainPercent < 0 {
+			return fmt.Errorf("take profit %d: price and gain_percent must not be negative", i+1)
+		}
+		total += level.Percent
+	}
+	if total > 100+1e-9 {
+		return fmt.Errorf("take profits close %.2f%% of the position, more than 100%%", total)
+	}
+	return nil
+}
+
+// Start prepares the rules for a trade opened at entry: it resolves the ladder's gain percentages to prices
+// and records the opening quantity. Levels on the wrong side of the entry are rejected.
+func (r *ExitRules) Start(side string, entry, quantity float64) error {
+	long := side == "BUY"
+	for i := range r.TakeProfits {
+		level := &r.TakeProfits[i]
+		if level.GainPercent > 0 {
+			level.Price = entry * (1 + level.GainPercent/100)
+			if !long {
+				level.Price = entry * (1 - level.GainPercent/100)
+			}
+			level.GainPercent = 0
+		}
+		if (long && level.Price <= entry) || (!long && level.Price >= entry) {
+			return fmt.Errorf("take profit %d at %g is not beyond the entry price %g", i+1, level.Price, entry)
+		}
+	}
+	if r.InitialQuantity == 0 {
+		r.InitialQuantity = quantity
+	}
+	if r.BestPrice == 0 {
+		r.BestPrice = entry
+	}
+	return nil
+}
+
+// ATRSettings returns the interval and period of the ATR trailing stop, with their defaults applied.
+func (r *ExitRules) ATRSettings() (string, int) {
+	interval, period := r.ATRInterval, r.ATRPeriod
+	if interval == "" {
+		interval = DefaultATRInterval
+	}
+	if period <= 0 {
+		period = DefaultATRPeriod
+	}
+	return interval, period
+}
+
+// Advance records price as the best price if the trade has moved further in its favour, and returns the stop
+// the rules ask for: the tighter of the break-even and trailing stops. It returns 0 when no rule sets a stop.
+func (r *ExitRules) Advance(side string, entry, price float64) (stop float64, reason string) {
+	long := side == "BUY"
+	if r.BestPrice == 0 || (long && price > r.BestPrice) || (!long && price < r.BestPrice) {
+		r.BestPrice = price
+	}
+	// gain is the best move in the trade's favour, as a fraction of the entry price
+	gain := (r.BestPrice - entry) / entry
+	if !long {
+		gain = -gain
+	}
+	tighter := func(candidate float64, why string) {
+		if candidate <= 0 {
+			return
+		}
+		if stop == 0 || (long && candidate > stop) || (!long && candidate < stop) {
+			stop, reason = candidate, why
+		}
+	}
+
+	if r.BreakEvenPercent > 0 && gain*100 >= r.BreakEvenPercent {
+		tighter(entry, "break_even")
+	}
+	distance := 0.0
+	if r.TrailingStopPercent > 0 {
+		distance = r.BestPrice * r.TrailingStopPercent / 100
+	}
+	if r.TrailingStopATR > 0 && r.ATR > 0 {
+		distance = math.Max(distance, r.TrailingStopATR*r.ATR)
+	}
+	if distance > 0 {
+		if long {
+			tighter(r.BestPrice-distance, "trailing_stop")
+		} else {
+			tighter(r.BestPrice+distance, "trailing_stop")
+		}
+	}
+	return stop, reason
+}
+
+// NextTakeProfit returns the index of the first ladder level that price has reached and that is not done yet, or -1.
+func (r *ExitRules) NextTakeProfit(side string, price float64) int {
+	for i, level := range r.TakeProfits {
+		if level.Done {
+			continue
+		}
+		if (side == "BUY" && price >= level.Price) || (side != "BUY" && price <= level.Price) {
+			return i
+		}
+	}
+	return -1
+}
@@ -11,4 +11,11 @@ type Prediction struct {
 	Confidence float64 `json:"confidence"`
 	Price      float64 `json:"price"`
 	Reason     string  `json:"reason,omitempty"`
+	// Profile is the scoring profile of the prediction, and Breakdown the vote of each of its indicators
+	Profile   string           `json:"profile,omitempty"`
+	Breakdown []IndicatorScore `json:"breakdown,omitempty"`
+	// Probability is the chance that a signal of this confidence hits its take profit, calibrated on past signal
+	// outcomes, and Calibration names the calibration used; both are unset without one
+	Probability *float64 `json:"probability,omitempty"`
+	Calibration string   `json:"calibration,omitempty"`
 }
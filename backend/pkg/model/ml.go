@@ -0,0 +1,49 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// MLLogisticRegression is the kind of the logistic regression models
+const MLLogisticRegression = "logistic_regression"
+
+// MLModel is a version of a model trained to forecast whether the close of a symbol rises over the next Horizon
+// candles of Interval. Versions count up per symbol and interval, and at most one of them is active.
+type MLModel struct {
+	ID          int       `json:"id" db:"id"`
+	Symbol      string    `json:"symbol" db:"symbol"`
+	Interval    string    `json:"interval" db:"interval"`
+	Version     int       `json:"version" db:"version"`
+	Kind        string    `json:"kind" db:"kind"`
+	Horizon     int       `json:"horizon" db:"horizon"`
+	Samples     int       `json:"samples" db:"samples"` // Feature vectors the model was trained on
+	TrainedFrom time.Time `json:"trained_from" db:"trained_from"`
+	TrainedTo   time.Time `json:"trained_to" db:"trained_to"`
+	// Parameters are the fitted model, in the format of its kind
+	Parameters json.RawMessage `json:"parameters" db:"parameters"`
+	Validation *MLValidation   `json:"validation,omitempty" db:"validation"`
+	Active     bool            `json:"active" db:"active"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+}
+
+// MLFold is the out-of-sample performance of a model trained on the candles before its test window.
+type MLFold struct {
+	TrainSamples int       `json:"train_samples"`
+	TestSamples  int       `json:"test_samples"`
+	TestFrom     time.Time `json:"test_from"`
+	TestTo       time.Time `json:"test_to"`
+	Accuracy     float64   `json:"accuracy"` // Share of rises and falls forecast right at a probability threshold of 1/2
+	LogLoss      float64   `json:"log_loss"`
+	Brier        float64   `json:"brier"`     // Mean squared error of the forecast probability
+	BaseRate     float64   `json:"base_rate"` // Share of rises; always forecasting the more common outcome is right max(BaseRate, 1-BaseRate) of the time
+}
+
+// MLValidation is the walk-forward validation of a model: its folds, and their averages weighted by test samples.
+type MLValidation struct {
+	Folds    []MLFold `json:"folds"`
+	Accuracy float64  `json:"accuracy"`
+	LogLoss  float64  `json:"log_loss"`
+	Brier    float64  `json:"brier"`
+	BaseRate float64  `json:"base_rate"`
+}
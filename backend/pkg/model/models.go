@@ -32,8 +32,23 @@ type DBTrade struct {
 	Status     string     `json:"status" db:"status"` // OPEN, CLOSED, CANCELLED
 	ExecutedAt time.Time  `json:"executed_at" db:"executed_at"`
 	ClosedAt   *time.Time `json:"closed_at" db:"closed_at"`
+	Exchange   string     `json:"exchange" db:"exchange"`
+	// ProtectionListID is the order list ID of the OCO protecting the position, if any
+	ProtectionListID string  `json:"protection_list_id,omitempty" db:"protection_list_id"`
+	ExitPrice        float64 `json:"exit_price" db:"exit_price"`
+	Fees             float64 `json:"fees" db:"fees"`                 // Entry and exit fees, in the quote asset
+	CloseReason      string  `json:"close_reason" db:"close_reason"` // take_profit, stop_loss, break_even, trailing_stop or duration
+	// ExitRules are the trailing stop, break-even and take profit ladder rules of the trade, if any
+	ExitRules *ExitRules `json:"exit_rules,omitempty" db:"exit_rules"`
 }
 
+// Trade statuses
+const (
+	TradeOpen      = "OPEN"
+	TradeClosed    = "CLOSED"
+	TradeCancelled = "CANCELLED"
+)
+
 // Signal represents a trading signal
 type Signal struct {
 	ID         int       `json:"id" db:"id"`
@@ -45,4 +60,53 @@ type Signal struct {
 	StopLoss   float64   `json:"stop_loss" db:"stop_loss"`
 	Confidence float64   `json:"confidence" db:"confidence"`
 	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	Timeframe  string    `json:"timeframe" db:"timeframe"` // Timeframe the signal was generated on, if any
+	// Outcome of the signal, evaluated on the candles that follow it
+	EntryAt      *time.Time `json:"entry_at,omitempty" db:"entry_at"` // Close of the candle in which the price first traded at the signal price
+	Outcome      string     `json:"outcome" db:"outcome"`             // Empty while pending, then take_profit, stop_loss, expired, unfilled or unscorable
+	OutcomePrice float64    `json:"outcome_price" db:"outcome_price"` // Exit price of the outcome; while pending, the latest price evaluated
+	OutcomeAt    *time.Time `json:"outcome_at,omitempty" db:"outcome_at"`
+	// TimeToOutcome is the time from the signal to its outcome, in seconds
+	TimeToOutcome int64 `json:"time_to_outcome" db:"time_to_outcome"`
+	// Largest move in favour of and against the signal before its outcome, as a percentage of its price
+	MaxFavorableExcursion float64 `json:"max_favorable_excursion" db:"max_favorable_excursion"`
+	MaxAdverseExcursion   float64 `json:"max_adverse_excursion" db:"max_adverse_excursion"`
+	// Result of the outcome as a percentage of the price, and as a multiple of the risk to the stop loss (R)
+	ReturnPercent float64 `json:"return_percent" db:"return_percent"`
+	RMultiple     float64 `json:"r_multiple" db:"r_multiple"`
+	// EvaluatedUntil is the close of the last candle evaluated
+	EvaluatedUntil *time.Time `json:"-" db:"evaluated_until"`
+}
+
+// Signal outcomes
+const (
+	SignalPending    = ""
+	SignalTakeProfit = "take_profit"
+	SignalStopLoss   = "stop_loss"
+	SignalExpired    = "expired"    // Neither level was hit within the evaluation horizon
+	SignalUnfilled   = "unfilled"   // The price never traded at the signal price within the evaluation horizon
+	SignalUnscorable = "unscorable" // No take profit and stop loss on either side of the price to measure against
+)
+
+// SignalStats are the accuracy statistics of the evaluated signals of a strategy, symbol and timeframe.
+type SignalStats struct {
+	Strategy    string `json:"strategy"`
+	Symbol      string `json:"symbol"`
+	Timeframe   string `json:"timeframe"`
+	Evaluated   int    `json:"evaluated"` // Signals that hit their take profit or stop loss, or expired
+	TakeProfits int    `json:"take_profits"`
+	StopLosses  int    `json:"stop_losses"`
+	Expired     int    `json:"expired"`
+	Pending     int    `json:"pending"`
+	Unfilled    int    `json:"unfilled"`
+	Unscorable  int    `json:"unscorable"`
+	// HitRate is the share of evaluated signals that hit their take profit
+	HitRate float64 `json:"hit_rate"`
+	// Expectancy is the average return of an evaluated signal, as a percentage of its price
+	Expectancy float64 `json:"expectancy"`
+	// AverageR is the average return of an evaluated signal, as a multiple of its risk
+	AverageR                     float64 `json:"average_r"`
+	AverageTimeToOutcome         float64 `json:"average_time_to_outcome"` // Seconds
+	AverageMaxFavorableExcursion float64 `json:"average_max_favorable_excursion"`
+	AverageMaxAdverseExcursion   float64 `json:"average_max_adverse_excursion"`
 }
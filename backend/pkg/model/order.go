@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+// Order statuses, following the Binance order lifecycle
+const (
+	OrderNew             = "NEW"
+	OrderPartiallyFilled = "PARTIALLY_FILLED"
+	OrderFilled          = "FILLED"
+	OrderCanceled        = "CANCELED"
+	OrderRejected        = "REJECTED"
+	OrderExpired         = "EXPIRED"
+)
+
+// Order is an order placed on an exchange and its execution so far
+type Order struct {
+	ID              int       `json:"id" db:"id"`
+	UserID          int       `json:"user_id" db:"user_id"`
+	Exchange        string    `json:"exchange" db:"exchange"`
+	Symbol          string    `json:"symbol" db:"symbol"`
+	Side            string    `json:"side" db:"side"` // BUY or SELL
+	Type            string    `json:"type" db:"type"` // MARKET, LIMIT, STOP_LOSS_LIMIT or TAKE_PROFIT_LIMIT
+	ClientOrderID   string    `json:"client_order_id" db:"client_order_id"`
+	ExchangeOrderID string    `json:"exchange_order_id" db:"exchange_order_id"`
+	Quantity        float64   `json:"quantity" db:"quantity"`
+	Price           float64   `json:"price" db:"price"`                     // Limit price, 0 for market orders
+	StopPrice       float64   `json:"stop_price,omitempty" db:"stop_price"` // Trigger price of stop orders
+	TimeInForce     string    `json:"time_in_force,omitempty" db:"time_in_force"`
+	OrderListID     string    `json:"order_list_id,omitempty" db:"order_list_id"` // Shared by the two legs of an OCO
+	Status          string    `json:"status" db:"status"`
+	FilledQuantity  float64   `json:"filled_quantity" db:"filled_quantity"`
+	AvgPrice        float64   `json:"avg_price" db:"avg_price"` // Average fill price, 0 until something fills
+	Fee             float64   `json:"fee" db:"fee"`
+	FeeAsset        string    `json:"fee_asset,omitempty" db:"fee_asset"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// IsOpen reports whether the order can still fill
+func (o *Order) IsOpen() bool {
+	return o.Status == OrderNew || o.Status == OrderPartiallyFilled
+}
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// GlobalRiskUserID is the user ID of the risk limits row holding the defaults for all users and the global kill switch
+const GlobalRiskUserID = 0
+
+// RiskLimits holds the pre-trade limits of a user.
+// A zero limit falls back to the global default, and is not enforced when the default is zero too.
+type RiskLimits struct {
+	UserID             int       `json:"user_id" db:"user_id"`
+	MaxPositionSize    float64   `json:"max_position_size" db:"max_position_size"`         // Largest holding of one symbol, in the quote asset
+	MaxTotalExposure   float64   `json:"max_total_exposure" db:"max_total_exposure"`       // Largest value of all holdings, in the quote asset
+	MaxOrdersPerMinute int       `json:"max_orders_per_minute" db:"max_orders_per_minute"` // Orders allowed in any 60 second window
+	MaxDailyLoss       float64   `json:"max_daily_loss" db:"max_daily_loss"`               // Largest realised loss since midnight UTC, in the quote asset
+	MaxDrawdownPercent float64   `json:"max_drawdown_percent" db:"max_drawdown_percent"`   // Largest fall of equity from its peak
+	KillSwitch         bool      `json:"kill_switch" db:"kill_switch"`                     // Cancels open orders and blocks new ones
+	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// DefaultScoringProfile is the name of the built-in scoring profile, used when no profile is selected
+const DefaultScoringProfile = "default"
+
+// IndicatorWeight sets the weight and parameters of one registered indicator in a scoring profile
+type IndicatorWeight struct {
+	Name   string             `json:"name"`             // Registered indicator, such as rsi, macd or bollinger
+	Weight float64            `json:"weight"`           // Points a full vote of the indicator is worth
+	Params map[string]float64 `json:"params,omitempty"` // Overrides of the indicator's default parameters and thresholds
+}
+
+// ScoringProfile is a named set of weighted indicators that predictions are scored with
+type ScoringProfile struct {
+	ID         int               `json:"id,omitempty" db:"id"`
+	UserID     int               `json:"user_id,omitempty" db:"user_id"` // 0 for the built-in profile
+	Name       string            `json:"name" db:"name"`
+	Indicators []IndicatorWeight `json:"indicators" db:"indicators"`
+	CreatedAt  time.Time         `json:"created_at,omitempty" db:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at,omitempty" db:"updated_at"`
+}
+
+// IndicatorScore explains the vote of one indicator in a prediction
+type IndicatorScore struct {
+	Name   string             `json:"name"`
+	Vote   float64            `json:"vote"` // From -1 for sell to 1 for buy; 0 abstains
+	Weight float64            `json:"weight"`
+	Score  float64            `json:"score"`            // Vote times weight
+	Values map[string]float64 `json:"values,omitempty"` // Indicator values the vote was taken on
+	Reason string             `json:"reason"`
+}
@@ -1,18 +1,23 @@
 package predictor
 
 import (
+	"context"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/backtest"
 	"github.com/ratheeshkumar25/forex_bot/backend/pkg/strategy"
 )
 
 // Predictor handles profit predictions
-type Predictor struct{}
+type Predictor struct {
+	engine *backtest.Engine
+}
 
 // NewPredictor creates a new predictor
-func NewPredictor() *Predictor {
-	return &Predictor{}
+func NewPredictor(engine *backtest.Engine) *Predictor {
+	return &Predictor{engine: engine}
 }
 
-// PredictProfit predicts profit based on strategy, symbol, investment, and timeframe
-func (p *Predictor) PredictProfit(strat strategy.Strategy, symbol string, investment float64, timeframe string) (float64, float64, error) {
-	return strat.GetProfitPrediction(symbol, investment, timeframe)
+// PredictProfit predicts profit by backtesting the strategy over the configured historical range
+func (p *Predictor) PredictProfit(ctx context.Context, strat strategy.Strategy, cfg backtest.Config) (*backtest.Result, error) {
+	return p.engine.Run(ctx, strat, cfg)
 }
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+)
+
+// AutoTradeRepository handles database operations for auto trade subscriptions
+type AutoTradeRepository struct {
+	db *sql.DB
+}
+
+// NewAutoTradeRepository creates a new auto trade repository
+func NewAutoTradeRepository(db *sql.DB) *AutoTradeRepository {
+	return &AutoTradeRepository{db: db}
+}
+
+// UpsertSubscription creates a subscription, replacing the user's existing subscription for the symbol
+func (r *AutoTradeRepository) UpsertSubscription(sub *model.AutoTradeSubscription) error {
+	rules, err := marshalExitRules(sub.ExitRules)
+	if err != nil {
+		return err
+	}
+	query := `INSERT INTO auto_trade_subscriptions (user_id, symbol, exchange, risk_percent, exit_rules)
+	          VALUES ($1, $2, $3, $4, $5)
+	          ON CONFLICT (user_id, symbol) DO UPDATE SET exchange = EXCLUDED.exchange, risk_percent = EXCLUDED.risk_percent, exit_rules = EXCLUDED.exit_rules
+	          RETURNING id, created_at`
+	return r.db.QueryRow(query, sub.UserID, sub.Symbol, sub.Exchange, sub.RiskPercent, rules).Scan(&sub.ID, &sub.CreatedAt)
+}
+
+// DeleteSubscription removes the user's subscription for a symbol
+func (r *AutoTradeRepository) DeleteSubscription(userID int, symbol string) (bool, error) {
+	query := `DELETE FROM auto_trade_subscriptions WHERE user_id = $1 AND symbol = $2`
+	res, err := r.db.Exec(query, userID, symbol)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// GetSubscriptionsByUserID retrieves the subscriptions of a user
+func (r *AutoTradeRepository) GetSubscriptionsByUserID(userID int) ([]*model.AutoTradeSubscription, error) {
+	query := `SELECT id, user_id, symbol, exchange, risk_percent, created_at, exit_rules
+	          FROM auto_trade_subscriptions WHERE user_id = $1 ORDER BY symbol`
+	return r.querySubscriptions(query, userID)
+}
+
+// GetSubscriptionsBySymbol retrieves all subscriptions for a symbol
+func (r *AutoTradeRepository) GetSubscriptionsBySymbol(symbol string) ([]*model.AutoTradeSubscription, error) {
+	query := `SELECT id, user_id, symbol, exchange, risk_percent, created_at, exit_rules
+	          FROM auto_trade_subscriptions WHERE symbol = $1 ORDER BY id`
+	return r.querySubscriptions(query, symbol)
+}
+
+// GetSubscribedSymbols retrieves every symbol with at least one subscription
+func (r *AutoTradeRepository) GetSubscribedSymbols() ([]string, error) {
+	rows, err := r.db.Query(`SELECT DISTINCT symbol FROM auto_trade_subscriptions ORDER BY symbol`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var symbols []string
+	for rows.Next() {
+		var symbol string
+		if err := rows.Scan(&symbol); err != nil {
+			return nil, err
+		}
+		symbols = append(symbols, symbol)
+	}
+	return symbols, rows.Err()
+}
+
+func (r *AutoTradeRepository) querySubscriptions(query string, args ...interface{}) ([]*model.AutoTradeSubscription, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []*model.AutoTradeSubscription
+	for rows.Next() {
+		sub := &model.AutoTradeSubscription{}
+		var rules []byte
+		if err := rows.Scan(&sub.ID, &sub.UserID, &sub.Symbol, &sub.Exchange, &sub.RiskPercent, &sub.CreatedAt, &rules); err != nil {
+			return nil, err
+		}
+		var err error
+		if sub.ExitRules, err = unmarshalExitRules(rules); err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
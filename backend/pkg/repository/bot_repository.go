@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+)
+
+// BotRepository handles database operations for bots
+type BotRepository struct {
+	db *sql.DB
+}
+
+// NewBotRepository creates a new bot repository
+func NewBotRepository(db *sql.DB) *BotRepository {
+	return &BotRepository{db: db}
+}
+
+// CreateBot creates a new bot
+func (r *BotRepository) CreateBot(bot *model.Bot) error {
+	query := `INSERT INTO bots (user_id, strategy, symbol, exchange, interval, params, state, status)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, created_at, updated_at`
+	return r.db.QueryRow(query, bot.UserID, bot.Strategy, bot.Symbol, bot.Exchange, bot.Interval, nullJSON(bot.Params), nullJSON(bot.State), bot.Status).
+		Scan(&bot.ID, &bot.StartedAt, &bot.UpdatedAt)
+}
+
+// UpdateBotState stores the strategy state of a bot
+func (r *BotRepository) UpdateBotState(id int, state json.RawMessage) error {
+	query := `UPDATE bots SET state = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
+	_, err := r.db.Exec(query, nullJSON(state), id)
+	return err
+}
+
+// UpdateBotStatus updates the status of a bot
+func (r *BotRepository) UpdateBotStatus(id int, status string) error {
+	query := `UPDATE bots SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
+	_, err := r.db.Exec(query, status, id)
+	return err
+}
+
+// GetBotByID retrieves a bot by ID
+func (r *BotRepository) GetBotByID(id int) (*model.Bot, error) {
+	query := `SELECT id, user_id, strategy, symbol, exchange, interval, params, state, status, created_at, updated_at
+	          FROM bots WHERE id = $1`
+	bots, err := r.queryBots(query, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(bots) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return bots[0], nil
+}
+
+// GetBotsByStatus retrieves all bots with a status, oldest first
+func (r *BotRepository) GetBotsByStatus(status string) ([]*model.Bot, error) {
+	query := `SELECT id, user_id, strategy, symbol, exchange, interval, params, state, status, created_at, updated_at
+	          FROM bots WHERE status = $1 ORDER BY id`
+	return r.queryBots(query, status)
+}
+
+func (r *BotRepository) queryBots(query string, args ...interface{}) ([]*model.Bot, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bots []*model.Bot
+	for rows.Next() {
+		bot := &model.Bot{}
+		var params, state []byte
+		err := rows.Scan(&bot.ID, &bot.UserID, &bot.Strategy, &bot.Symbol, &bot.Exchange, &bot.Interval, &params, &state, &bot.Status, &bot.StartedAt, &bot.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		bot.Params, bot.State = params, state
+		bots = append(bots, bot)
+	}
+	return bots, rows.Err()
+}
+
+// nullJSON stores empty JSON as NULL
+func nullJSON(data json.RawMessage) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return []byte(data)
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+)
+
+// CandleRepository handles database operations for candles
+type CandleRepository struct {
+	db *sql.DB
+}
+
+// NewCandleRepository creates a new candle repository
+func NewCandleRepository(db *sql.DB) *CandleRepository {
+	return &CandleRepository{db: db}
+}
+
+// UpsertCandles inserts candles, overwriting any stored candle with the same symbol, interval and open time.
+// Times are stored in UTC.
+func (r *CandleRepository) UpsertCandles(candles []model.Candle) error {
+	if len(candles) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO candles (symbol, interval, open_time, close_time, open, high, low, close, volume, trade_count)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	          ON CONFLICT (symbol, interval, open_time) DO UPDATE SET
+	          close_time = EXCLUDED.close_time, open = EXCLUDED.open, high = EXCLUDED.high, low = EXCLUDED.low,
+	          close = EXCLUDED.close, volume = EXCLUDED.volume, trade_count = EXCLUDED.trade_count`
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, c := range candles {
+		if _, err := stmt.Exec(c.Symbol, c.Interval, c.OpenTime.UTC(), c.CloseTime.UTC(), c.Open, c.High, c.Low, c.Close, c.Volume, c.TradeCount); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetCandles retrieves the candles opening in [start, end), oldest first
+func (r *CandleRepository) GetCandles(symbol, interval string, start, end time.Time) ([]model.Candle, error) {
+	query := `SELECT symbol, interval, open_time, close_time, open, high, low, close, volume, trade_count
+	          FROM candles WHERE symbol = $1 AND interval = $2 AND open_time >= $3 AND open_time < $4 ORDER BY open_time`
+	return r.queryCandles(query, symbol, interval, start.UTC(), end.UTC())
+}
+
+// GetLatestCandles retrieves the most recent limit candles, oldest first
+func (r *CandleRepository) GetLatestCandles(symbol, interval string, limit int) ([]model.Candle, error) {
+	query := `SELECT symbol, interval, open_time, close_time, open, high, low, close, volume, trade_count
+	          FROM (SELECT * FROM candles WHERE symbol = $1 AND interval = $2 ORDER BY open_time DESC LIMIT $3) latest
+	          ORDER BY open_time`
+	return r.queryCandles(query, symbol, interval, limit)
+}
+
+func (r *CandleRepository) queryCandles(query string, args ...interface{}) ([]model.Candle, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candles []model.Candle
+	for rows.Next() {
+		var c model.Candle
+		err := rows.Scan(&c.Symbol, &c.Interval, &c.OpenTime, &c.CloseTime, &c.Open, &c.High, &c.Low, &c.Close, &c.Volume, &c.TradeCount)
+		if err != nil {
+			return nil, err
+		}
+		candles = append(candles, c)
+	}
+	return candles, rows.Err()
+}
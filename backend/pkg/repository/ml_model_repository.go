@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+)
+
+// MLModelRepository handles database operations for the versions of trained ML models
+type MLModelRepository struct {
+	db *sql.DB
+}
+
+// NewMLModelRepository creates a new ML model repository
+func NewMLModelRepository(db *sql.DB) *MLModelRepository {
+	return &MLModelRepository{db: db}
+}
+
+const mlModelColumns = `id, symbol, interval, version, kind, horizon, parameters, validation, trained_from, trained_to, samples, active, created_at`
+
+// CreateModel stores a model as the next version of its symbol and interval, inactive
+func (r *MLModelRepository) CreateModel(m *model.MLModel) error {
+	var validation []byte
+	if m.Validation != nil {
+		var err error
+		if validation, err = json.Marshal(m.Validation); err != nil {
+			return err
+		}
+	}
+	query := `INSERT INTO ml_models (symbol, interval, version, kind, horizon, parameters, validation, trained_from, trained_to, samples)
+	          SELECT $1, $2, COALESCE(MAX(version), 0) + 1, $3, $4, $5, $6, $7, $8, $9 FROM ml_models WHERE symbol = $1 AND interval = $2
+	          RETURNING id, version, active, created_at`
+	return r.db.QueryRow(query, m.Symbol, m.Interval, m.Kind, m.Horizon, []byte(m.Parameters), validation, m.TrainedFrom, m.TrainedTo, m.Samples).
+		Scan(&m.ID, &m.Version, &m.Active, &m.CreatedAt)
+}
+
+// GetModel retrieves a model by ID
+func (r *MLModelRepository) GetModel(id int) (*model.MLModel, error) {
+	models, err := r.queryModels(`SELECT `+mlModelColumns+` FROM ml_models WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(models) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return models[0], nil
+}
+
+// GetModels retrieves the models, newest version first; an empty symbol or interval matches all
+func (r *MLModelRepository) GetModels(symbol, interval string) ([]*model.MLModel, error) {
+	query := `SELECT ` + mlModelColumns + ` FROM ml_models
+	          WHERE ($1 = '' OR symbol = $1) AND ($2 = '' OR interval = $2)
+	          ORDER BY symbol, interval, version DESC`
+	return r.queryModels(query, symbol, interval)
+}
+
+// GetActiveModel retrieves the active model of a symbol and interval, or nil if none is active
+func (r *MLModelRepository) GetActiveModel(symbol, interval string) (*model.MLModel, error) {
+	models, err := r.queryModels(`SELECT `+mlModelColumns+` FROM ml_models WHERE symbol = $1 AND interval = $2 AND active`, symbol, interval)
+	if err != nil || len(models) == 0 {
+		return nil, err
+	}
+	return models[0], nil
+}
+
+// ActivateModel makes a model the active one of its symbol and interval, deactivating the others
+func (r *MLModelRepository) ActivateModel(id int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var symbol, interval string
+	if err := tx.QueryRow(`SELECT symbol, interval FROM ml_models WHERE id = $1 FOR UPDATE`, id).Scan(&symbol, &interval); err != nil {
+		return err
+	}
+	// Deactivate first, as the partial unique index allows one active model at any moment
+	if _, err := tx.Exec(`UPDATE ml_models SET active = FALSE WHERE symbol = $1 AND interval = $2 AND active AND id <> $3`, symbol, interval, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE ml_models SET active = TRUE WHERE id = $1`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *MLModelRepository) queryModels(query string, args ...interface{}) ([]*model.MLModel, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var models []*model.MLModel
+	for rows.Next() {
+		m := &model.MLModel{}
+		var parameters, validation []byte
+		if err := rows.Scan(&m.ID, &m.Symbol, &m.Interval, &m.Version, &m.Kind, &m.Horizon, &parameters, &validation,
+			&m.TrainedFrom, &m.TrainedTo, &m.Samples, &m.Active, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		m.Parameters = parameters
+		if validation != nil {
+			m.Validation = &model.MLValidation{}
+			if err := json.Unmarshal(validation, m.Validation); err != nil {
+				return nil, err
+			}
+		}
+		models = append(models, m)
+	}
+	return models, rows.Err()
+}
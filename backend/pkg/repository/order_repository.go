@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+)
+
+// OrderRepository handles database operations for exchange orders
+type OrderRepository struct {
+	db *sql.DB
+}
+
+// NewOrderRepository creates a new order repository
+func NewOrderRepository(db *sql.DB) *OrderRepository {
+	return &OrderRepository{db: db}
+}
+
+// orderColumns are the columns read by the order queries, in queryOrders scan order
+const orderColumns = `id, user_id, exchange, symbol, side, type, client_order_id, exchange_order_id, quantity, price,
+	stop_price, time_in_force, order_list_id, status, filled_quantity, avg_price, fee, fee_asset, created_at, updated_at`
+
+// CreateOrder records a newly placed order
+func (r *OrderRepository) CreateOrder(order *model.Order) error {
+	query := `INSERT INTO orders (user_id, exchange, symbol, side, type, client_order_id, exchange_order_id, quantity, price,
+	          stop_price, time_in_force, order_list_id, status, filled_quantity, avg_price, fee, fee_asset)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17) RETURNING id, created_at, updated_at`
+	return r.db.QueryRow(query, order.UserID, order.Exchange, order.Symbol, order.Side, order.Type, order.ClientOrderID, order.ExchangeOrderID,
+		order.Quantity, order.Price, order.StopPrice, order.TimeInForce, order.OrderListID,
+		order.Status, order.FilledQuantity, order.AvgPrice, order.Fee, order.FeeAsset).
+		Scan(&order.ID, &order.CreatedAt, &order.UpdatedAt)
+}
+
+// UpdateOrderStatus stores the execution state of an order, matched by its client order ID.
+// It sets order.ID and reports whether the stored status or filled quantity changed;
+// it returns false if the order was not placed through this application.
+func (r *OrderRepository) UpdateOrderStatus(order *model.Order) (bool, error) {
+	// The FROM subquery reads the row as it was before the update
+	query := `UPDATE orders o SET status = $1, filled_quantity = $2, avg_price = $3, fee = $4, fee_asset = $5, updated_at = CURRENT_TIMESTAMP
+	          FROM (SELECT id, status, filled_quantity FROM orders WHERE client_order_id = $6) prev
+	          WHERE o.id = prev.id
+	          RETURNING o.id, o.user_id, o.created_at, prev.status <> o.status OR prev.filled_quantity <> o.filled_quantity`
+	var changed bool
+	err := r.db.QueryRow(query, order.Status, order.FilledQuantity, order.AvgPrice, order.Fee, order.FeeAsset, order.ClientOrderID).
+		Scan(&order.ID, &order.UserID, &order.CreatedAt, &changed)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return changed, err
+}
+
+// CancelOpenOrders marks every open order of a user on an exchange as cancelled
+func (r *OrderRepository) CancelOpenOrders(userID int, exchange string) error {
+	query := `UPDATE orders SET status = $1, updated_at = CURRENT_TIMESTAMP
+	          WHERE user_id = $2 AND exchange = $3 AND status IN ($4, $5)`
+	_, err := r.db.Exec(query, model.OrderCanceled, userID, exchange, model.OrderNew, model.OrderPartiallyFilled)
+	return err
+}
+
+// GetOrderByID retrieves an order by ID
+func (r *OrderRepository) GetOrderByID(id int) (*model.Order, error) {
+	orders, err := r.queryOrders(`SELECT `+orderColumns+` FROM orders WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return orders[0], nil
+}
+
+// GetOrdersByUserID retrieves the most recent orders of a user, newest first
+func (r *OrderRepository) GetOrdersByUserID(userID, limit int) ([]*model.Order, error) {
+	query := `SELECT ` + orderColumns + ` FROM orders WHERE user_id = $1 ORDER BY created_at DESC, id DESC LIMIT $2`
+	return r.queryOrders(query, userID, limit)
+}
+
+// GetOpenOrdersByUserID retrieves the orders of a user that can still fill, oldest first
+func (r *OrderRepository) GetOpenOrdersByUserID(userID int) ([]*model.Order, error) {
+	query := `SELECT ` + orderColumns + ` FROM orders WHERE user_id = $1 AND status IN ($2, $3) ORDER BY id`
+	return r.queryOrders(query, userID, model.OrderNew, model.OrderPartiallyFilled)
+}
+
+// GetOrdersByListID retrieves the legs of an OCO of a user on an exchange
+func (r *OrderRepository) GetOrdersByListID(userID int, exchange, listID string) ([]*model.Order, error) {
+	query := `SELECT ` + orderColumns + ` FROM orders WHERE user_id = $1 AND exchange = $2 AND order_list_id = $3 ORDER BY id`
+	return r.queryOrders(query, userID, exchange, listID)
+}
+
+func (r *OrderRepository) queryOrders(query string, args ...interface{}) ([]*model.Order, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := []*model.Order{}
+	for rows.Next() {
+		o := &model.Order{}
+		err := rows.Scan(&o.ID, &o.UserID, &o.Exchange, &o.Symbol, &o.Side, &o.Type, &o.ClientOrderID, &o.ExchangeOrderID, &o.Quantity, &o.Price,
+			&o.StopPrice, &o.TimeInForce, &o.OrderListID, &o.Status, &o.FilledQuantity, &o.AvgPrice, &o.Fee, &o.FeeAsset, &o.CreatedAt, &o.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, o)
+	}
+	return orders, rows.Err()
+}
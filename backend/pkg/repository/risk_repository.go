@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+)
+
+// RiskRepository handles database operations for risk limits
+type RiskRepository struct {
+	db *sql.DB
+}
+
+// NewRiskRepository creates a new risk repository
+func NewRiskRepository(db *sql.DB) *RiskRepository {
+	return &RiskRepository{db: db}
+}
+
+// GetLimits retrieves the limits of a user, or sql.ErrNoRows if the user has none of their own
+func (r *RiskRepository) GetLimits(userID int) (*model.RiskLimits, error) {
+	limits := &model.RiskLimits{}
+	query := `SELECT user_id, max_position_size, max_total_exposure, max_orders_per_minute, max_daily_loss, max_drawdown_percent, kill_switch, updated_at
+	          FROM risk_limits WHERE user_id = $1`
+	err := r.db.QueryRow(query, userID).Scan(&limits.UserID, &limits.MaxPositionSize, &limits.MaxTotalExposure, &limits.MaxOrdersPerMinute, &limits.MaxDailyLoss, &limits.MaxDrawdownPercent, &limits.KillSwitch, &limits.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return limits, nil
+}
+
+// UpsertLimits creates or replaces the limits of a user
+func (r *RiskRepository) UpsertLimits(limits *model.RiskLimits) error {
+	query := `INSERT INTO risk_limits (user_id, max_position_size, max_total_exposure, max_orders_per_minute, max_daily_loss, max_drawdown_percent, kill_switch)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7)
+	          ON CONFLICT (user_id) DO UPDATE SET
+	          max_position_size = EXCLUDED.max_position_size, max_total_exposure = EXCLUDED.max_total_exposure,
+	          max_orders_per_minute = EXCLUDED.max_orders_per_minute, max_daily_loss = EXCLUDED.max_daily_loss,
+	          max_drawdown_percent = EXCLUDED.max_drawdown_percent, kill_switch = EXCLUDED.kill_switch, updated_at = CURRENT_TIMESTAMP
+	          RETURNING updated_at`
+	return r.db.QueryRow(query, limits.UserID, limits.MaxPositionSize, limits.MaxTotalExposure, limits.MaxOrdersPerMinute, limits.MaxDailyLoss, limits.MaxDrawdownPercent, limits.KillSwitch).Scan(&limits.UpdatedAt)
+}
+
+// SetKillSwitch turns the kill switch of a user on or off, keeping their other limits
+func (r *RiskRepository) SetKillSwitch(userID int, on bool) error {
+	query := `INSERT INTO risk_limits (user_id, kill_switch) VALUES ($1, $2)
+	          ON CONFLICT (user_id) DO UPDATE SET kill_switch = EXCLUDED.kill_switch, updated_at = CURRENT_TIMESTAMP`
+	_, err := r.db.Exec(query, userID, on)
+	return err
+}
+
+// GetState retrieves the peak equity by quote asset and the ordered symbols the risk engine keeps for a user.
+// Both are empty for a user without a row.
+func (r *RiskRepository) GetState(userID int) (map[string]float64, []string, error) {
+	var peakData, symbolData []byte
+	err := r.db.QueryRow(`SELECT peak_equity, symbols FROM risk_limits WHERE user_id = $1`, userID).Scan(&peakData, &symbolData)
+	if errors.Is(err, sql.ErrNoRows) {
+		return map[string]float64{}, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	peaks := make(map[string]float64)
+	if err := json.Unmarshal(peakData, &peaks); err != nil {
+		return nil, nil, err
+	}
+	var symbols []string
+	if err := json.Unmarshal(symbolData, &symbols); err != nil {
+		return nil, nil, err
+	}
+	return peaks, symbols, nil
+}
+
+// SetPeak stores the peak equity of a user in a quote asset, keeping their other peaks and limits
+func (r *RiskRepository) SetPeak(userID int, quote string, equity float64) error {
+	query := `INSERT INTO risk_limits (user_id, peak_equity) VALUES ($1, jsonb_build_object($2::text, $3::float8))
+	          ON CONFLICT (user_id) DO UPDATE SET peak_equity = risk_limits.peak_equity || EXCLUDED.peak_equity`
+	_, err := r.db.Exec(query, userID, quote, equity)
+	return err
+}
+
+// AddSymbol adds a symbol to those a user has ordered, keeping their limits
+func (r *RiskRepository) AddSymbol(userID int, symbol string) error {
+	query := `INSERT INTO risk_limits (user_id, symbols) VALUES ($1, jsonb_build_array($2::text))
+	          ON CONFLICT (user_id) DO UPDATE SET symbols = risk_limits.symbols || EXCLUDED.symbols
+	          WHERE NOT risk_limits.symbols ? $2`
+	_, err := r.db.Exec(query, userID, symbol)
+	return err
+}
+
+// GetUserIDs retrieves the IDs of all users with their own limits
+func (r *RiskRepository) GetUserIDs() ([]int, error) {
+	rows, err := r.db.Query(`SELECT user_id FROM risk_limits WHERE user_id <> 0`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+)
+
+// ScoringProfileRepository handles database operations for scoring profiles
+type ScoringProfileRepository struct {
+	db *sql.DB
+}
+
+// NewScoringProfileRepository creates a new scoring profile repository
+func NewScoringProfileRepository(db *sql.DB) *ScoringProfileRepository {
+	return &ScoringProfileRepository{db: db}
+}
+
+// UpsertProfile creates a profile, replacing the indicators of the user's existing profile of the same name
+func (r *ScoringProfileRepository) UpsertProfile(profile *model.ScoringProfile) error {
+	indicators, err := json.Marshal(profile.Indicators)
+	if err != nil {
+		return err
+	}
+	query := `INSERT INTO scoring_profiles (user_id, name, indicators)
+	          VALUES ($1, $2, $3)
+	          ON CONFLICT (user_id, name) DO UPDATE SET indicators = EXCLUDED.indicators, updated_at = CURRENT_TIMESTAMP
+	          RETURNING id, created_at, updated_at`
+	return r.db.QueryRow(query, profile.UserID, profile.Name, indicators).Scan(&profile.ID, &profile.CreatedAt, &profile.UpdatedAt)
+}
+
+// GetProfile retrieves a profile of the user by name
+func (r *ScoringProfileRepository) GetProfile(userID int, name string) (*model.ScoringProfile, error) {
+	query := `SELECT id, user_id, name, indicators, created_at, updated_at FROM scoring_profiles WHERE user_id = $1 AND name = $2`
+	profiles, err := r.queryProfiles(query, userID, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(profiles) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return profiles[0], nil
+}
+
+// GetProfilesByUserID retrieves the profiles of a user
+func (r *ScoringProfileRepository) GetProfilesByUserID(userID int) ([]*model.ScoringProfile, error) {
+	query := `SELECT id, user_id, name, indicators, created_at, updated_at FROM scoring_profiles WHERE user_id = $1 ORDER BY name`
+	return r.queryProfiles(query, userID)
+}
+
+// DeleteProfile removes a profile of the user
+func (r *ScoringProfileRepository) DeleteProfile(userID int, name string) (bool, error) {
+	res, err := r.db.Exec(`DELETE FROM scoring_profiles WHERE user_id = $1 AND name = $2`, userID, name)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *ScoringProfileRepository) queryProfiles(query string, args ...interface{}) ([]*model.ScoringProfile, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var profiles []*model.ScoringProfile
+	for rows.Next() {
+		profile := &model.ScoringProfile{}
+		var indicators []byte
+		if err := rows.Scan(&profile.ID, &profile.UserID, &profile.Name, &indicators, &profile.CreatedAt, &profile.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(indicators, &profile.Indicators); err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, rows.Err()
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"database/sql"
+	"strconv"
+	"strings"
 
 	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
 )
@@ -16,18 +18,35 @@ func NewSignalRepository(db *sql.DB) *SignalRepository {
 	return &SignalRepository{db: db}
 }
 
+// signalColumns are the columns read by the signal queries, in querySignals scan order
+const signalColumns = `id, symbol, strategy, type, price, take_profit, stop_loss, confidence, created_at, timeframe,
+	outcome, outcome_price, outcome_at, time_to_outcome, max_favorable_excursion, max_adverse_excursion, return_percent, r_multiple, evaluated_until, entry_at`
+
 // CreateSignal creates a new signal
 func (r *SignalRepository) CreateSignal(signal *model.Signal) error {
-	query := `INSERT INTO signals (symbol, strategy, signal_type, price, take_profit, stop_loss, confidence, created_at)
-	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`
-	return r.db.QueryRow(query, signal.Symbol, signal.Strategy, signal.Type, signal.Price, signal.TakeProfit, signal.StopLoss, signal.Confidence, signal.CreatedAt).Scan(&signal.ID)
+	query := `INSERT INTO signals (symbol, strategy, type, price, take_profit, stop_loss, confidence, created_at, timeframe)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`
+	return r.db.QueryRow(query, signal.Symbol, signal.Strategy, signal.Type, signal.Price, signal.TakeProfit, signal.StopLoss, signal.Confidence, signal.CreatedAt,
+		signal.Timeframe).Scan(&signal.ID)
 }
 
 // GetSignalsBySymbol retrieves signals for a symbol
 func (r *SignalRepository) GetSignalsBySymbol(symbol string) ([]*model.Signal, error) {
-	query := `SELECT id, symbol, strategy, signal_type, price, take_profit, stop_loss, confidence, created_at
-	          FROM signals WHERE symbol = $1 ORDER BY created_at DESC`
-	rows, err := r.db.Query(query, symbol)
+	return r.querySignals(`SELECT `+signalColumns+` FROM signals WHERE symbol = $1 ORDER BY created_at DESC`, symbol)
+}
+
+// GetRecentSignals retrieves the latest limit signals, of a symbol unless it is empty
+func (r *SignalRepository) GetRecentSignals(symbol string, limit int) ([]*model.Signal, error) {
+	return r.querySignals(`SELECT `+signalColumns+` FROM signals WHERE $1 = '' OR symbol = $1 ORDER BY created_at DESC LIMIT $2`, symbol, limit)
+}
+
+// GetPendingSignals retrieves the signals whose outcome is not known yet, oldest first
+func (r *SignalRepository) GetPendingSignals() ([]*model.Signal, error) {
+	return r.querySignals(`SELECT ` + signalColumns + ` FROM signals WHERE outcome = '' ORDER BY created_at`)
+}
+
+func (r *SignalRepository) querySignals(query string, args ...interface{}) ([]*model.Signal, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -36,13 +55,112 @@ func (r *SignalRepository) GetSignalsBySymbol(symbol string) ([]*model.Signal, e
 	var signals []*model.Signal
 	for rows.Next() {
 		signal := &model.Signal{}
-		err := rows.Scan(&signal.ID, &signal.Symbol, &signal.Strategy, &signal.Type, &signal.Price, &signal.TakeProfit, &signal.StopLoss, &signal.Confidence, &signal.CreatedAt)
+		var takeProfit, stopLoss sql.NullFloat64
+		err := rows.Scan(&signal.ID, &signal.Symbol, &signal.Strategy, &signal.Type, &signal.Price, &takeProfit, &stopLoss, &signal.Confidence, &signal.CreatedAt,
+			&signal.Timeframe, &signal.Outcome, &signal.OutcomePrice, &signal.OutcomeAt, &signal.TimeToOutcome, &signal.MaxFavorableExcursion,
+			&signal.MaxAdverseExcursion, &signal.ReturnPercent, &signal.RMultiple, &signal.EvaluatedUntil, &signal.EntryAt)
 		if err != nil {
 			return nil, err
 		}
+		signal.TakeProfit, signal.StopLoss = takeProfit.Float64, stopLoss.Float64
 		signals = append(signals, signal)
 	}
-	return signals, nil
+	return signals, rows.Err()
+}
+
+// GetCalibrationSamples retrieves the confidence and outcome of the latest limit evaluated signals of a strategy
+func (r *SignalRepository) GetCalibrationSamples(strategy string, limit int) ([]model.CalibrationSample, error) {
+	query := `SELECT symbol, timeframe, confidence, outcome = 'take_profit'
+	          FROM signals WHERE strategy = $1 AND outcome IN ('take_profit', 'stop_loss', 'expired')
+	          ORDER BY created_at DESC LIMIT $2`
+	rows, err := r.db.Query(query, strategy, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var samples []model.CalibrationSample
+	for rows.Next() {
+		var s model.CalibrationSample
+		if err := rows.Scan(&s.Symbol, &s.Timeframe, &s.Confidence, &s.Hit); err != nil {
+			return nil, err
+		}
+		samples = append(samples, s)
+	}
+	return samples, rows.Err()
+}
+
+// UpdateSignalOutcome stores the evaluation of a signal
+func (r *SignalRepository) UpdateSignalOutcome(signal *model.Signal) error {
+	query := `UPDATE signals SET outcome = $1, outcome_price = $2, outcome_at = $3, time_to_outcome = $4, max_favorable_excursion = $5,
+	          max_adverse_excursion = $6, return_percent = $7, r_multiple = $8, evaluated_until = $9, entry_at = $10
+	          WHERE id = $11`
+	_, err := r.db.Exec(query, signal.Outcome, signal.OutcomePrice, signal.OutcomeAt, signal.TimeToOutcome, signal.MaxFavorableExcursion,
+		signal.MaxAdverseExcursion, signal.ReturnPercent, signal.RMultiple, signal.EvaluatedUntil, signal.EntryAt, signal.ID)
+	return err
+}
+
+// SignalStatsFilter selects the signals of the accuracy statistics; a nil field selects any value
+type SignalStatsFilter struct {
+	Strategy  *string
+	Symbol    *string
+	Timeframe *string
+}
+
+// GetSignalStats retrieves the accuracy statistics of the selected signals by strategy, symbol and timeframe
+func (r *SignalRepository) GetSignalStats(filter SignalStatsFilter) ([]*model.SignalStats, error) {
+	var conditions []string
+	var args []interface{}
+	for _, f := range []struct {
+		column string
+		value  *string
+	}{{"strategy", filter.Strategy}, {"symbol", filter.Symbol}, {"timeframe", filter.Timeframe}} {
+		if f.value != nil {
+			args = append(args, *f.value)
+			conditions = append(conditions, f.column+" = $"+strconv.Itoa(len(args)))
+		}
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query := `SELECT strategy, symbol, timeframe,
+	          COUNT(*) FILTER (WHERE outcome IN ('take_profit', 'stop_loss', 'expired')),
+	          COUNT(*) FILTER (WHERE outcome = 'take_profit'),
+	          COUNT(*) FILTER (WHERE outcome = 'stop_loss'),
+	          COUNT(*) FILTER (WHERE outcome = 'expired'),
+	          COUNT(*) FILTER (WHERE outcome = ''),
+	          COUNT(*) FILTER (WHERE outcome = 'unfilled'),
+	          COUNT(*) FILTER (WHERE outcome = 'unscorable'),
+	          COALESCE(AVG(return_percent) FILTER (WHERE outcome IN ('take_profit', 'stop_loss', 'expired')), 0),
+	          COALESCE(AVG(r_multiple) FILTER (WHERE outcome IN ('take_profit', 'stop_loss', 'expired')), 0),
+	          COALESCE(AVG(time_to_outcome) FILTER (WHERE outcome IN ('take_profit', 'stop_loss', 'expired')), 0),
+	          COALESCE(AVG(max_favorable_excursion) FILTER (WHERE outcome IN ('take_profit', 'stop_loss', 'expired')), 0),
+	          COALESCE(AVG(max_adverse_excursion) FILTER (WHERE outcome IN ('take_profit', 'stop_loss', 'expired')), 0)
+	          FROM signals ` + where + `
+	          GROUP BY strategy, symbol, timeframe
+	          ORDER BY strategy, symbol, timeframe`
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []*model.SignalStats
+	for rows.Next() {
+		s := &model.SignalStats{}
+		err := rows.Scan(&s.Strategy, &s.Symbol, &s.Timeframe, &s.Evaluated, &s.TakeProfits, &s.StopLosses, &s.Expired, &s.Pending, &s.Unfilled, &s.Unscorable,
+			&s.Expectancy, &s.AverageR, &s.AverageTimeToOutcome, &s.AverageMaxFavorableExcursion, &s.AverageMaxAdverseExcursion)
+		if err != nil {
+			return nil, err
+		}
+		if s.Evaluated > 0 {
+			s.HitRate = float64(s.TakeProfits) / float64(s.Evaluated)
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
 }
 
 // DeleteSignal deletes a signal
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"database/sql"
+	"encoding/json"
+	"time"
 
 	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
 )
@@ -16,52 +18,67 @@ func NewTradeRepository(db *sql.DB) *TradeRepository {
 	return &TradeRepository{db: db}
 }
 
+// tradeColumns are the columns read by the trade queries, in queryTrades scan order
+const tradeColumns = `id, user_id, exchange, symbol, side, quantity, price, strategy, profit_loss, take_profit, stop_loss,
+	status, executed_at, closed_at, protection_list_id, exit_price, fees, close_reason, exit_rules`
+
 // CreateTrade creates a new trade
 func (r *TradeRepository) CreateTrade(trade *model.DBTrade) error {
-	query := `INSERT INTO trades (user_id, symbol, side, quantity, price, strategy, profit_loss, take_profit, stop_loss, status, executed_at, closed_at)
-	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id`
-	return r.db.QueryRow(query, trade.UserID, trade.Symbol, trade.Side, trade.Quantity, trade.Price, trade.Strategy, trade.ProfitLoss, trade.TakeProfit, trade.StopLoss, trade.Status, trade.ExecutedAt, trade.ClosedAt).Scan(&trade.ID)
+	rules, err := marshalExitRules(trade.ExitRules)
+	if err != nil {
+		return err
+	}
+	query := `INSERT INTO trades (user_id, exchange, symbol, side, quantity, price, strategy, profit_loss, take_profit, stop_loss, status, executed_at, closed_at,
+	          protection_list_id, exit_price, fees, close_reason, exit_rules)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18) RETURNING id`
+	return r.db.QueryRow(query, trade.UserID, trade.Exchange, trade.Symbol, trade.Side, trade.Quantity, trade.Price, trade.Strategy, trade.ProfitLoss,
+		trade.TakeProfit, trade.StopLoss, trade.Status, trade.ExecutedAt, trade.ClosedAt,
+		trade.ProtectionListID, trade.ExitPrice, trade.Fees, trade.CloseReason, rules).Scan(&trade.ID)
 }
 
 // GetTradeByID retrieves a trade by ID
 func (r *TradeRepository) GetTradeByID(id int) (*model.DBTrade, error) {
-	trade := &model.DBTrade{}
-	query := `SELECT id, user_id, symbol, side, quantity, price, strategy, profit_loss, take_profit, stop_loss, status, executed_at, closed_at
-	          FROM trades WHERE id = $1`
-	err := r.db.QueryRow(query, id).Scan(&trade.ID, &trade.UserID, &trade.Symbol, &trade.Side, &trade.Quantity, &trade.Price, &trade.Strategy, &trade.ProfitLoss, &trade.TakeProfit, &trade.StopLoss, &trade.Status, &trade.ExecutedAt, &trade.ClosedAt)
+	trades, err := r.queryTrades(`SELECT `+tradeColumns+` FROM trades WHERE id = $1`, id)
 	if err != nil {
 		return nil, err
 	}
-	return trade, nil
+	if len(trades) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return trades[0], nil
 }
 
 // GetTradesByUserID retrieves all trades for a user
 func (r *TradeRepository) GetTradesByUserID(userID int) ([]*model.DBTrade, error) {
-	query := `SELECT id, user_id, symbol, side, quantity, price, strategy, profit_loss, take_profit, stop_loss, status, executed_at, closed_at
-	          FROM trades WHERE user_id = $1 ORDER BY executed_at DESC`
-	rows, err := r.db.Query(query, userID)
+	return r.queryTrades(`SELECT `+tradeColumns+` FROM trades WHERE user_id = $1 ORDER BY executed_at DESC`, userID)
+}
+
+// GetOpenTradesByUserID retrieves open trades for a user
+func (r *TradeRepository) GetOpenTradesByUserID(userID int) ([]*model.DBTrade, error) {
+	return r.queryTrades(`SELECT `+tradeColumns+` FROM trades WHERE user_id = $1 AND status = 'OPEN' ORDER BY executed_at DESC`, userID)
+}
+
+// GetOpenTradeUserIDs retrieves the IDs of every user with at least one open trade
+func (r *TradeRepository) GetOpenTradeUserIDs() ([]int, error) {
+	rows, err := r.db.Query(`SELECT DISTINCT user_id FROM trades WHERE status = 'OPEN' ORDER BY user_id`)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var trades []*model.DBTrade
+	var ids []int
 	for rows.Next() {
-		trade := &model.DBTrade{}
-		err := rows.Scan(&trade.ID, &trade.UserID, &trade.Symbol, &trade.Side, &trade.Quantity, &trade.Price, &trade.Strategy, &trade.ProfitLoss, &trade.TakeProfit, &trade.StopLoss, &trade.Status, &trade.ExecutedAt, &trade.ClosedAt)
-		if err != nil {
+		var id int
+		if err := rows.Scan(&id); err != nil {
 			return nil, err
 		}
-		trades = append(trades, trade)
+		ids = append(ids, id)
 	}
-	return trades, nil
+	return ids, rows.Err()
 }
 
-// GetOpenTradesByUserID retrieves open trades for a user
-func (r *TradeRepository) GetOpenTradesByUserID(userID int) ([]*model.DBTrade, error) {
-	query := `SELECT id, user_id, symbol, side, quantity, price, strategy, profit_loss, take_profit, stop_loss, status, executed_at, closed_at
-	          FROM trades WHERE user_id = $1 AND status = 'OPEN' ORDER BY executed_at DESC`
-	rows, err := r.db.Query(query, userID)
+func (r *TradeRepository) queryTrades(query string, args ...interface{}) ([]*model.DBTrade, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -70,19 +87,40 @@ func (r *TradeRepository) GetOpenTradesByUserID(userID int) ([]*model.DBTrade, e
 	var trades []*model.DBTrade
 	for rows.Next() {
 		trade := &model.DBTrade{}
-		err := rows.Scan(&trade.ID, &trade.UserID, &trade.Symbol, &trade.Side, &trade.Quantity, &trade.Price, &trade.Strategy, &trade.ProfitLoss, &trade.TakeProfit, &trade.StopLoss, &trade.Status, &trade.ExecutedAt, &trade.ClosedAt)
+		var rules []byte
+		err := rows.Scan(&trade.ID, &trade.UserID, &trade.Exchange, &trade.Symbol, &trade.Side, &trade.Quantity, &trade.Price, &trade.Strategy,
+			&trade.ProfitLoss, &trade.TakeProfit, &trade.StopLoss, &trade.Status, &trade.ExecutedAt, &trade.ClosedAt,
+			&trade.ProtectionListID, &trade.ExitPrice, &trade.Fees, &trade.CloseReason, &rules)
 		if err != nil {
 			return nil, err
 		}
+		if trade.ExitRules, err = unmarshalExitRules(rules); err != nil {
+			return nil, err
+		}
 		trades = append(trades, trade)
 	}
-	return trades, nil
+	return trades, rows.Err()
+}
+
+// GetRealizedPnLSince retrieves the total profit and loss of the user's trades closed since the given time
+func (r *TradeRepository) GetRealizedPnLSince(userID int, since time.Time) (float64, error) {
+	var pnl float64
+	query := `SELECT COALESCE(SUM(profit_loss), 0) FROM trades WHERE user_id = $1 AND status = 'CLOSED' AND closed_at >= $2`
+	err := r.db.QueryRow(query, userID, since).Scan(&pnl)
+	return pnl, err
 }
 
-// UpdateTrade updates a trade
+// UpdateTrade updates a trade. The quantity and stop loss change as exit rules close parts of the position and move its stop.
 func (r *TradeRepository) UpdateTrade(trade *model.DBTrade) error {
-	query := `UPDATE trades SET profit_loss = $1, status = $2, closed_at = $3 WHERE id = $4`
-	_, err := r.db.Exec(query, trade.ProfitLoss, trade.Status, trade.ClosedAt, trade.ID)
+	rules, err := marshalExitRules(trade.ExitRules)
+	if err != nil {
+		return err
+	}
+	query := `UPDATE trades SET quantity = $1, stop_loss = $2, take_profit = $3, profit_loss = $4, status = $5, closed_at = $6, protection_list_id = $7,
+	          exit_price = $8, fees = $9, close_reason = $10, exit_rules = $11
+	          WHERE id = $12`
+	_, err = r.db.Exec(query, trade.Quantity, trade.StopLoss, trade.TakeProfit, trade.ProfitLoss, trade.Status, trade.ClosedAt, trade.ProtectionListID,
+		trade.ExitPrice, trade.Fees, trade.CloseReason, rules, trade.ID)
 	return err
 }
 
@@ -92,3 +130,26 @@ func (r *TradeRepository) DeleteTrade(id int) error {
 	_, err := r.db.Exec(query, id)
 	return err
 }
+
+// marshalExitRules stores missing exit rules as NULL
+func marshalExitRules(rules *model.ExitRules) (interface{}, error) {
+	if rules == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return nil, err
+	}
+	return nullJSON(data), nil
+}
+
+func unmarshalExitRules(data []byte) (*model.ExitRules, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	rules := &model.ExitRules{}
+	if err := json.Unmarshal(data, rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
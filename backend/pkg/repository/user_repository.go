@@ -2,63 +2,64 @@ package repository
 
 import (
 	"database/sql"
+	"fmt"
 
 	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/secrets"
 )
 
-// UserRepository handles database operations for users
+// UserRepository handles database operations for users.
+// Exchange credentials are encrypted with the keyring before they are written and decrypted when read;
+// key_version records the master key each row was encrypted under, with 0 meaning legacy plaintext.
 type UserRepository struct {
-	db *sql.DB
+	db      *sql.DB
+	keyring *secrets.Keyring
 }
 
 // NewUserRepository creates a new user repository
-func NewUserRepository(db *sql.DB) *UserRepository {
-	return &UserRepository{db: db}
+func NewUserRepository(db *sql.DB, keyring *secrets.Keyring) *UserRepository {
+	return &UserRepository{db: db, keyring: keyring}
 }
 
+// userColumns are the columns read by the user queries, in scanUser order
+const userColumns = `id, username, email, password_hash, binance_api_key, binance_secret_key, solana_private_key, key_version, created_at, updated_at`
+
 // CreateUser creates a new user
 func (r *UserRepository) CreateUser(user *model.User) error {
-	query := `INSERT INTO users (username, email, password_hash, binance_api_key, binance_secret_key, solana_private_key, created_at, updated_at)
-	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`
-	return r.db.QueryRow(query, user.Username, user.Email, user.PasswordHash, user.BinanceAPIKey, user.BinanceSecretKey, user.SolanaPrivateKey, user.CreatedAt, user.UpdatedAt).Scan(&user.ID)
+	creds, version, err := r.encryptCredentials(user)
+	if err != nil {
+		return err
+	}
+	query := `INSERT INTO users (username, email, password_hash, binance_api_key, binance_secret_key, solana_private_key, key_version, created_at, updated_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`
+	return r.db.QueryRow(query, user.Username, user.Email, user.PasswordHash, creds[0], creds[1], creds[2], version, user.CreatedAt, user.UpdatedAt).Scan(&user.ID)
 }
 
 // GetUserByID retrieves a user by ID
 func (r *UserRepository) GetUserByID(id int) (*model.User, error) {
-	user := &model.User{}
-	query := `SELECT id, username, email, password_hash, binance_api_key, binance_secret_key, solana_private_key, created_at, updated_at FROM users WHERE id = $1`
-	err := r.db.QueryRow(query, id).Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.BinanceAPIKey, &user.BinanceSecretKey, &user.SolanaPrivateKey, &user.CreatedAt, &user.UpdatedAt)
-	if err != nil {
-		return nil, err
-	}
-	return user, nil
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+	return r.scanUser(r.db.QueryRow(query, id))
 }
 
 // GetUserByUsername retrieves a user by username
 func (r *UserRepository) GetUserByUsername(username string) (*model.User, error) {
-	user := &model.User{}
-	query := `SELECT id, username, email, password_hash, binance_api_key, binance_secret_key, solana_private_key, created_at, updated_at FROM users WHERE username = $1`
-	err := r.db.QueryRow(query, username).Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.BinanceAPIKey, &user.BinanceSecretKey, &user.SolanaPrivateKey, &user.CreatedAt, &user.UpdatedAt)
-	if err != nil {
-		return nil, err
-	}
-	return user, nil
+	query := `SELECT ` + userColumns + ` FROM users WHERE username = $1`
+	return r.scanUser(r.db.QueryRow(query, username))
 }
 
 func (r *UserRepository) GetUserByEmail(email string) (*model.User, error) {
-	user := &model.User{}
-	query := `SELECT id, username, email, password_hash, binance_api_key, binance_secret_key, solana_private_key, created_at, updated_at FROM users WHERE email = $1`
-	err := r.db.QueryRow(query, email).Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.BinanceAPIKey, &user.BinanceSecretKey, &user.SolanaPrivateKey, &user.CreatedAt, &user.UpdatedAt)
-	if err != nil {
-		return nil, err
-	}
-	return user, nil
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = $1`
+	return r.scanUser(r.db.QueryRow(query, email))
 }
 
 // UpdateUser updates a user
 func (r *UserRepository) UpdateUser(user *model.User) error {
-	query := `UPDATE users SET username = $1, email = $2, password_hash = $3, binance_api_key = $4, binance_secret_key = $5, solana_private_key = $6, updated_at = $7 WHERE id = $8`
-	_, err := r.db.Exec(query, user.Username, user.Email, user.PasswordHash, user.BinanceAPIKey, user.BinanceSecretKey, user.SolanaPrivateKey, user.UpdatedAt, user.ID)
+	creds, version, err := r.encryptCredentials(user)
+	if err != nil {
+		return err
+	}
+	query := `UPDATE users SET username = $1, email = $2, password_hash = $3, binance_api_key = $4, binance_secret_key = $5, solana_private_key = $6, key_version = $7, updated_at = $8 WHERE id = $9`
+	_, err = r.db.Exec(query, user.Username, user.Email, user.PasswordHash, creds[0], creds[1], creds[2], version, user.UpdatedAt, user.ID)
 	return err
 }
 
@@ -68,3 +69,98 @@ func (r *UserRepository) DeleteUser(id int) error {
 	_, err := r.db.Exec(query, id)
 	return err
 }
+
+// ReencryptCredentials re-encrypts the credentials of every user not yet under the current master key,
+// including legacy plaintext rows, and returns the number of rows rewritten.
+// All rows are rewritten in one transaction, so a failure leaves every row as it was.
+func (r *UserRepository) ReencryptCredentials() (int, error) {
+	current := r.keyring.CurrentVersion()
+	if current == 0 {
+		return 0, secrets.ErrNoKey
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT `+userColumns+` FROM users WHERE key_version <> $1 FOR UPDATE`, current)
+	if err != nil {
+		return 0, err
+	}
+	var users []*model.User
+	for rows.Next() {
+		user, err := r.scanUser(rows)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		users = append(users, user)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, user := range users {
+		creds, version, err := r.encryptCredentials(user)
+		if err != nil {
+			return 0, fmt.Errorf("user %d: %w", user.ID, err)
+		}
+		query := `UPDATE users SET binance_api_key = $1, binance_secret_key = $2, solana_private_key = $3, key_version = $4 WHERE id = $5`
+		if _, err := tx.Exec(query, creds[0], creds[1], creds[2], version, user.ID); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(users), nil
+}
+
+// credentialColumns name the encrypted columns; the name is the associated data of each ciphertext
+var credentialColumns = [3]string{"binance_api_key", "binance_secret_key", "solana_private_key"}
+
+// encryptCredentials returns the user's credentials encrypted under the current master key, with that key's version.
+func (r *UserRepository) encryptCredentials(user *model.User) ([3]sql.NullString, int, error) {
+	var out [3]sql.NullString
+	for i, value := range [3]string{user.BinanceAPIKey, user.BinanceSecretKey, user.SolanaPrivateKey} {
+		if value == "" {
+			continue
+		}
+		sealed, err := r.keyring.Encrypt(value, credentialColumns[i])
+		if err != nil {
+			return out, 0, fmt.Errorf("failed to encrypt %s: %w", credentialColumns[i], err)
+		}
+		out[i] = sql.NullString{String: sealed, Valid: true}
+	}
+	return out, r.keyring.CurrentVersion(), nil
+}
+
+// scanUser scans a row of userColumns and decrypts the credentials
+func (r *UserRepository) scanUser(row interface{ Scan(...any) error }) (*model.User, error) {
+	user := &model.User{}
+	var creds [3]sql.NullString
+	var version int
+	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash, &creds[0], &creds[1], &creds[2], &version, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := [3]*string{&user.BinanceAPIKey, &user.BinanceSecretKey, &user.SolanaPrivateKey}
+	for i, c := range creds {
+		if !c.Valid || c.String == "" {
+			continue
+		}
+		if version == 0 {
+			*fields[i] = c.String // Legacy plaintext, encrypted by the next write or rotation
+			continue
+		}
+		*fields[i], err = r.keyring.Decrypt(c.String, version, credentialColumns[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt %s of user %d: %w", credentialColumns[i], user.ID, err)
+		}
+	}
+	return user, nil
+}
@@ -0,0 +1,487 @@
+package risk
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/exchange"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/repository"
+)
+
+var (
+	// ErrKillSwitch is returned for orders blocked by the global or the user's kill switch
+	ErrKillSwitch = errors.New("kill switch is on")
+	// ErrLimitExceeded is returned for orders that would break one of the user's risk limits
+	ErrLimitExceeded = errors.New("risk limit exceeded")
+)
+
+// Engine enforces pre-trade risk limits for every user.
+// Exchanges wrapped with Wrap ask the engine to check each order before it is placed.
+type Engine struct {
+	riskRepo  *repository.RiskRepository
+	tradeRepo *repository.TradeRepository
+
+	exchanges map[string]exchange.Exchange // Unwrapped exchanges, used to cancel orders when a kill switch is turned on
+	limits    map[int]*model.RiskLimits    // Cached limits rows by user ID; nil when the user has none of their own
+	orders    map[int][]time.Time          // Times of each user's orders in the last minute
+	symbols   map[int]map[string]bool      // Symbols each user has ordered, which make up their exposure
+	peaks     map[int]map[string]float64   // Each user's peak equity by quote asset
+	loaded    map[int]bool                 // Users whose stored symbols and peaks have been loaded
+	mu        sync.Mutex
+}
+
+// NewEngine creates a new risk engine.
+func NewEngine(riskRepo *repository.RiskRepository, tradeRepo *repository.TradeRepository) *Engine {
+	return &Engine{
+		riskRepo:  riskRepo,
+		tradeRepo: tradeRepo,
+		exchanges: make(map[string]exchange.Exchange),
+		limits:    make(map[int]*model.RiskLimits),
+		orders:    make(map[int][]time.Time),
+		symbols:   make(map[int]map[string]bool),
+		peaks:     make(map[int]map[string]float64),
+		loaded:    make(map[int]bool),
+	}
+}
+
+// Wrap returns ex guarded by the engine. The name identifies the exchange for kill switch cancellation.
+func (e *Engine) Wrap(name string, ex exchange.Exchange) exchange.Exchange {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.exchanges[name] = ex
+	return &guard{Exchange: ex, engine: e}
+}
+
+// Limits returns the limits in force for a user, with the global defaults filled in, and the user's own row if any.
+func (e *Engine) Limits(userID int) (effective model.RiskLimits, own *model.RiskLimits, err error) {
+	global, err := e.load(model.GlobalRiskUserID)
+	if err != nil {
+		return model.RiskLimits{}, nil, err
+	}
+	if global == nil {
+		global = &model.RiskLimits{}
+	}
+	own, err = e.load(userID)
+	if err != nil {
+		return model.RiskLimits{}, nil, err
+	}
+
+	effective = *global
+	effective.UserID = userID
+	effective.KillSwitch = false
+	if own != nil {
+		effective.KillSwitch = own.KillSwitch
+		effective.UpdatedAt = own.UpdatedAt
+		if own.MaxPositionSize > 0 {
+			effective.MaxPositionSize = own.MaxPositionSize
+		}
+		if own.MaxTotalExposure > 0 {
+			effective.MaxTotalExposure = own.MaxTotalExposure
+		}
+		if own.MaxOrdersPerMinute > 0 {
+			effective.MaxOrdersPerMinute = own.MaxOrdersPerMinute
+		}
+		if own.MaxDailyLoss > 0 {
+			effective.MaxDailyLoss = own.MaxDailyLoss
+		}
+		if own.MaxDrawdownPercent > 0 {
+			effective.MaxDrawdownPercent = own.MaxDrawdownPercent
+		}
+	}
+	return effective, own, nil
+}
+
+// GlobalKillSwitch reports whether the global kill switch is on.
+func (e *Engine) GlobalKillSwitch() (bool, error) {
+	global, err := e.load(model.GlobalRiskUserID)
+	if err != nil {
+		return false, err
+	}
+	return global != nil && global.KillSwitch, nil
+}
+
+// UpdateLimits stores the limits of a user, or the global defaults for model.GlobalRiskUserID.
+// The kill switch is left as it is; use SetKillSwitch to change it.
+func (e *Engine) UpdateLimits(limits *model.RiskLimits) error {
+	current, err := e.load(limits.UserID)
+	if err != nil {
+		return err
+	}
+	limits.KillSwitch = current != nil && current.KillSwitch
+	if err := e.riskRepo.UpsertLimits(limits); err != nil {
+		return err
+	}
+	e.invalidate(limits.UserID)
+	return nil
+}
+
+// SetKillSwitch turns the kill switch of a user, or the global one for model.GlobalRiskUserID, on or off.
+// Turning it on cancels the open orders of the user, or of every known user for the global switch,
+// protective OCOs included; the position manager then closes trades at market, which the switch lets through.
+func (e *Engine) SetKillSwitch(ctx context.Context, userID int, on bool) error {
+	if err := e.riskRepo.SetKillSwitch(userID, on); err != nil {
+		return err
+	}
+	e.invalidate(userID)
+	if !on {
+		log.Printf("Kill switch turned off for user %d", userID)
+		return nil
+	}
+
+	users := []int{userID}
+	if userID == model.GlobalRiskUserID {
+		var err error
+		if users, err = e.knownUsers(); err != nil {
+			return err
+		}
+		// Exchanges shared by all users hold their orders without a user ID
+		users = append(users, model.GlobalRiskUserID)
+	}
+	log.Printf("Kill switch turned on for user %d, cancelling open orders of %d users", userID, len(users))
+	return e.cancelOrders(ctx, users)
+}
+
+// OrdersLastMinute returns how many orders the user has placed in the last minute.
+func (e *Engine) OrdersLastMinute(userID int) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.recentOrders(userID, time.Now()))
+}
+
+// DailyPnL returns the user's realised profit and loss since midnight UTC.
+func (e *Engine) DailyPnL(userID int) (float64, error) {
+	now := time.Now().UTC()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return e.tradeRepo.GetRealizedPnLSince(userID, midnight)
+}
+
+// Check runs an order through the kill switches and the user's limits.
+// Orders are rejected when any check fails, including when the data needed for a check cannot be loaded.
+// Orders that reduce a position pass the kill switches, so trades can still be closed once their protective
+// orders have been cancelled.
+// Position, exposure, daily loss and drawdown limits only apply to buys that do not reduce a position,
+// so positions can always be reduced, including shorts, which are closed by buying back.
+func (e *Engine) Check(ctx context.Context, ex exchange.Exchange, symbol, side string, quantity, price float64, reduces bool) error {
+	userID := exchange.UserIDFromContext(ctx)
+
+	globalKill, err := e.GlobalKillSwitch()
+	if err != nil {
+		return fmt.Errorf("failed to load risk limits: %w", err)
+	}
+	if globalKill && !reduces {
+		return fmt.Errorf("%w: trading is halted for all users", ErrKillSwitch)
+	}
+	limits, _, err := e.Limits(userID)
+	if err != nil {
+		return fmt.Errorf("failed to load risk limits: %w", err)
+	}
+	if limits.KillSwitch && !reduces {
+		return fmt.Errorf("%w: trading is halted for this account", ErrKillSwitch)
+	}
+
+	now := time.Now()
+	e.mu.Lock()
+	recent := len(e.recentOrders(userID, now))
+	e.mu.Unlock()
+	if limits.MaxOrdersPerMinute > 0 && recent >= limits.MaxOrdersPerMinute {
+		return fmt.Errorf("%w: %d orders in the last minute, limit is %d", ErrLimitExceeded, recent, limits.MaxOrdersPerMinute)
+	}
+
+	if side == "BUY" && !reduces {
+		if err := e.checkBuy(ctx, ex, userID, limits, symbol, quantity, price); err != nil {
+			return err
+		}
+	}
+
+	// Count the order under the same lock as the final rate check, so concurrent orders cannot overshoot it
+	added, err := e.record(userID, symbol, now, limits.MaxOrdersPerMinute)
+	if err != nil {
+		return err
+	}
+	if added {
+		if err := e.riskRepo.AddSymbol(userID, symbol); err != nil {
+			log.Printf("Error storing symbol %s of user %d for the risk engine: %v", symbol, userID, err)
+		}
+	}
+	return nil
+}
+
+// checkBuy enforces the limits on orders that add to the user's holdings.
+func (e *Engine) checkBuy(ctx context.Context, ex exchange.Exchange, userID int, limits model.RiskLimits, symbol string, quantity, price float64) error {
+	if limits.MaxDailyLoss > 0 {
+		pnl, err := e.DailyPnL(userID)
+		if err != nil {
+			return fmt.Errorf("failed to load daily profit and loss: %w", err)
+		}
+		if -pnl >= limits.MaxDailyLoss {
+			return fmt.Errorf("%w: realised loss today is %.2f, limit is %.2f", ErrLimitExceeded, -pnl, limits.MaxDailyLoss)
+		}
+	}
+
+	if limits.MaxPositionSize <= 0 && limits.MaxTotalExposure <= 0 && limits.MaxDrawdownPercent <= 0 {
+		return nil
+	}
+	base, quote, err := exchange.SplitSymbol(symbol)
+	if err != nil {
+		return err
+	}
+	if err := e.loadState(userID); err != nil {
+		return fmt.Errorf("failed to load risk state: %w", err)
+	}
+	holdings, err := e.holdings(ctx, ex, userID, symbol, quote)
+	if err != nil {
+		return fmt.Errorf("failed to value holdings: %w", err)
+	}
+	notional := quantity * price
+
+	if limits.MaxPositionSize > 0 && holdings[base]+notional > limits.MaxPositionSize {
+		return fmt.Errorf("%w: %s position would be %.2f %s, limit is %.2f", ErrLimitExceeded, base, holdings[base]+notional, quote, limits.MaxPositionSize)
+	}
+
+	var exposure float64
+	for _, value := range holdings {
+		exposure += value
+	}
+	if limits.MaxTotalExposure > 0 && exposure+notional > limits.MaxTotalExposure {
+		return fmt.Errorf("%w: exposure would be %.2f %s, limit is %.2f", ErrLimitExceeded, exposure+notional, quote, limits.MaxTotalExposure)
+	}
+
+	if limits.MaxDrawdownPercent > 0 {
+		cash, err := totalBalance(ctx, ex, quote)
+		if err != nil {
+			return fmt.Errorf("failed to get %s balance: %w", quote, err)
+		}
+		drawdown := e.drawdown(userID, quote, cash+exposure)
+		if drawdown >= limits.MaxDrawdownPercent {
+			return fmt.Errorf("%w: equity is %.2f%% below its peak, limit is %.2f%%", ErrLimitExceeded, drawdown, limits.MaxDrawdownPercent)
+		}
+	}
+	return nil
+}
+
+// holdings values the user's base assets in quote, for every symbol they have ordered with that quote asset.
+func (e *Engine) holdings(ctx context.Context, ex exchange.Exchange, userID int, symbol, quote string) (map[string]float64, error) {
+	e.mu.Lock()
+	symbols := []string{symbol}
+	for s := range e.symbols[userID] {
+		if s != symbol {
+			symbols = append(symbols, s)
+		}
+	}
+	e.mu.Unlock()
+
+	holdings := make(map[string]float64)
+	for _, s := range symbols {
+		base, q, err := exchange.SplitSymbol(s)
+		if err != nil || q != quote {
+			continue
+		}
+		if _, ok := holdings[base]; ok {
+			continue
+		}
+		amount, err := totalBalance(ctx, ex, base)
+		if err != nil {
+			return nil, err
+		}
+		if amount == 0 {
+			holdings[base] = 0
+			continue
+		}
+		price, err := ex.GetPrice(ctx, s)
+		if err != nil {
+			return nil, err
+		}
+		holdings[base] = amount * price
+	}
+	return holdings, nil
+}
+
+// drawdown records equity and returns how far it is below the user's peak, in percent.
+// A new peak is stored, so that it outlives a restart.
+func (e *Engine) drawdown(userID int, quote string, equity float64) float64 {
+	e.mu.Lock()
+	if e.peaks[userID] == nil {
+		e.peaks[userID] = make(map[string]float64)
+	}
+	peak := e.peaks[userID][quote]
+	if equity < peak {
+		e.mu.Unlock()
+		return (peak - equity) / peak * 100
+	}
+	e.peaks[userID][quote] = equity
+	e.mu.Unlock()
+
+	if equity > peak {
+		if err := e.riskRepo.SetPeak(userID, quote, equity); err != nil {
+			log.Printf("Error storing peak %s equity of user %d: %v", quote, userID, err)
+		}
+	}
+	return 0
+}
+
+// loadState loads the stored peaks and symbols of a user once, and adds the symbols of their open trades,
+// which count towards exposure even if they were ordered before the symbols were stored.
+func (e *Engine) loadState(userID int) error {
+	e.mu.Lock()
+	loaded := e.loaded[userID]
+	e.mu.Unlock()
+	if loaded {
+		return nil
+	}
+
+	peaks, symbols, err := e.riskRepo.GetState(userID)
+	if err != nil {
+		return err
+	}
+	trades, err := e.tradeRepo.GetOpenTradesByUserID(userID)
+	if err != nil {
+		return err
+	}
+	for _, t := range trades {
+		symbols = append(symbols, t.Symbol)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.loaded[userID] {
+		return nil
+	}
+	if e.symbols[userID] == nil {
+		e.symbols[userID] = make(map[string]bool)
+	}
+	for _, s := range symbols {
+		e.symbols[userID][s] = true
+	}
+	if e.peaks[userID] == nil {
+		e.peaks[userID] = make(map[string]float64)
+	}
+	for quote, peak := range peaks {
+		e.peaks[userID][quote] = max(e.peaks[userID][quote], peak)
+	}
+	e.loaded[userID] = true
+	return nil
+}
+
+// record counts an accepted order towards the user's rate limit and exposure, unless the rate limit has been reached.
+// It reports whether the symbol is new to the user's exposure.
+func (e *Engine) record(userID int, symbol string, at time.Time, maxPerMinute int) (bool, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	recent := e.recentOrders(userID, at)
+	if maxPerMinute > 0 && len(recent) >= maxPerMinute {
+		return false, fmt.Errorf("%w: %d orders in the last minute, limit is %d", ErrLimitExceeded, len(recent), maxPerMinute)
+	}
+	e.orders[userID] = append(recent, at)
+	if e.symbols[userID] == nil {
+		e.symbols[userID] = make(map[string]bool)
+	}
+	added := !e.symbols[userID][symbol]
+	e.symbols[userID][symbol] = true
+	return added, nil
+}
+
+// recentOrders drops order times older than a minute and returns the rest. The caller must hold e.mu.
+func (e *Engine) recentOrders(userID int, now time.Time) []time.Time {
+	times := e.orders[userID]
+	i := 0
+	for i < len(times) && now.Sub(times[i]) >= time.Minute {
+		i++
+	}
+	e.orders[userID] = times[i:]
+	return e.orders[userID]
+}
+
+// load returns the limits row of a user, or nil if there is none, caching the result.
+func (e *Engine) load(userID int) (*model.RiskLimits, error) {
+	e.mu.Lock()
+	limits, ok := e.limits[userID]
+	e.mu.Unlock()
+	if ok {
+		return limits, nil
+	}
+
+	limits, err := e.riskRepo.GetLimits(userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		limits, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	e.limits[userID] = limits
+	e.mu.Unlock()
+	return limits, nil
+}
+
+func (e *Engine) invalidate(userID int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.limits, userID)
+}
+
+// knownUsers returns every user with a risk limits row, which is stored with their first order, or orders placed since the server started.
+func (e *Engine) knownUsers() ([]int, error) {
+	ids, err := e.riskRepo.GetUserIDs()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[int]bool)
+	for _, id := range ids {
+		seen[id] = true
+	}
+	e.mu.Lock()
+	for id := range e.symbols {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	e.mu.Unlock()
+	return ids, nil
+}
+
+// cancelOrders cancels the open orders of users on every exchange that supports it.
+func (e *Engine) cancelOrders(ctx context.Context, users []int) error {
+	e.mu.Lock()
+	exchanges := make(map[string]exchange.Exchange, len(e.exchanges))
+	for name, ex := range e.exchanges {
+		exchanges[name] = ex
+	}
+	e.mu.Unlock()
+
+	var errs []error
+	for name, ex := range exchanges {
+		canceller, ok := ex.(exchange.OrderCanceller)
+		if !ok {
+			continue
+		}
+		for _, userID := range users {
+			if err := canceller.CancelAllOrders(exchange.WithUserID(ctx, userID)); err != nil {
+				errs = append(errs, fmt.Errorf("failed to cancel orders of user %d on %s: %w", userID, name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// totalBalance returns the free and locked balance of an asset.
+func totalBalance(ctx context.Context, ex exchange.Exchange, asset string) (float64, error) {
+	free, err := ex.GetBalance(ctx, asset)
+	if err != nil {
+		return 0, err
+	}
+	if lb, ok := ex.(exchange.LockedBalancer); ok {
+		locked, err := lb.GetLockedBalance(ctx, asset)
+		if err != nil {
+			return 0, err
+		}
+		free += locked
+	}
+	return free, nil
+}
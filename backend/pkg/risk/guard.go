@@ -0,0 +1,49 @@
+package risk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/exchange"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+)
+
+// guard wraps an exchange so that every order is checked by the risk engine before it is placed.
+// All other calls go straight to the wrapped exchange.
+type guard struct {
+	exchange.Exchange
+	engine *Engine
+}
+
+// PlaceOrder places the order only if it passes the engine's checks.
+// Market orders are checked at the current price, and reduce-only orders as closing a position.
+func (g *guard) PlaceOrder(ctx context.Context, req exchange.OrderRequest) (*model.Order, error) {
+	price := req.Price
+	if strings.EqualFold(req.Type, exchange.OrderTypeMarket) {
+		current, err := g.Exchange.GetPrice(ctx, req.Symbol)
+		if err != nil {
+			return nil, fmt.Errorf("failed to price market order: %w", err)
+		}
+		price = current
+	}
+	if err := g.engine.Check(ctx, g.Exchange, req.Symbol, strings.ToUpper(req.Side), req.Quantity, price, req.ReduceOnly); err != nil {
+		return nil, err
+	}
+	return g.Exchange.PlaceOrder(ctx, req)
+}
+
+// PlaceOCO places the bracket only if it passes the engine's checks; it counts as a single order,
+// valued at the higher of its two limit prices.
+func (g *guard) PlaceOCO(ctx context.Context, req exchange.OCORequest) ([]*model.Order, error) {
+	price := max(req.Price, req.StopLimitPrice)
+	if err := g.engine.Check(ctx, g.Exchange, req.Symbol, strings.ToUpper(req.Side), req.Quantity, price, false); err != nil {
+		return nil, err
+	}
+	return g.Exchange.PlaceOCO(ctx, req)
+}
+
+// Unwrap returns the guarded exchange
+func (g *guard) Unwrap() exchange.Exchange {
+	return g.Exchange
+}
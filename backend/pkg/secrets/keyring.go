@@ -0,0 +1,174 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// KeySize is the length in bytes of master and data keys (AES-256).
+const KeySize = 32
+
+// wrappedKeySize is the length of an encrypted data key: nonce, key and GCM tag.
+const wrappedKeySize = 12 + KeySize + 16
+
+// ErrNoKey is returned when a secret cannot be encrypted or decrypted because its master key is not configured.
+var ErrNoKey = errors.New("encryption key not configured")
+
+// Keyring holds the versioned master keys used to encrypt secrets at rest.
+// Secrets are envelope encrypted: each value gets a random data key, the value is sealed with
+// AES-GCM under the data key, and the data key is sealed under the current master key.
+// Older versions are kept so values written before a rotation can still be read.
+type Keyring struct {
+	keys    map[int][]byte
+	current int
+}
+
+// NewKeyring creates a keyring from master keys by version; the highest version is used for new values.
+func NewKeyring(keys map[int][]byte) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, ErrNoKey
+	}
+	k := &Keyring{keys: make(map[int][]byte, len(keys))}
+	for version, key := range keys {
+		if version <= 0 {
+			return nil, fmt.Errorf("key version must be positive, got %d", version)
+		}
+		if len(key) != KeySize {
+			return nil, fmt.Errorf("key version %d must be %d bytes, got %d", version, KeySize, len(key))
+		}
+		k.keys[version] = key
+		if version > k.current {
+			k.current = version
+		}
+	}
+	return k, nil
+}
+
+// ParseKeys parses master keys in the form "1:BASE64KEY,2:BASE64KEY".
+// Entries may also be separated by newlines, so a key file can list one version per line.
+func ParseKeys(s string) (map[int][]byte, error) {
+	keys := make(map[int][]byte)
+	entries := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '\n' || r == '\r' })
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		v, encoded, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid key entry, expected VERSION:BASE64KEY")
+		}
+		version, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return nil, fmt.Errorf("invalid key version %q", v)
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 for key version %d: %w", version, err)
+		}
+		if _, dup := keys[version]; dup {
+			return nil, fmt.Errorf("duplicate key version %d", version)
+		}
+		keys[version] = key
+	}
+	return keys, nil
+}
+
+// CurrentVersion returns the version of the master key used to encrypt new values.
+func (k *Keyring) CurrentVersion() int {
+	if k == nil {
+		return 0
+	}
+	return k.current
+}
+
+// Encrypt seals plaintext under the current master key and returns it base64 encoded.
+// The associated data binds the ciphertext to where it is stored, so it cannot be moved to another field.
+func (k *Keyring) Encrypt(plaintext, associatedData string) (string, error) {
+	if k == nil {
+		return "", ErrNoKey
+	}
+	dataKey := make([]byte, KeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+	wrapped, err := seal(k.keys[k.current], dataKey, associatedData)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := seal(dataKey, []byte(plaintext), associatedData)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(append(wrapped, sealed...)), nil
+}
+
+// Decrypt opens a value produced by Encrypt under the master key of the given version.
+func (k *Keyring) Decrypt(ciphertext string, version int, associatedData string) (string, error) {
+	if k == nil {
+		return "", ErrNoKey
+	}
+	master, ok := k.keys[version]
+	if !ok {
+		return "", fmt.Errorf("key version %d: %w", version, ErrNoKey)
+	}
+	blob, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("invalid ciphertext: %w", err)
+	}
+	if len(blob) < wrappedKeySize {
+		return "", fmt.Errorf("invalid ciphertext: too short")
+	}
+	dataKey, err := open(master, blob[:wrappedKeySize], associatedData)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(dataKey, blob[wrappedKeySize:], associatedData)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// seal encrypts data with AES-GCM under key, prefixing the random nonce.
+func seal(key, data []byte, associatedData string) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, data, []byte(associatedData)), nil
+}
+
+// open reverses seal.
+func open(key, data []byte, associatedData string) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid ciphertext: too short")
+	}
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], []byte(associatedData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
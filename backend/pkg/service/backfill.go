@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/repository"
+)
+
+// CandleGap is a range [Start, End) for which no candles are stored.
+type CandleGap struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// BackfillResult summarises a backfill run.
+type BackfillResult struct {
+	Symbol        string      `json:"symbol"`
+	Interval      string      `json:"interval"`
+	GapsFound     []CandleGap `json:"gaps_found"`
+	CandlesStored int         `json:"candles_stored"`
+	GapsRemaining []CandleGap `json:"gaps_remaining"` // Typically ranges with no trading, e.g. before the symbol was listed
+}
+
+// backfillChunk is the number of candles fetched and saved per step, so long backfills make progress incrementally.
+const backfillChunk = 1000
+
+// BackfillService populates the candle store with historical klines and repairs gaps in it.
+type BackfillService struct {
+	fetcherSvc *FetcherService
+	candleRepo *repository.CandleRepository
+}
+
+// NewBackfillService creates a new backfill service.
+func NewBackfillService(fetcher *FetcherService, candleRepo *repository.CandleRepository) *BackfillService {
+	return &BackfillService{
+		fetcherSvc: fetcher,
+		candleRepo: candleRepo,
+	}
+}
+
+// FindGaps returns the ranges within [start, end) that have no stored candles.
+func (s *BackfillService) FindGaps(symbol, interval string, start, end time.Time) ([]CandleGap, error) {
+	step, err := model.IntervalDuration(interval)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := s.candleRepo.GetCandles(symbol, interval, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return findGaps(stored, step, start, end), nil
+}
+
+// Backfill pages through Binance klines for every gap in [start, end) and saves them to the store.
+// Already stored candles are not fetched again, so running it over an existing range repairs gaps only.
+func (s *BackfillService) Backfill(ctx context.Context, symbol, interval string, start, end time.Time) (*BackfillResult, error) {
+	step, err := model.IntervalDuration(interval)
+	if err != nil {
+		return nil, err
+	}
+	if now := time.Now(); end.After(now) {
+		end = now
+	}
+	if !end.After(start) {
+		return nil, fmt.Errorf("backfill end must be after its start")
+	}
+
+	gaps, err := s.FindGaps(symbol, interval, start, end)
+	if err != nil {
+		return nil, err
+	}
+	result := &BackfillResult{Symbol: symbol, Interval: interval, GapsFound: gaps}
+
+	for _, gap := range gaps {
+		for from := gap.Start; from.Before(gap.End); from = from.Add(backfillChunk * step) {
+			to := from.Add(backfillChunk * step)
+			if to.After(gap.End) {
+				to = gap.End
+			}
+			candles, err := s.fetcherSvc.fetchRange(ctx, symbol, interval, from, to)
+			if err != nil {
+				return result, err
+			}
+			if err := s.candleRepo.UpsertCandles(closedCandles(candles)); err != nil {
+				return result, err
+			}
+			result.CandlesStored += len(candles)
+		}
+	}
+
+	result.GapsRemaining, err = s.FindGaps(symbol, interval, start, end)
+	if err != nil {
+		return result, err
+	}
+	log.Printf("Backfilled %d candles for %s [%s]; %d of %d gaps remain", result.CandlesStored, symbol, interval, len(result.GapsRemaining), len(result.GapsFound))
+	return result, nil
+}
+
+// findGaps returns the ranges within [start, end) not covered by the chronological candles.
+// Expected open times are aligned to multiples of step, as Binance aligns its klines.
+func findGaps(candles []model.Candle, step time.Duration, start, end time.Time) []CandleGap {
+	var gaps []CandleGap
+	expected := start.Truncate(step)
+	if expected.Before(start) {
+		expected = expected.Add(step)
+	}
+	for _, c := range candles {
+		if c.OpenTime.After(expected) {
+			gaps = append(gaps, CandleGap{Start: expected, End: c.OpenTime})
+		}
+		if next := c.OpenTime.Add(step); next.After(expected) {
+			expected = next
+		}
+	}
+	// The candle still in progress is never stored, so it only counts as a gap when nothing is stored at all.
+	if expected.Before(end) && (expected.Add(step).Before(time.Now()) || len(candles) == 0) {
+		gaps = append(gaps, CandleGap{Start: expected, End: end})
+	}
+	return gaps
+}
+
+// closedCandles filters out the candle that is still in progress.
+func closedCandles(candles []model.Candle) []model.Candle {
+	now := time.Now()
+	closed := make([]model.Candle, 0, len(candles))
+	for _, c := range candles {
+		if c.CloseTime.Before(now) {
+			closed = append(closed, c)
+		}
+	}
+	return closed
+}
+
+// sortCandles sorts candles oldest to newest.
+func sortCandles(candles []model.Candle) {
+	sort.Slice(candles, func(i, j int) bool { return candles[i].OpenTime.Before(candles[j].OpenTime) })
+}
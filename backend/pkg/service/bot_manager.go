@@ -0,0 +1,375 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/exchange"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/marketdata"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/repository"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/strategy"
+)
+
+// botTickInterval is how often a running bot passes the latest live price to its strategy.
+const botTickInterval = 5 * time.Second
+
+// Backoff between attempts to start a bot whose orders or market data could not be reached
+const (
+	botMinRetry = time.Second
+	botMaxRetry = time.Minute
+)
+
+// runningBot pairs a bot with the runner driving it and the function that stops it.
+type runningBot struct {
+	bot    model.Bot
+	runner *strategy.Runner
+	cancel context.CancelFunc
+}
+
+// BotManager starts, stops and tracks the strategy bots of all users.
+// Each bot runs its strategy through a strategy.Runner, so the same code is used
+// for live and paper trading as for backtesting. Bots and the state of stateful
+// strategies are persisted, so running bots resume after a restart; paper bots do not,
+// as the paper accounts they trade are not persisted.
+type BotManager struct {
+	exchanges  map[string]exchange.Exchange
+	clients    *exchange.ClientFactory
+	strategies map[string]strategy.Factory
+	botRepo    *repository.BotRepository
+	hub        *marketdata.Hub
+
+	bots map[int]*runningBot
+	mu   sync.Mutex
+}
+
+// NewBotManager creates a new bot manager.
+// Fills from the paper exchange are routed to the bot that placed the order.
+// Bots receive prices and closed candles from the market data hub.
+func NewBotManager(exchanges map[string]exchange.Exchange, clients *exchange.ClientFactory, strategies map[string]strategy.Factory, botRepo *repository.BotRepository, hub *marketdata.Hub) *BotManager {
+	m := &BotManager{
+		exchanges:  exchanges,
+		clients:    clients,
+		strategies: strategies,
+		botRepo:    botRepo,
+		hub:        hub,
+		bots:       make(map[int]*runningBot),
+	}
+	if paper, ok := exchange.Unwrap(exchanges["paper"]).(*exchange.PaperExchange); ok {
+		paper.OnFill(m.routePaperFill)
+	}
+	return m
+}
+
+// StartBot initialises the named strategy with params and starts trading symbol for the user.
+func (m *BotManager) StartBot(userID int, strategyName, symbol, exchangeName, interval string, params json.RawMessage) (*model.Bot, error) {
+	bot := model.Bot{
+		UserID:   userID,
+		Strategy: strategyName,
+		Symbol:   symbol,
+		Exchange: exchangeName,
+		Interval: interval,
+		Params:   params,
+		Status:   model.BotRunning,
+	}
+	strat, err := m.newStrategy(bot)
+	if err != nil {
+		return nil, err
+	}
+	// Orders are placed with the user's own keys, so refuse to start without them
+	if err := m.clients.CheckAccess(userID, exchangeName); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, rb := range m.bots {
+		if rb.bot.UserID == userID && rb.bot.Symbol == symbol && rb.bot.Exchange == exchangeName {
+			return nil, fmt.Errorf("a bot for %s on %s is already running", symbol, exchangeName)
+		}
+	}
+	if err := m.botRepo.CreateBot(&bot); err != nil {
+		return nil, fmt.Errorf("failed to save bot: %w", err)
+	}
+
+	m.launch(bot, strat)
+	log.Printf("Started %s bot %d for user %d on %s (%s)", strategyName, bot.ID, userID, symbol, exchangeName)
+	return &bot, nil
+}
+
+// ResumeBots restarts every bot that was running when the application stopped,
+// restoring the saved state of stateful strategies. Paper bots are stopped instead.
+func (m *BotManager) ResumeBots() error {
+	bots, err := m.botRepo.GetBotsByStatus(model.BotRunning)
+	if err != nil {
+		return fmt.Errorf("failed to load running bots: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, bot := range bots {
+		if _, ok := m.bots[bot.ID]; ok {
+			continue
+		}
+		if _, paper := exchange.Unwrap(m.exchanges[bot.Exchange]).(*exchange.PaperExchange); paper {
+			m.stopPaperBot(bot)
+			continue
+		}
+		strat, err := m.newStrategy(*bot)
+		if err != nil {
+			log.Printf("Cannot resume bot %d: %v", bot.ID, err)
+			continue
+		}
+		m.launch(*bot, strat)
+		log.Printf("Resumed %s bot %d for user %d on %s (%s)", bot.Strategy, bot.ID, bot.UserID, bot.Symbol, bot.Exchange)
+	}
+	return nil
+}
+
+// stopPaperBot marks a paper bot that was running before a restart as stopped and clears its strategy state.
+// Paper balances, orders and fills are kept in memory only, so the state would refer to orders and holdings
+// that no longer exist.
+func (m *BotManager) stopPaperBot(bot *model.Bot) {
+	if err := m.botRepo.UpdateBotState(bot.ID, nil); err != nil {
+		log.Printf("Bot %d: error clearing paper state: %v", bot.ID, err)
+	}
+	if err := m.botRepo.UpdateBotStatus(bot.ID, model.BotStopped); err != nil {
+		log.Printf("Bot %d: error saving stopped status: %v", bot.ID, err)
+	}
+	log.Printf("Not resuming paper bot %d for user %d on %s: paper accounts do not survive a restart", bot.ID, bot.UserID, bot.Symbol)
+}
+
+// StopAll stops every running bot without marking it stopped, so it resumes on the next start.
+func (m *BotManager) StopAll() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, rb := range m.bots {
+		rb.cancel()
+		delete(m.bots, id)
+	}
+}
+
+// StopBot stops one of the user's bots.
+func (m *BotManager) StopBot(userID, botID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rb, ok := m.bots[botID]
+	if !ok || rb.bot.UserID != userID {
+		return fmt.Errorf("no bot %d is running", botID)
+	}
+	rb.cancel()
+	delete(m.bots, botID)
+	if err := m.botRepo.UpdateBotStatus(botID, model.BotStopped); err != nil {
+		log.Printf("Bot %d: error saving stopped status: %v", botID, err)
+	}
+	return nil
+}
+
+// GetBot returns one of the user's running bots including its latest strategy state.
+func (m *BotManager) GetBot(userID, botID int) (*model.Bot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rb, ok := m.bots[botID]
+	if !ok || rb.bot.UserID != userID {
+		return nil, fmt.Errorf("no bot %d is running", botID)
+	}
+	bot := rb.bot
+	return &bot, nil
+}
+
+// ListBots returns the user's running bots, oldest first. Strategy state is left out; use GetBot for it.
+func (m *BotManager) ListBots(userID int) []model.Bot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	bots := []model.Bot{}
+	for _, rb := range m.bots {
+		if rb.bot.UserID == userID {
+			bot := rb.bot
+			bot.State = nil
+			bots = append(bots, bot)
+		}
+	}
+	sort.Slice(bots, func(i, j int) bool { return bots[i].ID < bots[j].ID })
+	return bots
+}
+
+// run feeds live prices and closed candles to the bot's strategy until ctx is cancelled.
+// Closed candles are passed on as soon as the hub reports them; the latest price is passed every tick.
+// The strategy's state is saved after each of them and once more when the bot stops.
+// A bot resumed from saved state first looks up its orders still resting on the exchange, so they are not placed twice.
+func (m *BotManager) run(ctx context.Context, rb *runningBot, resumed bool) {
+	ex := m.exchanges[rb.bot.Exchange]
+	trades, klines, ok := m.connect(ctx, rb, resumed)
+	if !ok {
+		return
+	}
+	defer trades.Close()
+	defer klines.Close()
+
+	ticker := time.NewTicker(botTickInterval)
+	defer ticker.Stop()
+	defer m.saveState(rb)
+
+	var price float64
+	var lastCandle time.Time
+	for {
+		select {
+		case e, ok := <-trades.C:
+			if !ok {
+				return
+			}
+			price = e.Price
+		case e, ok := <-klines.C:
+			if !ok {
+				return
+			}
+			if e.Closed && e.Candle.OpenTime.After(lastCandle) {
+				lastCandle = e.Candle.OpenTime
+				m.report(rb, rb.runner.HandleCandle(ctx, *e.Candle))
+				m.report(rb, rb.runner.Drain(ctx))
+				m.saveState(rb)
+			}
+		case <-ticker.C:
+			if price == 0 {
+				continue
+			}
+			// The paper exchange matches resting orders on the prices it is asked for
+			if paper, ok := exchange.Unwrap(ex).(*exchange.PaperExchange); ok {
+				paper.MatchRange(rb.bot.Symbol, price, price)
+			}
+			m.report(rb, rb.runner.Sync(ctx))
+			m.report(rb, rb.runner.Drain(ctx))
+			m.report(rb, rb.runner.HandleTick(ctx, strategy.Tick{Price: price, Time: time.Now()}))
+			m.report(rb, rb.runner.Drain(ctx))
+			m.saveState(rb)
+		case <-ctx.Done():
+			log.Printf("Stopped bot %d for user %d on %s", rb.bot.ID, rb.bot.UserID, rb.bot.Symbol)
+			return
+		}
+	}
+}
+
+// connect prepares a bot to run, retrying with exponential backoff until it succeeds or ctx is cancelled,
+// so that a transient error at startup does not leave a bot marked running that never trades.
+// It reports false only when ctx was cancelled first.
+func (m *BotManager) connect(ctx context.Context, rb *runningBot, resumed bool) (trades, klines *marketdata.Subscription, ok bool) {
+	backoff := botMinRetry
+	for {
+		var err error
+		if trades, klines, err = m.subscribe(ctx, rb, resumed); err == nil {
+			return trades, klines, true
+		}
+		log.Printf("Bot %d: %v; retrying in %s", rb.bot.ID, err, backoff)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil, nil, false
+		}
+		backoff = min(backoff*2, botMaxRetry)
+	}
+}
+
+// subscribe looks up the resting orders of a resumed bot and subscribes to its trades and candles.
+func (m *BotManager) subscribe(ctx context.Context, rb *runningBot, resumed bool) (trades, klines *marketdata.Subscription, err error) {
+	if resumed {
+		if err := rb.runner.Resume(ctx); err != nil {
+			return nil, nil, fmt.Errorf("error resuming %s orders: %w", rb.bot.Symbol, err)
+		}
+	}
+	trades, err = m.hub.Subscribe(marketdata.Stream{Symbol: rb.bot.Symbol, Kind: marketdata.KindTrade})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error subscribing to %s trades: %w", rb.bot.Symbol, err)
+	}
+	klines, err = m.hub.Subscribe(marketdata.Stream{Symbol: rb.bot.Symbol, Kind: marketdata.KindKline, Interval: rb.bot.Interval})
+	if err != nil {
+		trades.Close()
+		return nil, nil, fmt.Errorf("error subscribing to %s %s candles: %w", rb.bot.Symbol, rb.bot.Interval, err)
+	}
+	return trades, klines, nil
+}
+
+// routePaperFill passes a paper fill to every running paper bot of the same user.
+func (m *BotManager) routePaperFill(f exchange.PaperFill) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, rb := range m.bots {
+		if rb.bot.Exchange == "paper" && rb.bot.UserID == f.UserID {
+			rb.runner.NotifyPaperFill(f)
+		}
+	}
+}
+
+// newStrategy creates and initialises the strategy of bot, restoring its saved state if it has any.
+func (m *BotManager) newStrategy(bot model.Bot) (strategy.Strategy, error) {
+	factory, ok := m.strategies[bot.Strategy]
+	if !ok {
+		return nil, fmt.Errorf("strategy %s not found", bot.Strategy)
+	}
+	if _, ok := m.exchanges[bot.Exchange]; !ok {
+		return nil, fmt.Errorf("exchange %s not found", bot.Exchange)
+	}
+	if _, err := model.IntervalDuration(bot.Interval); err != nil {
+		return nil, err
+	}
+
+	strat := factory()
+	if err := strat.Init(bot.Params); err != nil {
+		return nil, err
+	}
+	if stateful, ok := strat.(strategy.Stateful); ok && len(bot.State) > 0 {
+		if err := stateful.Restore(bot.State); err != nil {
+			return nil, err
+		}
+	}
+	return strat, nil
+}
+
+// launch registers a bot and starts its loop. The caller must hold m.mu.
+func (m *BotManager) launch(bot model.Bot, strat strategy.Strategy) {
+	ctx, cancel := context.WithCancel(exchange.WithUserID(context.Background(), bot.UserID))
+	rb := &runningBot{bot: bot, runner: strategy.NewRunner(strat, m.exchanges[bot.Exchange], bot.Symbol), cancel: cancel}
+	m.bots[bot.ID] = rb
+	go m.run(ctx, rb, len(bot.State) > 0)
+}
+
+// saveState persists the state of a stateful strategy when it has changed since the last save.
+func (m *BotManager) saveState(rb *runningBot) {
+	stateful, ok := rb.runner.Strategy().(strategy.Stateful)
+	if !ok {
+		return
+	}
+	state, err := stateful.State()
+	if err != nil {
+		m.report(rb, fmt.Errorf("failed to encode state: %w", err))
+		return
+	}
+
+	m.mu.Lock()
+	unchanged := bytes.Equal(state, rb.bot.State)
+	if !unchanged {
+		rb.bot.State = state
+	}
+	m.mu.Unlock()
+	if unchanged {
+		return
+	}
+	if err := m.botRepo.UpdateBotState(rb.bot.ID, state); err != nil {
+		m.report(rb, fmt.Errorf("failed to save state: %w", err))
+	}
+}
+
+func (m *BotManager) report(rb *runningBot, err error) {
+	if err != nil {
+		log.Printf("Bot %d (%s on %s): %v", rb.bot.ID, rb.bot.Strategy, rb.bot.Symbol, err)
+	}
+}
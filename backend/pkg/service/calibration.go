@@ -0,0 +1,210 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/config"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/repository"
+)
+
+const (
+	// Calibrations are refitted on the latest signal outcomes this often
+	calibrationRefreshInterval = 15 * time.Minute
+	// Most recent evaluated signals a calibration is fitted on
+	calibrationSampleLimit = 50000
+	calibrationMethod      = "isotonic"
+)
+
+type calibrationKey struct {
+	symbol, timeframe string
+}
+
+// CalibrationService turns the raw confidence of predictions into the probability that their signal hits its
+// take profit. It fits an isotonic regression on the outcomes of the signals the workers stored, per symbol and
+// timeframe, falling back to the timeframe across symbols and then to all signals where there are too few.
+type CalibrationService struct {
+	signalRepo *repository.SignalRepository
+	minSamples int
+
+	mu           sync.Mutex
+	fittedAt     time.Time
+	samples      []model.CalibrationSample
+	calibrations map[calibrationKey]*model.Calibration
+}
+
+// NewCalibrationService creates a calibration needing CALIBRATION_MIN_SAMPLES evaluated signals per fit.
+func NewCalibrationService(cfg *config.Config, signalRepo *repository.SignalRepository) *CalibrationService {
+	return &CalibrationService{signalRepo: signalRepo, minSamples: cfg.CalibrationMinSamples}
+}
+
+// Calibrate sets the calibrated probability of a prediction on timeframe, if it has a buy or sell signal and a calibration exists.
+// Only predictions of the default scoring profile should be calibrated, as the signals were generated with it.
+func (s *CalibrationService) Calibrate(p *model.Prediction, timeframe string) {
+	if p.Signal != "buy" && p.Signal != "sell" {
+		return
+	}
+	c := s.Calibration(p.Pair, timeframe)
+	if c == nil {
+		return
+	}
+	probability := c.Probability(p.Confidence)
+	p.Probability = &probability
+	p.Calibration = calibrationName(c)
+}
+
+// Calibration returns the calibration of a symbol and timeframe, or the fallback used for them, or nil if there are too few evaluated signals.
+func (s *CalibrationService) Calibration(symbol, timeframe string) *model.Calibration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refresh()
+	for _, key := range []calibrationKey{{symbol, timeframe}, {"", timeframe}, {"", ""}} {
+		if c, ok := s.calibrations[key]; ok {
+			return c
+		}
+	}
+	return nil
+}
+
+// Reliability compares the raw and calibrated confidence of the evaluated signals of a symbol and timeframe,
+// each of them any if empty, with how often they hit, in buckets of equal confidence width.
+// The calibrated values are in-sample, as the calibration was fitted on the same signals.
+func (s *CalibrationService) Reliability(symbol, timeframe string, buckets int) *model.ReliabilityReport {
+	c := s.Calibration(symbol, timeframe)
+	s.mu.Lock()
+	samples := s.samples
+	s.mu.Unlock()
+
+	report := &model.ReliabilityReport{Symbol: symbol, Timeframe: timeframe, Calibration: c, Buckets: make([]model.ReliabilityBucket, buckets)}
+	for i := range report.Buckets {
+		report.Buckets[i].Lower, report.Buckets[i].Upper = float64(i)/float64(buckets), float64(i+1)/float64(buckets)
+	}
+	for _, sample := range samples {
+		if (symbol != "" && sample.Symbol != symbol) || (timeframe != "" && sample.Timeframe != timeframe) {
+			continue
+		}
+		probability := sample.Confidence
+		if c != nil {
+			probability = c.Probability(sample.Confidence)
+		}
+		outcome := 0.0
+		if sample.Hit {
+			outcome = 1
+		}
+		b := &report.Buckets[min(int(sample.Confidence*float64(buckets)), buckets-1)]
+		b.Signals++
+		b.MeanConfidence += sample.Confidence
+		b.MeanProbability += probability
+		b.HitRate += outcome
+		report.Signals++
+		report.BrierRaw += (sample.Confidence - outcome) * (sample.Confidence - outcome)
+		report.BrierCalibrated += (probability - outcome) * (probability - outcome)
+	}
+	for i := range report.Buckets {
+		if b := &report.Buckets[i]; b.Signals > 0 {
+			n := float64(b.Signals)
+			b.MeanConfidence, b.MeanProbability, b.HitRate = b.MeanConfidence/n, b.MeanProbability/n, b.HitRate/n
+		}
+	}
+	if report.Signals > 0 {
+		report.BrierRaw /= float64(report.Signals)
+		report.BrierCalibrated /= float64(report.Signals)
+	}
+	return report
+}
+
+// refresh refits the calibrations once they are older than the refresh interval. A failed load is retried
+// at the next interval, keeping the previous calibrations meanwhile. The caller holds the lock.
+func (s *CalibrationService) refresh() {
+	now := time.Now()
+	if now.Sub(s.fittedAt) < calibrationRefreshInterval {
+		return
+	}
+	s.fittedAt = now
+	samples, err := s.signalRepo.GetCalibrationSamples(executionStrategy, calibrationSampleLimit)
+	if err != nil {
+		log.Printf("Error loading signal outcomes for calibration: %v", err)
+		return
+	}
+
+	groups := make(map[calibrationKey][]model.CalibrationSample)
+	for _, sample := range samples {
+		for _, key := range []calibrationKey{{sample.Symbol, sample.Timeframe}, {"", sample.Timeframe}, {"", ""}} {
+			groups[key] = append(groups[key], sample)
+		}
+	}
+	calibrations := make(map[calibrationKey]*model.Calibration)
+	for key, group := range groups {
+		if len(group) < s.minSamples {
+			continue
+		}
+		calibrations[key] = &model.Calibration{
+			Symbol:    key.symbol,
+			Timeframe: key.timeframe,
+			Method:    calibrationMethod,
+			Signals:   len(group),
+			Points:    fitIsotonic(group),
+			FittedAt:  now,
+		}
+	}
+	s.samples, s.calibrations = samples, calibrations
+}
+
+// fitIsotonic fits a non-decreasing step function of the hit rate on confidence with the pool adjacent violators
+// algorithm. Each step's probability is smoothed towards 1/2 by one hit and one miss, so a step with few signals
+// is never certain.
+func fitIsotonic(samples []model.CalibrationSample) []model.CalibrationPoint {
+	sorted := append([]model.CalibrationSample(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Confidence < sorted[j].Confidence })
+
+	type block struct {
+		confidenceSum float64
+		hits, n       int
+	}
+	rate := func(b block) float64 { return float64(b.hits) / float64(b.n) }
+	var blocks []block
+	for i := 0; i < len(sorted); {
+		// Signals of the same confidence share a step
+		b := block{}
+		for j := i; i < len(sorted) && sorted[i].Confidence == sorted[j].Confidence; i++ {
+			b.confidenceSum += sorted[i].Confidence
+			b.n++
+			if sorted[i].Hit {
+				b.hits++
+			}
+		}
+		// Pool with the previous steps while they hit at least as often
+		for len(blocks) > 0 && rate(blocks[len(blocks)-1]) >= rate(b) {
+			prev := blocks[len(blocks)-1]
+			b = block{confidenceSum: prev.confidenceSum + b.confidenceSum, hits: prev.hits + b.hits, n: prev.n + b.n}
+			blocks = blocks[:len(blocks)-1]
+		}
+		blocks = append(blocks, b)
+	}
+
+	points := make([]model.CalibrationPoint, len(blocks))
+	floor := 0.0
+	for i, b := range blocks {
+		// Smoothing can reorder steps of different sizes, so the probabilities are kept non-decreasing
+		floor = math.Max(floor, float64(b.hits+1)/float64(b.n+2))
+		points[i] = model.CalibrationPoint{Confidence: b.confidenceSum / float64(b.n), Probability: floor, Signals: b.n}
+	}
+	return points
+}
+
+// calibrationName describes which signals a calibration was fitted on.
+func calibrationName(c *model.Calibration) string {
+	scope := "all signals"
+	switch {
+	case c.Symbol != "":
+		scope = c.Symbol + " " + c.Timeframe
+	case c.Timeframe != "":
+		scope = "all symbols " + c.Timeframe
+	}
+	return fmt.Sprintf("%s on %s (%d signals)", c.Method, scope, c.Signals)
+}
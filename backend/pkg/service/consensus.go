@@ -0,0 +1,171 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/config"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/indicators"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+)
+
+const (
+	// Moving averages that give the trend of a timeframe
+	trendFastPeriod = 20
+	trendSlowPeriod = 50
+	// A timeframe's prediction stops counting once it misses this many analyses
+	consensusStaleAnalyses = 3
+)
+
+// ConsensusService keeps the latest prediction of every timeframe the workers analyse, and combines
+// them into one signal per symbol that must agree with the trend of a higher timeframe.
+type ConsensusService struct {
+	weights          map[string]float64
+	trendTimeframe   string
+	requireConsensus bool
+
+	latest map[string]map[string]model.TimeframeSignal // By symbol and timeframe
+	mu     sync.RWMutex
+}
+
+// NewConsensusService creates a consensus with the timeframe weights and trend timeframe of the configuration.
+func NewConsensusService(cfg *config.Config) *ConsensusService {
+	return &ConsensusService{
+		weights:          cfg.ConsensusWeights,
+		trendTimeframe:   cfg.ConsensusTrendTimeframe,
+		requireConsensus: cfg.AutoTradeRequireConsensus,
+		latest:           make(map[string]map[string]model.TimeframeSignal),
+	}
+}
+
+// Record stores the latest prediction of a symbol's timeframe, made on candles.
+func (s *ConsensusService) Record(symbol, timeframe string, p model.Prediction, candles []model.Candle) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.latest[symbol] == nil {
+		s.latest[symbol] = make(map[string]model.TimeframeSignal)
+	}
+	s.latest[symbol][timeframe] = model.TimeframeSignal{
+		Timeframe:  timeframe,
+		Weight:     s.weights[timeframe],
+		Prediction: p,
+		Trend:      trendOf(candles),
+		UpdatedAt:  time.Now(),
+	}
+}
+
+// Forget drops the predictions of a symbol, when its worker stops.
+func (s *ConsensusService) Forget(symbol string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.latest, symbol)
+}
+
+// Consensus returns the consensus of a symbol, or false before any of its timeframes has been analysed.
+func (s *ConsensusService) Consensus(symbol string) (*model.Consensus, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	latest, ok := s.latest[symbol]
+	if !ok {
+		return nil, false
+	}
+	return s.combine(symbol, latest, time.Now()), true
+}
+
+// Permits reports whether auto trading may execute a timeframe's prediction: always, unless the consensus
+// is required, in which case its signal must be the consensus signal.
+func (s *ConsensusService) Permits(p model.Prediction) (bool, string) {
+	if !s.requireConsensus {
+		return true, ""
+	}
+	c, ok := s.Consensus(p.Pair)
+	if !ok {
+		return false, "no consensus yet"
+	}
+	if c.Signal != p.Signal {
+		return false, fmt.Sprintf("consensus is %s: %s", c.Signal, c.Reason)
+	}
+	return true, ""
+}
+
+// combine weighs the fresh predictions of the timeframes, each voting its confidence towards buy or sell.
+// A buy or sell is held back unless the trend timeframe is fresh and trends the same way.
+func (s *ConsensusService) combine(symbol string, latest map[string]model.TimeframeSignal, now time.Time) *model.Consensus {
+	c := &model.Consensus{Pair: symbol, Signal: "hold", TrendTimeframe: s.trendTimeframe}
+	var score, totalWeight float64
+	trendFresh := false
+	for _, tf := range timeframes {
+		signal, ok := latest[tf]
+		if !ok {
+			continue
+		}
+		signal.Stale = now.Sub(signal.UpdatedAt) > consensusStaleAnalyses*timeframeIntervals[tf]
+		c.Components = append(c.Components, signal)
+		if signal.UpdatedAt.After(c.UpdatedAt) {
+			c.UpdatedAt = signal.UpdatedAt
+		}
+		if tf == s.trendTimeframe {
+			c.Trend, trendFresh = signal.Trend, !signal.Stale
+		}
+		if signal.Stale || signal.Weight <= 0 {
+			continue
+		}
+		switch signal.Prediction.Signal {
+		case "buy":
+			score += signal.Weight * signal.Prediction.Confidence
+		case "sell":
+			score -= signal.Weight * signal.Prediction.Confidence
+		}
+		totalWeight += signal.Weight
+	}
+	if totalWeight == 0 {
+		c.Reason = "no fresh timeframe predictions"
+		return c
+	}
+
+	c.Score = score / totalWeight
+	signal, want := "hold", ""
+	switch {
+	case c.Score > 0:
+		signal, want = "buy", model.TrendUp
+	case c.Score < 0:
+		signal, want = "sell", model.TrendDown
+	default:
+		c.Reason = "timeframes are balanced"
+		return c
+	}
+	switch {
+	case s.trendTimeframe == "":
+	case !trendFresh:
+		c.Reason = fmt.Sprintf("timeframes lean %s, but there is no fresh %s trend to confirm it", signal, s.trendTimeframe)
+		return c
+	case c.Trend != want:
+		c.Reason = fmt.Sprintf("timeframes lean %s against the %s trend (%s)", signal, s.trendTimeframe, c.Trend)
+		return c
+	}
+	c.Signal, c.Confidence = signal, math.Abs(c.Score)
+	c.Reason = fmt.Sprintf("weighted score %.4f of %d timeframes", c.Score, len(c.Components))
+	if s.trendTimeframe != "" {
+		c.Reason += fmt.Sprintf(", in line with the %s trend", s.trendTimeframe)
+	}
+	return c
+}
+
+// trendOf returns the direction of candles: up while the fast moving average is above the slow one, down
+// while it is below, and flat without enough candles.
+func trendOf(candles []model.Candle) string {
+	if len(candles) < trendSlowPeriod {
+		return model.TrendFlat
+	}
+	prices := model.ClosePrices(candles)
+	fast, slow := last(indicators.EMA(prices, trendFastPeriod)), last(indicators.EMA(prices, trendSlowPeriod))
+	switch {
+	case fast > slow:
+		return model.TrendUp
+	case fast < slow:
+		return model.TrendDown
+	}
+	return model.TrendFlat
+}
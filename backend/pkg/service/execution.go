@@ -0,0 +1,316 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/config"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/events"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/exchange"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/repository"
+)
+
+// executionStrategy is the strategy name recorded on signals and trades created by the pipeline.
+const executionStrategy = "indicators"
+
+// feeHeadroom is the fraction of the quote balance kept free for fees when sizing a buy.
+const feeHeadroom = 0.01
+
+// lastSignal remembers the most recent signal traded on a symbol.
+type lastSignal struct {
+	side string
+	at   time.Time
+}
+
+// ExecutionService turns worker predictions into orders.
+// A prediction above the confidence threshold becomes a model.Signal; one below it is stored as a signal for
+// calibration, but not traded. A BUY signal is risk-checked, sized and
+// placed as a market order for every user subscribed to the symbol, and recorded as an open DBTrade.
+// Long positions are protected by an OCO at the signal's take profit and stop loss once the entry fills, unless
+// the subscription has exit rules: those move the stop and sell parts of the position, which an OCO would lock.
+// Spot accounts cannot short, so a SELL signal closes the subscribers' open long trades through the position
+// manager instead of opening a trade. Signals and the trades they open are published on the event bus.
+type ExecutionService struct {
+	cfg        *config.Config
+	exchanges  map[string]exchange.Exchange
+	tradeRepo  *repository.TradeRepository
+	signalRepo *repository.SignalRepository
+	subRepo    *repository.AutoTradeRepository
+	positions  *PositionManager
+	bus        *events.Bus
+
+	// last holds the last traded signal per symbol, shared by all timeframes of the symbol's worker
+	last map[string]lastSignal
+	// sampled holds the last untraded signal stored per symbol and timeframe
+	sampled map[string]lastSignal
+	mu      sync.Mutex
+}
+
+// NewExecutionService creates a new execution pipeline.
+func NewExecutionService(cfg *config.Config, exchanges map[string]exchange.Exchange, tradeRepo *repository.TradeRepository, signalRepo *repository.SignalRepository, subRepo *repository.AutoTradeRepository, positions *PositionManager, bus *events.Bus) *ExecutionService {
+	return &ExecutionService{
+		cfg:        cfg,
+		exchanges:  exchanges,
+		tradeRepo:  tradeRepo,
+		signalRepo: signalRepo,
+		subRepo:    subRepo,
+		positions:  positions,
+		bus:        bus,
+		last:       make(map[string]lastSignal),
+		sampled:    make(map[string]lastSignal),
+	}
+}
+
+// Execute runs a prediction through the pipeline and returns the trades it opened, or for a SELL signal closed.
+// Holds and duplicates of the last traded signal are ignored. Predictions below the confidence threshold are
+// not traded, but are stored through Record so that their outcomes are evaluated for calibration.
+func (s *ExecutionService) Execute(ctx context.Context, timeframe string, p model.Prediction) ([]*model.DBTrade, error) {
+	side := strings.ToUpper(p.Signal)
+	if (side != "BUY" && side != "SELL") || p.Price <= 0 {
+		return nil, nil
+	}
+	// Predictions without a calibration yet are only held to the confidence threshold
+	if p.Confidence < s.cfg.AutoTradeMinConfidence || (p.Probability != nil && *p.Probability < s.cfg.AutoTradeMinProbability) {
+		s.Record(timeframe, p)
+		return nil, nil
+	}
+	if !s.claim(s.last, p.Pair, side, time.Now()) {
+		return nil, nil
+	}
+
+	signal := s.newSignal(p, side, timeframe)
+	if err := s.signalRepo.CreateSignal(signal); err != nil {
+		log.Printf("Error saving %s signal for %s: %v", side, p.Pair, err)
+	}
+	s.bus.Publish(events.Event{Type: events.SignalCreated, Symbol: signal.Symbol, Data: signal})
+	log.Printf("[%s] %s signal from %s at %.4f (confidence %.2f%%), TP %.4f, SL %.4f",
+		p.Pair, side, timeframe, signal.Price, signal.Confidence*100, signal.TakeProfit, signal.StopLoss)
+
+	subs, err := s.subRepo.GetSubscriptionsBySymbol(p.Pair)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load auto trade subscriptions: %w", err)
+	}
+
+	var trades []*model.DBTrade
+	for _, sub := range subs {
+		if side == "SELL" {
+			closed, err := s.closeFor(ctx, sub, signal)
+			if err != nil {
+				log.Printf("[%s] Not closing trades on SELL signal for user %d: %v", p.Pair, sub.UserID, err)
+			}
+			trades = append(trades, closed...)
+			continue
+		}
+		trade, err := s.executeFor(ctx, sub, signal)
+		if err != nil {
+			log.Printf("[%s] Not trading %s signal for user %d: %v", p.Pair, side, sub.UserID, err)
+			continue
+		}
+		trades = append(trades, trade)
+	}
+	return trades, nil
+}
+
+// closeFor closes a subscriber's open long trades on the signal's symbol and exchange at market, through the
+// position manager, which releases their protective OCOs as part of the close and records their realised profit
+// and loss. A SELL signal without a long to close is not traded.
+func (s *ExecutionService) closeFor(ctx context.Context, sub *model.AutoTradeSubscription, signal *model.Signal) ([]*model.DBTrade, error) {
+	open, err := s.tradeRepo.GetOpenTradesByUserID(sub.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load open trades: %w", err)
+	}
+	var closed []*model.DBTrade
+	var errs []error
+	for _, t := range open {
+		if t.Symbol != signal.Symbol || t.Exchange != sub.Exchange || t.Side != "BUY" {
+			continue
+		}
+		trade, err := s.positions.CloseTrade(exchange.WithUserID(ctx, sub.UserID), t, signal.Price, CloseSignal)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to close trade %d: %w", t.ID, err))
+			continue
+		}
+		closed = append(closed, trade)
+	}
+	if len(closed) == 0 && len(errs) == 0 {
+		return nil, fmt.Errorf("no open BUY trade on %s to close", signal.Symbol)
+	}
+	return closed, errors.Join(errs...)
+}
+
+// Record stores a buy or sell prediction that is not traded as a signal, without publishing or executing it.
+// The signal evaluator scores it like a traded one, so the calibration covers every raw confidence and not only
+// those above the threshold. A timeframe stores the same direction at most once per cooldown.
+func (s *ExecutionService) Record(timeframe string, p model.Prediction) {
+	side := strings.ToUpper(p.Signal)
+	if (side != "BUY" && side != "SELL") || p.Price <= 0 {
+		return
+	}
+	if !s.claim(s.sampled, p.Pair+" "+timeframe, side, time.Now()) {
+		return
+	}
+	if err := s.signalRepo.CreateSignal(s.newSignal(p, side, timeframe)); err != nil {
+		log.Printf("Error saving untraded %s signal for %s: %v", side, p.Pair, err)
+	}
+}
+
+// claim records side as the last signal for key in last, unless it repeats the previous signal.
+// The same direction is claimed again only once the cooldown has passed; an opposite signal is a new crossover.
+func (s *ExecutionService) claim(last map[string]lastSignal, key, side string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if prev, ok := last[key]; ok && prev.side == side && now.Sub(prev.at) < s.cfg.AutoTradeCooldown {
+		return false
+	}
+	last[key] = lastSignal{side: side, at: now}
+	return true
+}
+
+// newSignal builds the signal for a prediction on a timeframe, with take profit and stop loss around its price.
+func (s *ExecutionService) newSignal(p model.Prediction, side, timeframe string) *model.Signal {
+	tp, sl := s.cfg.AutoTradeTakeProfitPercent/100, s.cfg.AutoTradeStopLossPercent/100
+	signal := &model.Signal{
+		Symbol:     p.Pair,
+		Strategy:   executionStrategy,
+		Type:       side,
+		Price:      p.Price,
+		TakeProfit: p.Price * (1 + tp),
+		StopLoss:   p.Price * (1 - sl),
+		Confidence: p.Confidence,
+		CreatedAt:  time.Now(),
+		Timeframe:  timeframe,
+	}
+	if side == "SELL" {
+		signal.TakeProfit = p.Price * (1 - tp)
+		signal.StopLoss = p.Price * (1 + sl)
+	}
+	return signal
+}
+
+// executeFor sizes, risk-checks and places a BUY signal for one subscriber, then records the trade.
+func (s *ExecutionService) executeFor(ctx context.Context, sub *model.AutoTradeSubscription, signal *model.Signal) (*model.DBTrade, error) {
+	ex, ok := s.exchanges[sub.Exchange]
+	if !ok {
+		return nil, fmt.Errorf("exchange %s not found", sub.Exchange)
+	}
+	base, quote, err := exchange.SplitSymbol(signal.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	ctx = exchange.WithUserID(ctx, sub.UserID)
+
+	quoteBalance, err := ex.GetBalance(ctx, quote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s balance: %w", quote, err)
+	}
+	riskPercent := sub.RiskPercent
+	if riskPercent <= 0 {
+		riskPercent = s.cfg.AutoTradeRiskPercent
+	}
+	quantity := CalculatePositionSize(quoteBalance, riskPercent, signal.Price, signal.StopLoss)
+
+	quantity, err = s.checkRisk(sub, signal, quoteBalance, quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := ex.PlaceOrder(ctx, exchange.MarketOrder(signal.Symbol, signal.Type, quantity))
+	if err != nil {
+		return nil, fmt.Errorf("failed to place order: %w", err)
+	}
+	price := signal.Price
+	if order.FilledQuantity > 0 {
+		quantity, price = order.FilledQuantity, order.AvgPrice
+	}
+
+	log.Printf("[%s] Placed %s %.8f at %.4f for user %d on %s (order %s)", signal.Symbol, signal.Type, quantity, price, sub.UserID, sub.Exchange, order.ExchangeOrderID)
+
+	trade := &model.DBTrade{
+		UserID:     sub.UserID,
+		Exchange:   sub.Exchange,
+		Symbol:     signal.Symbol,
+		Side:       signal.Type,
+		Quantity:   quantity,
+		Price:      price,
+		Strategy:   executionStrategy,
+		TakeProfit: signal.TakeProfit,
+		StopLoss:   signal.StopLoss,
+		Status:     model.TradeOpen,
+		ExecutedAt: time.Now(),
+		Fees:       feeInQuote(order, base, quote, price),
+	}
+	if sub.ExitRules != nil {
+		rules := sub.ExitRules.Copy()
+		if err := rules.Start(trade.Side, price, quantity); err != nil {
+			log.Printf("[%s] Ignoring exit rules of user %d: %v", signal.Symbol, sub.UserID, err)
+		} else {
+			trade.ExitRules = rules
+			if len(rules.TakeProfits) > 0 {
+				// The ladder takes profits instead, and what it leaves open exits by its stop
+				trade.TakeProfit = 0
+			}
+		}
+	}
+	if s.cfg.AutoTradeProtectiveOCO && trade.ExitRules == nil {
+		listID, err := s.protect(ctx, ex, signal, base, order)
+		if err != nil {
+			log.Printf("[%s] Position of user %d is unprotected: %v", signal.Symbol, sub.UserID, err)
+		}
+		trade.ProtectionListID = listID
+	}
+
+	if err := s.tradeRepo.CreateTrade(trade); err != nil {
+		// The order is already on the exchange, so report it rather than failing the execution.
+		log.Printf("[%s] Error saving trade for user %d: %v", signal.Symbol, sub.UserID, err)
+	}
+	s.bus.Publish(events.Event{Type: events.TradeOpened, UserID: trade.UserID, Symbol: trade.Symbol, Data: trade})
+	return trade, nil
+}
+
+// protect places a SELL OCO at the signal's take profit and stop loss for the base asset bought by order,
+// and returns its order list ID.
+func (s *ExecutionService) protect(ctx context.Context, ex exchange.Exchange, signal *model.Signal, base string, order *model.Order) (string, error) {
+	if order.Status != model.OrderFilled {
+		return "", fmt.Errorf("entry order %s is %s, not filled", order.ExchangeOrderID, order.Status)
+	}
+	quantity := order.FilledQuantity
+	if order.FeeAsset == base {
+		quantity -= order.Fee
+	}
+	req := exchange.ProtectiveOCO(signal.Symbol, quantity, signal.TakeProfit, signal.StopLoss, s.cfg.AutoTradeStopLimitPercent/100)
+	legs, err := ex.PlaceOCO(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("failed to place protective OCO: %w", err)
+	}
+	log.Printf("[%s] Protective OCO for %.8f: take profit %.4f (order %s), stop loss %.4f (order %s)",
+		signal.Symbol, quantity, signal.TakeProfit, legs[0].ExchangeOrderID, signal.StopLoss, legs[1].ExchangeOrderID)
+	return legs[0].OrderListID, nil
+}
+
+// checkRisk rejects BUY signals the user cannot or should not take and caps the quantity to what the account can pay for.
+func (s *ExecutionService) checkRisk(sub *model.AutoTradeSubscription, signal *model.Signal, quoteBalance, quantity float64) (float64, error) {
+	open, err := s.tradeRepo.GetOpenTradesByUserID(sub.UserID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load open trades: %w", err)
+	}
+	for _, t := range open {
+		if t.Symbol == signal.Symbol && t.Side == signal.Type {
+			return 0, fmt.Errorf("a %s trade on %s is already open", t.Side, t.Symbol)
+		}
+	}
+
+	// Leave room for fees the exchange may reserve on top of the notional
+	quantity = math.Min(quantity, quoteBalance*(1-feeHeadroom)/signal.Price)
+	if quantity <= 0 {
+		return 0, fmt.Errorf("insufficient balance for a %s order", signal.Type)
+	}
+	return quantity, nil
+}
@@ -3,34 +3,109 @@ package service
 import (
 	"context"
 	"fmt"
+	"log"
 	"strconv"
 	"time"
 
 	"github.com/adshao/go-binance/v2"
 	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/repository"
 )
 
 // FetcherService is responsible for fetching data from external APIs.
-// This version is configured to fetch kline data from Binance.
+// This version is configured to fetch kline data from Binance, reading
+// from the candle store first and only asking Binance for missing candles.
 type FetcherService struct {
 	binanceClient *binance.Client
+	candleRepo    *repository.CandleRepository
 }
 
 // NewFetcherService creates a new FetcherService for Binance.
 // For public data endpoints, API keys are not required.
-func NewFetcherService() *FetcherService {
+func NewFetcherService(candleRepo *repository.CandleRepository) *FetcherService {
 	return &FetcherService{
 		binanceClient: binance.NewClient("", ""),
+		candleRepo:    candleRepo,
 	}
 }
 
-// FetchKlines retrieves and sorts the latest kline (candlestick) data for a given crypto symbol.
-func (s *FetcherService) FetchKlines(symbol, interval string) ([]model.ForexData, error) {
-	// 1. Fetch klines from Binance API.
+// binanceKlineLimit is the maximum number of klines Binance returns per request.
+const binanceKlineLimit = 1000
+
+// FetchCandles retrieves the latest limit candles for a crypto symbol, sorted oldest to newest.
+// Stored candles are used where possible and only the candles after the newest stored one are fetched.
+func (s *FetcherService) FetchCandles(symbol, interval string, limit int) ([]model.Candle, error) {
+	step, err := model.IntervalDuration(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := s.candleRepo.GetLatestCandles(symbol, interval, limit)
+	if err != nil {
+		log.Printf("Candle store unavailable for %s [%s], fetching from Binance: %v", symbol, interval, err)
+		return s.fetchLatest(symbol, interval, limit)
+	}
+
+	from := time.Now().Add(-time.Duration(limit) * step)
+	if len(stored) > 0 {
+		from = stored[len(stored)-1].OpenTime.Add(step)
+	}
+	recent, err := s.fetchRange(context.Background(), symbol, interval, from, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	s.store(recent)
+
+	candles := append(stored, recent...)
+	if len(candles) > limit {
+		candles = candles[len(candles)-limit:]
+	}
+	// Older history missing from the store; take the whole window from Binance instead.
+	if len(candles) < limit || len(findGaps(candles, step, candles[0].OpenTime, candles[len(candles)-1].CloseTime)) > 0 {
+		return s.fetchLatest(symbol, interval, limit)
+	}
+	return candles, nil
+}
+
+// FetchCandleRange retrieves every candle opening in [start, end), sorted oldest to newest.
+// Gaps in the candle store are fetched from Binance and saved before returning.
+func (s *FetcherService) FetchCandleRange(ctx context.Context, symbol, interval string, start, end time.Time) ([]model.Candle, error) {
+	step, err := model.IntervalDuration(interval)
+	if err != nil {
+		return nil, err
+	}
+	if now := time.Now(); end.After(now) {
+		end = now
+	}
+
+	stored, err := s.candleRepo.GetCandles(symbol, interval, start, end)
+	if err != nil {
+		log.Printf("Candle store unavailable for %s [%s], fetching from Binance: %v", symbol, interval, err)
+		return s.fetchRange(ctx, symbol, interval, start, end)
+	}
+
+	gaps := findGaps(stored, step, start, end)
+	if len(gaps) == 0 {
+		return stored, nil
+	}
+	for _, gap := range gaps {
+		fetched, err := s.fetchRange(ctx, symbol, interval, gap.Start, gap.End)
+		if err != nil {
+			return nil, err
+		}
+		s.store(fetched)
+		stored = append(stored, fetched...)
+	}
+	sortCandles(stored)
+	return stored, nil
+}
+
+// fetchLatest retrieves the latest limit candles straight from Binance and saves the closed ones.
+func (s *FetcherService) fetchLatest(symbol, interval string, limit int) ([]model.Candle, error) {
 	klines, err := s.binanceClient.NewKlinesService().
 		Symbol(symbol).
 		Interval(interval).
-		Limit(100). // The number of candles to retrieve, sufficient for our indicators
+		Limit(limit).
 		Do(context.Background())
 
 	if err != nil {
@@ -41,27 +116,90 @@ func (s *FetcherService) FetchKlines(symbol, interval string) ([]model.ForexData
 		return nil, fmt.Errorf("no kline data returned from Binance for symbol %s (is the symbol valid?)", symbol)
 	}
 
-	// 2. Transform the Binance response into our internal model.ForexData format.
-	var data []model.ForexData
-	for _, k := range klines {
-		// The prediction engine uses the closing price of each candle.
-		price, err := strconv.ParseFloat(k.Close, 64)
+	candles, err := candlesFromKlines(symbol, interval, klines)
+	if err != nil {
+		return nil, err
+	}
+	s.store(candles)
+	return candles, nil
+}
+
+// fetchRange pages through the Binance klines API to retrieve every candle
+// opening in [start, end), sorted oldest to newest.
+func (s *FetcherService) fetchRange(ctx context.Context, symbol, interval string, start, end time.Time) ([]model.Candle, error) {
+	var candles []model.Candle
+	from := start
+	for from.Before(end) {
+		klines, err := s.binanceClient.NewKlinesService().
+			Symbol(symbol).
+			Interval(interval).
+			StartTime(from.UnixMilli()).
+			EndTime(end.UnixMilli() - 1).
+			Limit(binanceKlineLimit).
+			Do(ctx)
 		if err != nil {
-			// Skip this kline if the price is not a valid number, though this is unlikely with Binance.
-			continue
+			return nil, fmt.Errorf("failed to fetch klines from Binance for symbol %s: %w", symbol, err)
+		}
+		if len(klines) == 0 {
+			break
+		}
+
+		page, err := candlesFromKlines(symbol, interval, klines)
+		if err != nil {
+			return nil, err
+		}
+		candles = append(candles, page...)
+
+		from = time.UnixMilli(klines[len(klines)-1].CloseTime + 1)
+		if len(klines) < binanceKlineLimit {
+			break
 		}
-		data = append(data, model.ForexData{
-			// The timestamp from Binance is the *closing* time of the candle.
-			Timestamp: time.UnixMilli(k.CloseTime).Format("2006-01-02 15:04:05"),
-			Price:     price,
-		})
 	}
+	return candles, nil
+}
 
-	// 3. The Binance API returns data sorted oldest to newest.
-	// Our prediction engine expects data sorted newest to oldest. So, we reverse the slice.
-	for i, j := 0, len(data)-1; i < j; i, j = i+1, j-1 {
-		data[i], data[j] = data[j], data[i]
+// FetchKlines retrieves the latest close prices for a given crypto symbol.
+// It is a close-only adapter over FetchCandles, sorted newest to oldest.
+func (s *FetcherService) FetchKlines(symbol, interval string) ([]model.ForexData, error) {
+	candles, err := s.FetchCandles(symbol, interval, 100) // Sufficient for our indicators
+	if err != nil {
+		return nil, err
 	}
+	return model.ToForexData(candles), nil
+}
 
-	return data, nil
+// store saves the closed candles; the candle still in progress is left out so it is fetched again next time.
+func (s *FetcherService) store(candles []model.Candle) {
+	closed := closedCandles(candles)
+	if err := s.candleRepo.UpsertCandles(closed); err != nil {
+		log.Printf("Failed to store %d candles: %v", len(closed), err)
+	}
+}
+
+// candlesFromKlines transforms the Binance response into our internal model.Candle format.
+func candlesFromKlines(symbol, interval string, klines []*binance.Kline) ([]model.Candle, error) {
+	candles := make([]model.Candle, 0, len(klines))
+	for _, k := range klines {
+		values := make([]float64, 5)
+		for i, raw := range []string{k.Open, k.High, k.Low, k.Close, k.Volume} {
+			v, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid kline value %q: %w", raw, err)
+			}
+			values[i] = v
+		}
+		candles = append(candles, model.Candle{
+			Symbol:     symbol,
+			Interval:   interval,
+			OpenTime:   time.UnixMilli(k.OpenTime),
+			CloseTime:  time.UnixMilli(k.CloseTime),
+			Open:       values[0],
+			High:       values[1],
+			Low:        values[2],
+			Close:      values[3],
+			Volume:     values[4],
+			TradeCount: k.TradeNum,
+		})
+	}
+	return candles, nil
 }
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"sync"
 
-	"github.com/ratheeshkumar25/forex_bot/backend/pkg/repository"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/events"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/marketdata"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
 )
 
 // WorkerManager oversees all active analysis workers.
@@ -14,6 +16,12 @@ type WorkerManager struct {
 	// workerFactory is a dependency that creates new worker instances.
 	workerFactory func() *WorkerService
 
+	// bus receives the start and stop of every worker.
+	bus *events.Bus
+
+	// consensus combines the timeframes analysed by the workers.
+	consensus *ConsensusService
+
 	// activeWorkers holds the cancellation function for each running worker.
 	// The map is protected by a mutex to allow safe concurrent access.
 	activeWorkers map[string]context.CancelFunc
@@ -23,12 +31,14 @@ type WorkerManager struct {
 // NewWorkerManager creates a new manager.
 // It takes a factory function to create worker instances, which decouples it
 // from the specific implementation of WorkerService.
-func NewWorkerManager(fetcher *FetcherService, predictor *PredictionService, tradeRepo *repository.TradeRepository, signalRepo *repository.SignalRepository) *WorkerManager {
+func NewWorkerManager(fetcher *FetcherService, predictor *PredictionService, executor *ExecutionService, hub *marketdata.Hub, bus *events.Bus, consensus *ConsensusService, calibrator *CalibrationService) *WorkerManager {
 	return &WorkerManager{
 		// This factory function captures the dependencies needed by a WorkerService.
 		workerFactory: func() *WorkerService {
-			return NewWorkerService(fetcher, predictor, tradeRepo, signalRepo)
+			return NewWorkerService(fetcher, predictor, executor, hub, consensus, calibrator)
 		},
+		bus:           bus,
+		consensus:     consensus,
 		activeWorkers: make(map[string]context.CancelFunc),
 	}
 }
@@ -48,8 +58,12 @@ func (m *WorkerManager) StartWorker(symbol string) error {
 	// Create a new worker instance using the factory.
 	worker := m.workerFactory()
 
-	// Start the worker in its own goroutine.
-	go worker.Start(ctx, symbol)
+	// Start the worker in its own goroutine, reporting when it stops.
+	m.bus.Publish(events.Event{Type: events.WorkerStarted, Symbol: symbol})
+	go func() {
+		worker.Start(ctx, symbol)
+		m.bus.Publish(events.Event{Type: events.WorkerStopped, Symbol: symbol})
+	}()
 
 	// Store the cancellation function so we can stop it later.
 	m.activeWorkers[symbol] = cancel
@@ -70,8 +84,9 @@ func (m *WorkerManager) StopWorker(symbol string) error {
 	// Call the worker's cancellation function to signal it to stop.
 	cancel()
 
-	// Remove the worker from the active list.
+	// Remove the worker from the active list, along with the predictions it made.
 	delete(m.activeWorkers, symbol)
+	m.consensus.Forget(symbol)
 
 	return nil
 }
@@ -87,3 +102,19 @@ func (m *WorkerManager) GetStatus() []string {
 	}
 	return symbols
 }
+
+// GetConsensus returns the multi-timeframe consensus of every running worker that has analysed a timeframe, by symbol.
+func (m *WorkerManager) GetConsensus() map[string]*model.Consensus {
+	consensus := make(map[string]*model.Consensus)
+	for _, symbol := range m.GetStatus() {
+		if c, ok := m.consensus.Consensus(symbol); ok {
+			consensus[symbol] = c
+		}
+	}
+	return consensus
+}
+
+// Consensus returns the multi-timeframe consensus of a symbol, or false if its worker has not analysed a timeframe yet.
+func (m *WorkerManager) Consensus(symbol string) (*model.Consensus, bool) {
+	return m.consensus.Consensus(symbol)
+}
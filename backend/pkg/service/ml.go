@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/ml"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/repository"
+)
+
+// Active models are reloaded this often, so a model activated by another instance is picked up
+const mlModelRefreshInterval = 5 * time.Minute
+
+type mlModelKey struct {
+	symbol, interval string
+}
+
+// activeMLModel is the cached active model of a symbol and interval: meta is nil if none is active, and err is set
+// if the active model cannot be used
+type activeMLModel struct {
+	meta     *model.MLModel
+	logistic *ml.Logistic
+	err      error
+	loadedAt time.Time
+}
+
+// MLService trains versions of the ML forecasting models on stored candles, and forecasts with the active versions.
+type MLService struct {
+	fetcherSvc *FetcherService
+	modelRepo  *repository.MLModelRepository
+
+	mu     sync.Mutex
+	active map[mlModelKey]*activeMLModel
+}
+
+// NewMLService creates a new ML service.
+func NewMLService(fetcher *FetcherService, modelRepo *repository.MLModelRepository) *MLService {
+	return &MLService{fetcherSvc: fetcher, modelRepo: modelRepo, active: make(map[mlModelKey]*activeMLModel)}
+}
+
+// MLTrainRequest describes the training of a model version.
+type MLTrainRequest struct {
+	Symbol   string
+	Interval string
+	Start    time.Time
+	End      time.Time
+	Horizon  int  // Candles ahead the model forecasts
+	Folds    int  // Walk-forward validation folds
+	Activate bool // Make the new version the active one
+}
+
+// Train validates a logistic regression walk-forward on the candles of the request, then trains it on all of them
+// and stores it as the next version. Missing candles are fetched from Binance first.
+func (s *MLService) Train(ctx context.Context, req MLTrainRequest) (*model.MLModel, error) {
+	if req.Horizon < 1 {
+		return nil, fmt.Errorf("horizon must be at least 1")
+	}
+	candles, err := s.fetcherSvc.FetchCandleRange(ctx, req.Symbol, req.Interval, req.Start, req.End)
+	if err != nil {
+		return nil, err
+	}
+	ds := ml.NewDataset(candles, req.Horizon)
+	cfg := ml.DefaultTrainConfig()
+	validation, err := ml.WalkForward(ds, req.Folds, req.Horizon, cfg)
+	if err != nil {
+		return nil, err
+	}
+	logistic, err := ml.TrainLogistic(ds, cfg)
+	if err != nil {
+		return nil, err
+	}
+	parameters, err := json.Marshal(logistic)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &model.MLModel{
+		Symbol:      req.Symbol,
+		Interval:    req.Interval,
+		Kind:        model.MLLogisticRegression,
+		Horizon:     req.Horizon,
+		Samples:     ds.Len(),
+		TrainedFrom: ds.Times[0],
+		TrainedTo:   ds.Times[ds.Len()-1],
+		Parameters:  parameters,
+		Validation:  validation,
+	}
+	if err := s.modelRepo.CreateModel(m); err != nil {
+		return nil, err
+	}
+	log.Printf("Trained ML model %s [%s] v%d on %d samples: walk-forward accuracy %.2f%% with %.2f%% of rises",
+		m.Symbol, m.Interval, m.Version, m.Samples, validation.Accuracy*100, validation.BaseRate*100)
+	if req.Activate {
+		if err := s.Activate(m.ID); err != nil {
+			return nil, err
+		}
+		m.Active = true
+	}
+	return m, nil
+}
+
+// Activate makes a model version the active one of its symbol and interval.
+func (s *MLService) Activate(id int) error {
+	m, err := s.modelRepo.GetModel(id)
+	if err != nil {
+		return err
+	}
+	if _, err := decodeLogistic(m); err != nil {
+		return err
+	}
+	if err := s.modelRepo.ActivateModel(id); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	delete(s.active, mlModelKey{m.Symbol, m.Interval})
+	s.mu.Unlock()
+	return nil
+}
+
+// Forecast returns the probability that the close rises over the horizon after the last of candles, sorted oldest
+// to newest, with the active model of their symbol and interval. The model is nil if none is active.
+func (s *MLService) Forecast(candles []model.Candle) (float64, *model.MLModel, error) {
+	if len(candles) == 0 {
+		return 0, nil, fmt.Errorf("no candles to forecast")
+	}
+	active, err := s.activeModel(candles[0].Symbol, candles[0].Interval)
+	if err != nil {
+		return 0, nil, err
+	}
+	if active.err != nil || active.meta == nil {
+		return 0, active.meta, active.err
+	}
+	probability, err := active.logistic.Forecast(candles)
+	return probability, active.meta, err
+}
+
+// activeModel returns the cached active model of a symbol and interval, reloading it once it is older than the refresh
+// interval. A failed reload keeps the previous model until the next interval.
+func (s *MLService) activeModel(symbol, interval string) (*activeMLModel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := mlModelKey{symbol, interval}
+	cached, ok := s.active[key]
+	if ok && time.Since(cached.loadedAt) < mlModelRefreshInterval {
+		return cached, nil
+	}
+
+	m, err := s.modelRepo.GetActiveModel(symbol, interval)
+	if err != nil {
+		if !ok {
+			return nil, err
+		}
+		log.Printf("Error loading the active ML model of %s [%s], keeping the previous one: %v", symbol, interval, err)
+		cached.loadedAt = time.Now()
+		return cached, nil
+	}
+	cached = &activeMLModel{meta: m, loadedAt: time.Now()}
+	if m != nil {
+		cached.logistic, cached.err = decodeLogistic(m)
+	}
+	s.active[key] = cached
+	return cached, nil
+}
+
+// decodeLogistic decodes the parameters of a logistic regression model, checking it can be used with the current features.
+func decodeLogistic(m *model.MLModel) (*ml.Logistic, error) {
+	if m.Kind != model.MLLogisticRegression {
+		return nil, fmt.Errorf("unsupported model kind %q", m.Kind)
+	}
+	var logistic ml.Logistic
+	if err := json.Unmarshal(m.Parameters, &logistic); err != nil {
+		return nil, err
+	}
+	if err := logistic.Check(); err != nil {
+		return nil, err
+	}
+	return &logistic, nil
+}
+
+// Indicator returns the "ml" scoring indicator, which votes with the active model of the candles' symbol and interval:
+// buy when the forecast probability of a rise reaches the threshold, and sell when that of a fall does.
+func (s *MLService) Indicator() Indicator {
+	return Indicator{
+		Description: "ML forecast: buys when the active model of the symbol and interval forecasts a rise with at least the threshold probability, and sells when it forecasts a fall with it",
+		Defaults:    map[string]float64{"threshold": 0.55},
+		Lookback:    func(map[string]float64) int { return ml.Lookback },
+		Validate: func(p map[string]float64) error {
+			if p["threshold"] <= 0.5 || p["threshold"] >= 1 {
+				return fmt.Errorf("threshold must be between 0.5 and 1")
+			}
+			return nil
+		},
+		Vote: func(candles []model.Candle, p map[string]float64) (float64, map[string]float64, string) {
+			probability, m, err := s.Forecast(candles)
+			if err != nil {
+				return 0, nil, fmt.Sprintf("ML forecast failed: %v", err)
+			}
+			if m == nil {
+				return 0, nil, "no active ML model for the symbol and interval"
+			}
+			values := map[string]float64{"probability": probability, "version": float64(m.Version), "horizon": float64(m.Horizon)}
+			switch {
+			case probability >= p["threshold"]:
+				return 1, values, fmt.Sprintf("model v%d forecasts a rise over %d candles with probability %.2f", m.Version, m.Horizon, probability)
+			case probability <= 1-p["threshold"]:
+				return -1, values, fmt.Sprintf("model v%d forecasts a fall over %d candles with probability %.2f", m.Version, m.Horizon, 1-probability)
+			}
+			return 0, values, fmt.Sprintf("model v%d forecast probability %.2f of a rise is inconclusive", m.Version, probability)
+		},
+	}
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strconv"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/events"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/exchange"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/repository"
+)
+
+// OrderRecorder keeps the orders table in step with the exchanges.
+// Exchanges wrapped by it store every order they place, and update the stored order
+// whenever its state is read back with GetOrder or changed with CancelOrder.
+// New orders and changes of state are published on the event bus.
+type OrderRecorder struct {
+	orderRepo *repository.OrderRepository
+	bus       *events.Bus
+}
+
+// NewOrderRecorder creates a new order recorder.
+// Paper orders fill in the background, so their fills are recorded as the paper exchange reports them.
+func NewOrderRecorder(orderRepo *repository.OrderRepository, paper *exchange.PaperExchange, bus *events.Bus) *OrderRecorder {
+	r := &OrderRecorder{orderRepo: orderRepo, bus: bus}
+	paper.OnFill(func(f exchange.PaperFill) {
+		ctx := exchange.WithUserID(context.Background(), f.UserID)
+		order, err := paper.GetOrder(ctx, f.Symbol, strconv.FormatInt(f.OrderID, 10))
+		if err != nil {
+			log.Printf("Error reading filled paper order %d: %v", f.OrderID, err)
+			return
+		}
+		r.update(ctx, paper, order)
+	})
+	return r
+}
+
+// closeSiblings stores the state of the other leg of an OCO once one leg has filled or been cancelled,
+// since the exchange ends the other leg without a call of its own.
+func (r *OrderRecorder) closeSiblings(ctx context.Context, ex exchange.Exchange, closed *model.Order) {
+	if closed.OrderListID == "" || closed.IsOpen() {
+		return
+	}
+	open, err := r.orderRepo.GetOpenOrdersByUserID(closed.UserID)
+	if err != nil {
+		log.Printf("Error loading open orders of user %d: %v", closed.UserID, err)
+		return
+	}
+	for _, o := range open {
+		if o.Exchange != closed.Exchange || o.OrderListID != closed.OrderListID || o.ExchangeOrderID == closed.ExchangeOrderID {
+			continue
+		}
+		order, err := ex.GetOrder(ctx, o.Symbol, o.ExchangeOrderID)
+		if err != nil {
+			log.Printf("Error reading %s order %s: %v", o.Exchange, o.ExchangeOrderID, err)
+			continue
+		}
+		order.Exchange = o.Exchange
+		r.store(order)
+	}
+}
+
+// update stores the current state of an order, then that of its OCO siblings if it has closed.
+func (r *OrderRecorder) update(ctx context.Context, ex exchange.Exchange, order *model.Order) {
+	if !r.store(order) {
+		return
+	}
+	r.closeSiblings(ctx, ex, order)
+}
+
+// store stores the current state of an order and publishes it if it changed.
+// It returns false if the order could not be stored.
+func (r *OrderRecorder) store(order *model.Order) bool {
+	changed, err := r.orderRepo.UpdateOrderStatus(order)
+	if err != nil {
+		log.Printf("Error updating %s order %s: %v", order.Exchange, order.ExchangeOrderID, err)
+		return false
+	}
+	if changed {
+		eventType := events.OrderUpdated
+		if order.Status == model.OrderFilled {
+			eventType = events.OrderFilled
+		}
+		r.publish(eventType, order)
+	}
+	return true
+}
+
+func (r *OrderRecorder) publish(eventType string, order *model.Order) {
+	r.bus.Publish(events.Event{Type: eventType, UserID: order.UserID, Symbol: order.Symbol, Data: order})
+}
+
+// Wrap returns ex with its orders recorded under the exchange name.
+func (r *OrderRecorder) Wrap(name string, ex exchange.Exchange) exchange.Exchange {
+	return &recordingExchange{Exchange: ex, name: name, recorder: r}
+}
+
+// recordingExchange is an exchange whose orders are stored by an OrderRecorder.
+type recordingExchange struct {
+	exchange.Exchange
+	name     string
+	recorder *OrderRecorder
+}
+
+// PlaceOrder places the order and stores it. The order stands even if it cannot be stored.
+func (e *recordingExchange) PlaceOrder(ctx context.Context, req exchange.OrderRequest) (*model.Order, error) {
+	order, err := e.Exchange.PlaceOrder(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	e.create(ctx, order)
+	return order, nil
+}
+
+// PlaceOCO places the bracket and stores both of its legs.
+func (e *recordingExchange) PlaceOCO(ctx context.Context, req exchange.OCORequest) ([]*model.Order, error) {
+	orders, err := e.Exchange.PlaceOCO(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	for _, order := range orders {
+		e.create(ctx, order)
+	}
+	return orders, nil
+}
+
+// CancelOrder cancels the order and stores its final state.
+func (e *recordingExchange) CancelOrder(ctx context.Context, symbol string, orderID string) (*model.Order, error) {
+	order, err := e.Exchange.CancelOrder(ctx, symbol, orderID)
+	if err != nil {
+		return nil, err
+	}
+	e.update(ctx, order)
+	return order, nil
+}
+
+// GetOrder reads the order from the exchange and stores its current state.
+func (e *recordingExchange) GetOrder(ctx context.Context, symbol string, orderID string) (*model.Order, error) {
+	order, err := e.Exchange.GetOrder(ctx, symbol, orderID)
+	if err != nil {
+		return nil, err
+	}
+	e.update(ctx, order)
+	return order, nil
+}
+
+// GetLockedBalance forwards to the wrapped exchange when it reports locked funds.
+func (e *recordingExchange) GetLockedBalance(ctx context.Context, asset string) (float64, error) {
+	if lb, ok := e.Exchange.(exchange.LockedBalancer); ok {
+		return lb.GetLockedBalance(ctx, asset)
+	}
+	return 0, nil
+}
+
+// CancelAllOrders cancels every open order of the user and marks the stored ones as cancelled.
+func (e *recordingExchange) CancelAllOrders(ctx context.Context) error {
+	canceller, ok := e.Exchange.(exchange.OrderCanceller)
+	if !ok {
+		return nil
+	}
+	if err := canceller.CancelAllOrders(ctx); err != nil {
+		return err
+	}
+	userID := exchange.UserIDFromContext(ctx)
+	if err := e.recorder.orderRepo.CancelOpenOrders(userID, e.name); err != nil {
+		log.Printf("Error marking %s orders of user %d as cancelled: %v", e.name, userID, err)
+	}
+	return nil
+}
+
+// Unwrap returns the wrapped exchange.
+func (e *recordingExchange) Unwrap() exchange.Exchange {
+	return e.Exchange
+}
+
+func (e *recordingExchange) create(ctx context.Context, order *model.Order) {
+	order.UserID = exchange.UserIDFromContext(ctx)
+	order.Exchange = e.name
+	if err := e.recorder.orderRepo.CreateOrder(order); err != nil {
+		log.Printf("Error saving %s order %s for user %d: %v", e.name, order.ExchangeOrderID, order.UserID, err)
+		return
+	}
+	e.recorder.publish(events.OrderPlaced, order)
+}
+
+func (e *recordingExchange) update(ctx context.Context, order *model.Order) {
+	order.Exchange = e.name
+	e.recorder.update(ctx, e.Exchange, order)
+}
@@ -0,0 +1,688 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/config"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/events"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/exchange"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/indicators"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/marketdata"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/repository"
+)
+
+// Reasons a trade is closed for
+const (
+	CloseTakeProfit   = "take_profit"
+	CloseStopLoss     = "stop_loss"
+	CloseBreakEven    = "break_even"    // The stop moved to the entry price by the exit rules
+	CloseTrailingStop = "trailing_stop" // The stop trailed by the exit rules
+	CloseDuration     = "duration"
+	CloseSignal       = "signal" // An opposite signal of the execution pipeline
+)
+
+// ErrTradeNotFound is returned for a trade that is not an open trade of the user.
+var ErrTradeNotFound = errors.New("open trade not found")
+
+const (
+	// positionCheckInterval is how often open trades are checked against the latest prices
+	positionCheckInterval = time.Second
+	// positionRefreshInterval is how often open trades are reloaded and their protective OCOs read back from the exchange
+	positionRefreshInterval = 30 * time.Second
+	// positionRetryDelay is how long a trade that failed to close waits before the next attempt
+	positionRetryDelay = time.Minute
+	// positionDustFraction is the share of the opening quantity below which a partial take profit closes the whole
+	// position instead, as smaller remainders are usually below the exchange's minimum order size
+	positionDustFraction = 0.01
+)
+
+// PositionManager closes open trades when the live price reaches their take profit or stop loss,
+// or when they reach the maximum trade duration. It loads the OPEN trades of every user, follows
+// their symbols' trade streams on the market data hub, and on exit places the closing market order
+// and stores the trade as CLOSED with its exit price and its profit and loss net of fees.
+//
+// A long position protected by an OCO is closed by the OCO when the exchange fills a leg; the manager
+// then records the leg's fill. If the price reaches a level first, the OCO is cancelled and the position
+// is closed at market instead.
+//
+// Trades with exit rules also have their stop moved to break-even and trailed behind the best price, and
+// parts of the position closed as the price reaches the levels of their take profit ladder.
+type PositionManager struct {
+	cfg       *config.Config
+	exchanges map[string]exchange.Exchange
+	tradeRepo *repository.TradeRepository
+	orderRepo *repository.OrderRepository
+	fetcher   *FetcherService
+	hub       *marketdata.Hub
+	bus       *events.Bus
+
+	trades  map[int]*model.DBTrade              // Open trades by ID
+	streams map[string]*marketdata.Subscription // Trade streams of the symbols with open trades
+	prices  map[string]float64                  // Latest trade price per symbol
+	retryAt map[int]time.Time                   // Trades that failed to close, and when to try again
+	dirty   map[int]bool                        // Trades whose exit rules moved on since they were last stored
+	mu      sync.Mutex
+	// ops serialises the work on the trades, so that exit rules set through the API do not race a closing order
+	ops sync.Mutex
+}
+
+// NewPositionManager creates a new position manager.
+func NewPositionManager(cfg *config.Config, exchanges map[string]exchange.Exchange, tradeRepo *repository.TradeRepository, orderRepo *repository.OrderRepository, fetcher *FetcherService, hub *marketdata.Hub, bus *events.Bus) *PositionManager {
+	return &PositionManager{
+		cfg:       cfg,
+		exchanges: exchanges,
+		tradeRepo: tradeRepo,
+		orderRepo: orderRepo,
+		fetcher:   fetcher,
+		hub:       hub,
+		bus:       bus,
+		trades:    make(map[int]*model.DBTrade),
+		streams:   make(map[string]*marketdata.Subscription),
+		prices:    make(map[string]float64),
+		retryAt:   make(map[int]time.Time),
+		dirty:     make(map[int]bool),
+	}
+}
+
+// Start manages the open trades until ctx is cancelled. Trades opened later are picked up from the event bus.
+func (m *PositionManager) Start(ctx context.Context) {
+	updates := m.bus.Subscribe(events.TradeOpened, events.OrderFilled)
+	defer updates.Close()
+	defer m.closeStreams()
+	defer m.flush()
+
+	m.run(func() { m.refresh(ctx) })
+	check := time.NewTicker(positionCheckInterval)
+	defer check.Stop()
+	refresh := time.NewTicker(positionRefreshInterval)
+	defer refresh.Stop()
+	for {
+		select {
+		case e := <-updates.C:
+			m.run(func() { m.handleEvent(ctx, e) })
+		case <-check.C:
+			m.run(func() { m.checkAll(ctx) })
+		case <-refresh.C:
+			m.run(func() { m.refresh(ctx) })
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (m *PositionManager) run(op func()) {
+	m.ops.Lock()
+	defer m.ops.Unlock()
+	op()
+}
+
+// Trades returns the open trades being managed.
+func (m *PositionManager) Trades() []*model.DBTrade {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	trades := make([]*model.DBTrade, 0, len(m.trades))
+	for _, t := range m.trades {
+		trades = append(trades, copyTrade(t))
+	}
+	return trades
+}
+
+// SetExitRules replaces the exit rules of one of the user's open trades, or removes them when rules is nil.
+// The state already reached is kept: the best price, the profit and loss of the parts already closed,
+// and the stop, which never moves back.
+func (m *PositionManager) SetExitRules(userID, tradeID int, rules *model.ExitRules) (*model.DBTrade, error) {
+	m.ops.Lock()
+	defer m.ops.Unlock()
+
+	m.mu.Lock()
+	tracked, ok := m.trades[tradeID]
+	var t *model.DBTrade
+	if ok {
+		t = copyTrade(tracked)
+	}
+	m.mu.Unlock()
+	if !ok || t.UserID != userID {
+		return nil, ErrTradeNotFound
+	}
+
+	if rules != nil {
+		if err := rules.Validate(); err != nil {
+			return nil, err
+		}
+		rules = rules.Copy()
+		rules.ATR, rules.InitialQuantity, rules.BestPrice, rules.StopReason, rules.RealizedPnL = 0, 0, 0, "", 0
+		if old := t.ExitRules; old != nil {
+			rules.InitialQuantity, rules.BestPrice, rules.StopReason, rules.RealizedPnL = old.InitialQuantity, old.BestPrice, old.StopReason, old.RealizedPnL
+		}
+		if err := rules.Start(t.Side, t.Price, t.Quantity); err != nil {
+			return nil, err
+		}
+		if len(rules.TakeProfits) > 0 {
+			t.TakeProfit = 0
+		}
+	} else if old := t.ExitRules; old != nil && old.InitialQuantity > t.Quantity {
+		// Without rules, the parts already closed still count in the trade's profit and loss
+		rules = &model.ExitRules{InitialQuantity: old.InitialQuantity, StopReason: old.StopReason, RealizedPnL: old.RealizedPnL}
+	}
+	t.ExitRules = rules
+	m.prepare(t)
+
+	if err := m.tradeRepo.UpdateTrade(t); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	if _, ok := m.trades[t.ID]; ok {
+		m.trades[t.ID] = t
+		delete(m.dirty, t.ID)
+	}
+	m.mu.Unlock()
+	m.bus.Publish(events.Event{Type: events.TradeUpdated, UserID: t.UserID, Symbol: t.Symbol, Data: copyTrade(t)})
+	return copyTrade(t), nil
+}
+
+// CloseTrade closes an open trade at market the way the manager's own exits do: the protective OCO is released
+// as part of the close, the position is sold, or for shorts bought back, and the trade is stored as closed with
+// its realised profit and loss. A trade not managed yet, such as one opened moments ago, is taken on first.
+// If the closing order fails, the trade stays open and managed.
+func (m *PositionManager) CloseTrade(ctx context.Context, trade *model.DBTrade, price float64, reason string) (*model.DBTrade, error) {
+	m.ops.Lock()
+	defer m.ops.Unlock()
+
+	m.mu.Lock()
+	tracked, ok := m.trades[trade.ID]
+	m.mu.Unlock()
+	if !ok {
+		if _, known := m.exchanges[trade.Exchange]; !known || trade.Status != model.TradeOpen {
+			return nil, ErrTradeNotFound
+		}
+		tracked = copyTrade(trade)
+		m.track(tracked)
+	}
+	t := copyTrade(tracked)
+	if err := m.exit(ctx, t, t.Quantity, price, reason); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// handleEvent starts managing newly opened trades, and records the fills of protective OCOs.
+func (m *PositionManager) handleEvent(ctx context.Context, e events.Event) {
+	switch e.Type {
+	case events.TradeOpened:
+		if t, ok := e.Data.(*model.DBTrade); ok && t.ID != 0 {
+			m.track(copyTrade(t))
+		}
+	case events.OrderFilled:
+		order, ok := e.Data.(*model.Order)
+		if !ok || order.OrderListID == "" {
+			return
+		}
+		for _, t := range m.Trades() {
+			if t.UserID == order.UserID && t.Exchange == order.Exchange && t.ProtectionListID == order.OrderListID {
+				m.settle(t, order.AvgPrice, order.FilledQuantity, order, protectionReason(order))
+			}
+		}
+	}
+}
+
+// refresh reloads the open trades of every user, follows the trade streams of their symbols,
+// and reads the protective OCOs back from the exchanges to catch legs that filled.
+// Exit rule state that moved on is stored first, so that it is not lost by the reload.
+func (m *PositionManager) refresh(ctx context.Context) {
+	m.flush()
+	userIDs, err := m.tradeRepo.GetOpenTradeUserIDs()
+	if err != nil {
+		log.Printf("Position manager: error loading users with open trades: %v", err)
+		return
+	}
+	trades := make(map[int]*model.DBTrade)
+	for _, userID := range userIDs {
+		open, err := m.tradeRepo.GetOpenTradesByUserID(userID)
+		if err != nil {
+			log.Printf("Position manager: error loading open trades of user %d: %v", userID, err)
+			return
+		}
+		for _, t := range open {
+			if _, ok := m.exchanges[t.Exchange]; !ok {
+				// Trades of unknown exchanges cannot be closed; they stay as they are
+				continue
+			}
+			m.prepare(t)
+			trades[t.ID] = t
+		}
+	}
+
+	m.mu.Lock()
+	m.trades = trades
+	for id := range m.retryAt {
+		if _, ok := trades[id]; !ok {
+			delete(m.retryAt, id)
+		}
+	}
+	m.mu.Unlock()
+	m.follow()
+
+	for _, t := range m.Trades() {
+		if t.ProtectionListID != "" {
+			m.syncProtection(ctx, t)
+		}
+	}
+}
+
+// track starts managing a trade.
+func (m *PositionManager) track(t *model.DBTrade) {
+	if _, ok := m.exchanges[t.Exchange]; !ok {
+		return
+	}
+	m.prepare(t)
+	m.mu.Lock()
+	m.trades[t.ID] = t
+	m.mu.Unlock()
+	m.follow()
+}
+
+// follow subscribes to the trade stream of every symbol with open trades and closes the others.
+func (m *PositionManager) follow() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	wanted := make(map[string]bool)
+	for _, t := range m.trades {
+		wanted[t.Symbol] = true
+	}
+	for symbol, sub := range m.streams {
+		if !wanted[symbol] {
+			sub.Close()
+			delete(m.streams, symbol)
+			delete(m.prices, symbol)
+		}
+	}
+	for symbol := range wanted {
+		if _, ok := m.streams[symbol]; ok {
+			continue
+		}
+		sub, err := m.hub.Subscribe(marketdata.Stream{Symbol: symbol, Kind: marketdata.KindTrade})
+		if err != nil {
+			log.Printf("Position manager: error subscribing to %s trades: %v", symbol, err)
+			continue
+		}
+		m.streams[symbol] = sub
+		go func(symbol string) {
+			for e := range sub.C {
+				m.mu.Lock()
+				m.prices[symbol] = e.Price
+				m.mu.Unlock()
+			}
+		}(symbol)
+	}
+}
+
+// prepare measures the average true range of a trade whose ATR trailing stop has none yet, and stores it.
+func (m *PositionManager) prepare(t *model.DBTrade) {
+	rules := t.ExitRules
+	if rules == nil || rules.TrailingStopATR <= 0 || rules.ATR > 0 {
+		return
+	}
+	interval, period := rules.ATRSettings()
+	// Wilder's smoothing starts from a simple average, so a few periods of history let it settle
+	candles, err := m.fetcher.FetchCandles(t.Symbol, interval, 3*period+1)
+	if err != nil {
+		log.Printf("[%s] Error loading candles for the ATR of trade %d: %v", t.Symbol, t.ID, err)
+		return
+	}
+	atr := indicators.ATR(candles, period)
+	if len(atr) == 0 || math.IsNaN(atr[len(atr)-1]) || atr[len(atr)-1] <= 0 {
+		return
+	}
+	rules.ATR = atr[len(atr)-1]
+	if err := m.tradeRepo.UpdateTrade(t); err != nil {
+		log.Printf("[%s] Error storing the ATR of trade %d: %v", t.Symbol, t.ID, err)
+	}
+}
+
+// flush stores the trades whose exit rules moved on.
+func (m *PositionManager) flush() {
+	m.mu.Lock()
+	var trades []*model.DBTrade
+	for id := range m.dirty {
+		if t, ok := m.trades[id]; ok {
+			trades = append(trades, copyTrade(t))
+		}
+	}
+	m.dirty = make(map[int]bool)
+	m.mu.Unlock()
+
+	for _, t := range trades {
+		if err := m.tradeRepo.UpdateTrade(t); err != nil {
+			log.Printf("[%s] Error storing the exit rules of trade %d: %v", t.Symbol, t.ID, err)
+		}
+	}
+}
+
+func (m *PositionManager) closeStreams() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for symbol, sub := range m.streams {
+		sub.Close()
+		delete(m.streams, symbol)
+	}
+}
+
+// checkAll moves the stops of the trades with exit rules, and closes the trades, or the parts of them,
+// whose exit conditions are met at the latest prices.
+func (m *PositionManager) checkAll(ctx context.Context) {
+	now := time.Now()
+	type exit struct {
+		trade  *model.DBTrade
+		price  float64
+		reason string
+		level  int // Take profit ladder level of a partial exit, or -1 to close the whole trade
+	}
+	var exits []exit
+	m.mu.Lock()
+	for id, t := range m.trades {
+		price := m.prices[t.Symbol]
+		if price <= 0 {
+			continue
+		}
+		if t.ExitRules != nil && advanceStop(t, price) {
+			m.dirty[id] = true
+		}
+		if now.Before(m.retryAt[id]) {
+			continue
+		}
+		if reason := exitReason(t, price, now, m.cfg.AutoTradeMaxDuration); reason != "" {
+			exits = append(exits, exit{trade: copyTrade(t), price: price, reason: reason, level: -1})
+		} else if t.ExitRules != nil {
+			if level := t.ExitRules.NextTakeProfit(t.Side, price); level >= 0 {
+				exits = append(exits, exit{trade: copyTrade(t), price: price, reason: CloseTakeProfit, level: level})
+			}
+		}
+	}
+	m.mu.Unlock()
+
+	for _, e := range exits {
+		var err error
+		if e.level >= 0 {
+			err = m.takeProfit(ctx, e.trade, e.level, e.price)
+		} else {
+			err = m.closeTrade(ctx, e.trade, e.price, e.reason)
+		}
+		if err != nil {
+			log.Printf("[%s] Error closing trade %d of user %d (%s at %.4f), retrying in %s: %v",
+				e.trade.Symbol, e.trade.ID, e.trade.UserID, e.reason, e.price, positionRetryDelay, err)
+			m.mu.Lock()
+			m.retryAt[e.trade.ID] = time.Now().Add(positionRetryDelay)
+			m.mu.Unlock()
+		}
+	}
+}
+
+// advanceStop moves the stop loss of a trade with exit rules to where the rules ask for at price,
+// and reports whether the rules' state changed. The stop only ever moves in the trade's favour.
+func advanceStop(t *model.DBTrade, price float64) bool {
+	rules := t.ExitRules
+	best := rules.BestPrice
+	stop, reason := rules.Advance(t.Side, t.Price, price)
+	changed := rules.BestPrice != best
+	if stop > 0 && (t.StopLoss <= 0 || (t.Side == "BUY" && stop > t.StopLoss) || (t.Side != "BUY" && stop < t.StopLoss)) {
+		if reason != rules.StopReason {
+			log.Printf("[%s] Stop of trade %d of user %d moved by %s to %.4f", t.Symbol, t.ID, t.UserID, reason, stop)
+		}
+		t.StopLoss, rules.StopReason = stop, reason
+		changed = true
+	}
+	return changed
+}
+
+// exitReason returns why a trade should be closed at price, or "" if it should stay open.
+// Short trades profit when the price falls, so their levels are mirrored. A stop moved by
+// exit rules closes the trade for the rule that last moved it.
+func exitReason(t *model.DBTrade, price float64, now time.Time, maxDuration time.Duration) string {
+	long := t.Side == "BUY"
+	switch {
+	case t.TakeProfit > 0 && ((long && price >= t.TakeProfit) || (!long && price <= t.TakeProfit)):
+		return CloseTakeProfit
+	case t.StopLoss > 0 && ((long && price <= t.StopLoss) || (!long && price >= t.StopLoss)):
+		if t.ExitRules != nil && t.ExitRules.StopReason != "" {
+			return t.ExitRules.StopReason
+		}
+		return CloseStopLoss
+	case maxDuration > 0 && now.Sub(t.ExecutedAt) >= maxDuration:
+		return CloseDuration
+	}
+	return ""
+}
+
+// closeTrade exits a trade at market: it releases the protective OCO, if any, then places the opposite order.
+// If a leg of the OCO has already filled, the trade is recorded as closed by it instead.
+func (m *PositionManager) closeTrade(ctx context.Context, t *model.DBTrade, price float64, reason string) error {
+	return m.exit(ctx, t, t.Quantity, price, reason)
+}
+
+// takeProfit closes the part of a trade due at a level of its take profit ladder. The last part closes the whole trade.
+func (m *PositionManager) takeProfit(ctx context.Context, t *model.DBTrade, level int, price float64) error {
+	rules := t.ExitRules
+	rules.TakeProfits[level].Done = true
+	quantity := math.Min(rules.InitialQuantity*rules.TakeProfits[level].Percent/100, t.Quantity)
+	if t.Quantity-quantity <= rules.InitialQuantity*positionDustFraction {
+		quantity = t.Quantity
+	}
+	return m.exit(ctx, t, quantity, price, CloseTakeProfit)
+}
+
+// exit sells, or for shorts buys back, quantity of a trade at market, and closes the trade if that is all of it.
+func (m *PositionManager) exit(ctx context.Context, t *model.DBTrade, quantity, price float64, reason string) error {
+	ex := m.exchanges[t.Exchange]
+	ctx = exchange.WithUserID(ctx, t.UserID)
+	base, _, err := exchange.SplitSymbol(t.Symbol)
+	if err != nil {
+		return err
+	}
+
+	if t.ProtectionListID != "" {
+		if filled, open := m.protection(ctx, ex, t); filled != nil {
+			m.settle(t, filled.AvgPrice, filled.FilledQuantity, filled, protectionReason(filled))
+			return nil
+		} else if open != nil {
+			// Cancelling one leg cancels the whole bracket and frees the base asset it holds
+			if _, err := ex.CancelOrder(ctx, t.Symbol, open.ExchangeOrderID); err != nil {
+				return fmt.Errorf("failed to cancel protective OCO %s: %w", t.ProtectionListID, err)
+			}
+		}
+		t.ProtectionListID = ""
+	}
+
+	side, full := "SELL", quantity >= t.Quantity
+	if t.Side == "BUY" {
+		// Base fees on the entry leave slightly less than the traded quantity to sell
+		balance, err := ex.GetBalance(ctx, base)
+		if err != nil {
+			return fmt.Errorf("failed to get %s balance: %w", base, err)
+		}
+		quantity = math.Min(quantity, balance)
+	} else {
+		side = "BUY"
+	}
+	if quantity <= 0 {
+		return fmt.Errorf("no %s left to close the position with", base)
+	}
+
+	req := exchange.MarketOrder(t.Symbol, side, quantity)
+	req.ReduceOnly = true
+	order, err := ex.PlaceOrder(ctx, req)
+	if err != nil {
+		return fmt.Errorf("failed to place closing order: %w", err)
+	}
+	exitPrice := price
+	if order.FilledQuantity > 0 {
+		exitPrice, quantity = order.AvgPrice, order.FilledQuantity
+	}
+	if !full {
+		log.Printf("[%s] Took profit on part of %s trade %d of user %d on %s: %s %.8f at %.4f (order %s)",
+			t.Symbol, t.Side, t.ID, t.UserID, t.Exchange, side, quantity, exitPrice, order.ExchangeOrderID)
+		m.settlePart(t, exitPrice, quantity, order)
+		return nil
+	}
+	log.Printf("[%s] Closed %s trade %d of user %d on %s by %s: %s %.8f at %.4f (order %s)",
+		t.Symbol, t.Side, t.ID, t.UserID, t.Exchange, reason, side, quantity, exitPrice, order.ExchangeOrderID)
+	m.settle(t, exitPrice, quantity, order, reason)
+	return nil
+}
+
+// settlePart stores the part of a trade closed at exitPrice: the trade keeps the rest of its quantity open,
+// and its profit and loss becomes that of the parts closed so far, net of all fees paid.
+func (m *PositionManager) settlePart(t *model.DBTrade, exitPrice, quantity float64, exit *model.Order) {
+	base, quote, _ := exchange.SplitSymbol(t.Symbol)
+	t.ExitRules.RealizedPnL += grossPnL(t, exitPrice, quantity)
+	t.Fees += feeInQuote(exit, base, quote, exitPrice)
+	t.Quantity -= quantity
+	t.ProfitLoss = t.ExitRules.RealizedPnL - t.Fees
+	if err := m.tradeRepo.UpdateTrade(t); err != nil {
+		log.Printf("[%s] Error storing partly closed trade %d of user %d: %v", t.Symbol, t.ID, t.UserID, err)
+	}
+
+	m.mu.Lock()
+	if _, ok := m.trades[t.ID]; ok {
+		m.trades[t.ID] = t
+		delete(m.dirty, t.ID)
+	}
+	m.mu.Unlock()
+	m.bus.Publish(events.Event{Type: events.TradeUpdated, UserID: t.UserID, Symbol: t.Symbol, Data: copyTrade(t)})
+}
+
+// protection reads the legs of a trade's protective OCO back from the exchange, and returns the leg that filled,
+// or otherwise a leg that is still open. Both are nil once the bracket has been cancelled.
+func (m *PositionManager) protection(ctx context.Context, ex exchange.Exchange, t *model.DBTrade) (filled, open *model.Order) {
+	legs, err := m.orderRepo.GetOrdersByListID(t.UserID, t.Exchange, t.ProtectionListID)
+	if err != nil {
+		log.Printf("[%s] Error loading protective OCO %s of trade %d: %v", t.Symbol, t.ProtectionListID, t.ID, err)
+		return nil, nil
+	}
+	for _, leg := range legs {
+		if leg.IsOpen() {
+			// Reading the leg also stores its current state
+			current, err := ex.GetOrder(ctx, t.Symbol, leg.ExchangeOrderID)
+			if err != nil {
+				log.Printf("[%s] Error reading protective order %s: %v", t.Symbol, leg.ExchangeOrderID, err)
+				open = leg
+				continue
+			}
+			leg = current
+		}
+		switch {
+		case leg.Status == model.OrderFilled:
+			return leg, nil
+		case leg.IsOpen():
+			open = leg
+		}
+	}
+	return nil, open
+}
+
+// syncProtection records a trade as closed if a leg of its protective OCO has filled,
+// and forgets the OCO if it has been cancelled, for instance by hand on the exchange.
+func (m *PositionManager) syncProtection(ctx context.Context, t *model.DBTrade) {
+	filled, open := m.protection(exchange.WithUserID(ctx, t.UserID), m.exchanges[t.Exchange], t)
+	switch {
+	case filled != nil:
+		m.settle(t, filled.AvgPrice, filled.FilledQuantity, filled, protectionReason(filled))
+	case open == nil:
+		t.ProtectionListID = ""
+		if err := m.tradeRepo.UpdateTrade(t); err != nil {
+			log.Printf("[%s] Error updating trade %d: %v", t.Symbol, t.ID, err)
+			return
+		}
+		m.mu.Lock()
+		if tracked, ok := m.trades[t.ID]; ok {
+			tracked.ProtectionListID = ""
+		}
+		m.mu.Unlock()
+	}
+}
+
+// settle stores a trade as closed at exitPrice with its profit and loss net of the entry fees and of the exit order's fee.
+// For a trade whose ladder closed parts of it before, the profit and loss includes those parts, the quantity is the
+// opening quantity again, and the exit price is the average of all its exits. Only the first settlement of a trade counts.
+func (m *PositionManager) settle(t *model.DBTrade, exitPrice, quantity float64, exit *model.Order, reason string) {
+	m.mu.Lock()
+	if _, ok := m.trades[t.ID]; !ok {
+		m.mu.Unlock()
+		return
+	}
+	delete(m.trades, t.ID)
+	delete(m.retryAt, t.ID)
+	m.mu.Unlock()
+
+	if quantity <= 0 {
+		quantity = t.Quantity
+	}
+	base, quote, _ := exchange.SplitSymbol(t.Symbol)
+	gross := grossPnL(t, exitPrice, quantity)
+	now := time.Now()
+	t.Fees += feeInQuote(exit, base, quote, exitPrice)
+	t.ExitPrice = exitPrice
+	if rules := t.ExitRules; rules != nil && rules.InitialQuantity > t.Quantity {
+		closed := quantity + rules.InitialQuantity - t.Quantity
+		gross += rules.RealizedPnL
+		t.Quantity = rules.InitialQuantity
+		// The gross profit and loss is the distance of the average exit from the entry, times the quantity closed
+		t.ExitPrice = t.Price + gross/closed
+		if t.Side == "SELL" {
+			t.ExitPrice = t.Price - gross/closed
+		}
+	}
+	t.ProfitLoss = gross - t.Fees
+	t.CloseReason = reason
+	t.Status = model.TradeClosed
+	t.ClosedAt = &now
+	if err := m.tradeRepo.UpdateTrade(t); err != nil {
+		log.Printf("[%s] Error storing closed trade %d of user %d: %v", t.Symbol, t.ID, t.UserID, err)
+	}
+	log.Printf("[%s] Trade %d of user %d closed by %s at %.4f, profit and loss %.4f %s", t.Symbol, t.ID, t.UserID, reason, exitPrice, t.ProfitLoss, quote)
+	m.bus.Publish(events.Event{Type: events.TradeClosed, UserID: t.UserID, Symbol: t.Symbol, Data: t, Time: now})
+}
+
+// grossPnL returns the profit and loss of closing quantity of a trade at exitPrice, before fees.
+func grossPnL(t *model.DBTrade, exitPrice, quantity float64) float64 {
+	gross := (exitPrice - t.Price) * quantity
+	if t.Side == "SELL" {
+		gross = -gross
+	}
+	return gross
+}
+
+// copyTrade returns a copy of a trade that shares no exit rule state with it.
+func copyTrade(t *model.DBTrade) *model.DBTrade {
+	copied := *t
+	if t.ExitRules != nil {
+		copied.ExitRules = t.ExitRules.Copy()
+	}
+	return &copied
+}
+
+// protectionReason returns the close reason of a trade closed by a leg of its protective OCO.
+func protectionReason(leg *model.Order) string {
+	if leg.StopPrice > 0 {
+		return CloseStopLoss
+	}
+	return CloseTakeProfit
+}
+
+// feeInQuote values the fee of an order in the quote asset. Fees in a third asset, such as BNB,
+// cannot be valued from the symbol's price and count as 0.
+func feeInQuote(order *model.Order, base, quote string, price float64) float64 {
+	switch order.FeeAsset {
+	case quote:
+		return order.Fee
+	case base:
+		return order.Fee * price
+	}
+	return 0
+}
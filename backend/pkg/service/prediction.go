@@ -1,99 +1,81 @@
 package service
 
 import (
+	"fmt"
 	"math"
+	"sync"
 
 	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
 )
 
 // PredictionService encapsulates the logic for making trading predictions.
+// Predictions are scored by weighted indicators from its registry, as chosen by a scoring profile.
 type PredictionService struct {
-	// Dependencies like a logger could be added here later.
+	indicators map[string]Indicator
+	mu         sync.RWMutex
 }
 
-// NewPredictionService creates a new PredictionService.
+// NewPredictionService creates a new PredictionService with the built-in indicators registered.
 func NewPredictionService() *PredictionService {
-	return &PredictionService{}
+	return &PredictionService{indicators: defaultIndicators()}
 }
 
-// PredictionParameters holds the configuration for the prediction indicators.
-type PredictionParameters struct {
-	RSI_Period           int
-	MACD_Fast_Period     int
-	MACD_Slow_Period     int
-	MACD_Signal_Period   int
-	BBands_Period        int
-	BBands_StdDev_Factor float64
-}
-
-// DefaultPredictionParams returns a default set of parameters.
-func (s *PredictionService) DefaultPredictionParams() *PredictionParameters {
-	return &PredictionParameters{
-		RSI_Period:           14,
-		MACD_Fast_Period:     12,
-		MACD_Slow_Period:     26,
-		MACD_Signal_Period:   9,
-		BBands_Period:        20,
-		BBands_StdDev_Factor: 2.0,
+// AdvancedPredictBuySell scores the candles with the indicators of a scoring profile to generate a trading signal.
+// Each indicator votes from -1 (sell) to 1 (buy), times its weight; the signal follows the larger side, and the
+// confidence is the margin between the sides as a share of the profile's total weight.
+// The candles must be sorted oldest to newest. A nil profile uses the default profile.
+func (s *PredictionService) AdvancedPredictBuySell(pair string, candles []model.Candle, profile *model.ScoringProfile) model.Prediction {
+	if profile == nil {
+		profile = s.DefaultProfile()
 	}
-}
-
-// AdvancedPredictBuySell uses a combination of technical indicators to generate a trading signal.
-func (s *PredictionService) AdvancedPredictBuySell(pair string, data []model.ForexData, params *PredictionParameters) model.Prediction {
 	currentPrice := 0.0
-	if len(data) > 0 {
-		currentPrice = data[0].Price
+	if len(candles) > 0 {
+		currentPrice = candles[len(candles)-1].Close
 	}
 
-	requiredDataPoints := max(params.RSI_Period, params.MACD_Slow_Period, params.BBands_Period) + params.MACD_Signal_Period
-	if len(data) < requiredDataPoints {
-		return model.Prediction{Pair: pair, Signal: "hold", Confidence: 0, Price: currentPrice, Reason: "not enough historical data for a reliable prediction"}
+	type weighted struct {
+		model.IndicatorWeight
+		indicator Indicator
+		params    map[string]float64
 	}
-
-	prices := make([]float64, len(data))
-	for i, d := range data {
-		prices[len(data)-1-i] = d.Price // Reverse for chronological calculations
-	}
-
-	var buyScore, sellScore int
-
-	// 1. RSI
-	rsi := calculateRSI(prices, params.RSI_Period)
-	if rsi < 30 {
-		buyScore++
-	} else if rsi > 70 {
-		sellScore++
-	}
-
-	// 2. MACD
-	macdLine, signalLine := calculateMACD(prices, params.MACD_Fast_Period, params.MACD_Slow_Period, params.MACD_Signal_Period)
-	if len(macdLine) > 1 && len(signalLine) > 1 {
-		if macdLine[len(macdLine)-1] > signalLine[len(signalLine)-1] && macdLine[len(macdLine)-2] <= signalLine[len(signalLine)-2] {
-			buyScore++ // Bullish crossover
-		} else if macdLine[len(macdLine)-1] < signalLine[len(signalLine)-1] && macdLine[len(macdLine)-2] >= signalLine[len(signalLine)-2] {
-			sellScore++ // Bearish crossover
+	var votes []weighted
+	requiredDataPoints := 0
+	for _, w := range profile.Indicators {
+		indicator, ok := s.indicator(w.Name)
+		if !ok {
+			return model.Prediction{Pair: pair, Signal: "hold", Price: currentPrice, Profile: profile.Name, Reason: fmt.Sprintf("unknown indicator %q", w.Name)}
 		}
-	}
-
-	// 3. Bollinger Bands
-	_, upperBand, lowerBand := calculateBollingerBands(prices, params.BBands_Period, params.BBands_StdDev_Factor)
-	latestPrice := prices[len(prices)-1]
-	if latestPrice < lowerBand[len(lowerBand)-1] {
-		buyScore += 2 // Strong buy signal
-	} else if latestPrice > upperBand[len(upperBand)-1] {
-		sellScore += 2 // Strong sell signal
+		params := indicatorParams(indicator, w)
+		requiredDataPoints = max(requiredDataPoints, indicator.Lookback(params))
+		votes = append(votes, weighted{IndicatorWeight: w, indicator: indicator, params: params})
+	}
+	if len(candles) < requiredDataPoints {
+		return model.Prediction{Pair: pair, Signal: "hold", Confidence: 0, Price: currentPrice, Profile: profile.Name, Reason: "not enough historical data for a reliable prediction"}
+	}
+
+	var buyScore, sellScore, totalPossibleScore float64
+	breakdown := make([]model.IndicatorScore, 0, len(votes))
+	for _, v := range votes {
+		vote, values, reason := v.indicator.Vote(candles, v.params)
+		vote = math.Max(-1, math.Min(1, vote))
+		score := vote * v.Weight
+		if score > 0 {
+			buyScore += score
+		} else {
+			sellScore -= score
+		}
+		totalPossibleScore += v.Weight
+		breakdown = append(breakdown, model.IndicatorScore{Name: v.Name, Vote: vote, Weight: v.Weight, Score: score, Values: values, Reason: reason})
 	}
 
 	var signal string
 	var confidence float64
-	totalPossibleScore := 4.0
-
 	if buyScore > sellScore {
 		signal = "buy"
-		confidence = float64(buyScore-sellScore) / totalPossibleScore
+		confidence = (buyScore - sellScore) / totalPossibleScore
 	} else if sellScore > buyScore {
 		signal = "sell"
-		confidence = float64(sellScore-buyScore) / totalPossibleScore
+		confidence = (sellScore - buyScore) / totalPossibleScore
 	} else {
 		signal = "hold"
 		confidence = 0
@@ -103,136 +85,11 @@ func (s *PredictionService) AdvancedPredictBuySell(pair string, data []model.For
 		Pair:       pair,
 		Signal:     signal,
 		Confidence: confidence,
-		Price:      latestPrice,
-		Reason:     "Advanced model prediction",
-	}
-}
-
-// --- Calculation Helper Functions (unchanged from your version) ---
-
-func calculateRSI(prices []float64, period int) float64 {
-	if len(prices) <= period {
-		return 50 // Neutral RSI
-	}
-	var gains, losses []float64
-	for i := 1; i < len(prices); i++ {
-		change := prices[i] - prices[i-1]
-		if change > 0 {
-			gains = append(gains, change)
-			losses = append(losses, 0)
-		} else {
-			gains = append(gains, 0)
-			losses = append(losses, -change)
-		}
-	}
-	avgGain := simpleMovingAverage(gains, period)
-	avgLoss := simpleMovingAverage(losses, period)
-	if len(avgGain) == 0 || len(avgLoss) == 0 || avgLoss[len(avgLoss)-1] == 0 {
-		return 50 // Neutral RSI
-	}
-	rs := avgGain[len(avgGain)-1] / avgLoss[len(avgLoss)-1]
-	return 100 - (100 / (1 + rs))
-}
-
-func calculateMACD(prices []float64, fastPeriod, slowPeriod, signalPeriod int) (macdLine, signalLine []float64) {
-	emaFast := exponentialMovingAverage(prices, fastPeriod)
-	emaSlow := exponentialMovingAverage(prices, slowPeriod)
-	if len(emaSlow) > len(emaFast) {
-		emaSlow = emaSlow[len(emaSlow)-len(emaFast):]
-	}
-	offset := len(emaFast) - len(emaSlow)
-	macdLine = make([]float64, len(emaSlow))
-	for i := range emaSlow {
-		macdLine[i] = emaFast[i+offset] - emaSlow[i]
-	}
-	signalLine = exponentialMovingAverage(macdLine, signalPeriod)
-	return
-}
-
-func calculateBollingerBands(prices []float64, period int, stdDevFactor float64) (middle, upper, lower []float64) {
-	sma := simpleMovingAverage(prices, period)
-	stdDev := standardDeviation(prices, period)
-	if len(sma) > len(stdDev) {
-		sma = sma[len(sma)-len(stdDev):]
-	} else if len(stdDev) > len(sma) {
-		stdDev = stdDev[len(stdDev)-len(sma):]
-	}
-	upper = make([]float64, len(sma))
-	lower = make([]float64, len(sma))
-	for i := range sma {
-		upper[i] = sma[i] + (stdDev[i] * stdDevFactor)
-		lower[i] = sma[i] - (stdDev[i] * stdDevFactor)
-	}
-	return sma, upper, lower
-}
-
-func exponentialMovingAverage(data []float64, period int) []float64 {
-	if len(data) < period {
-		return nil
-	}
-	emas := make([]float64, len(data)-period+1)
-	multiplier := 2.0 / (float64(period) + 1.0)
-	sum := 0.0
-	for i := 0; i < period; i++ {
-		sum += data[i]
-	}
-	emas[0] = sum / float64(period)
-	for i := 1; i <= len(data)-period; i++ {
-		emas[i] = (data[i+period-1]-emas[i-1])*multiplier + emas[i-1]
-	}
-	return emas
-}
-
-func simpleMovingAverage(data []float64, period int) []float64 {
-	if len(data) < period {
-		return nil
-	}
-	smas := make([]float64, len(data)-period+1)
-	sum := 0.0
-	for i := 0; i < period; i++ {
-		sum += data[i]
-	}
-	smas[0] = sum / float64(period)
-	for i := 1; i <= len(data)-period; i++ {
-		sum = sum - data[i-1] + data[i+period-1]
-		smas[i] = sum / float64(period)
-	}
-	return smas
-}
-
-func standardDeviation(data []float64, period int) []float64 {
-	if len(data) < period {
-		return nil
-	}
-	stdDevs := make([]float64, len(data)-period+1)
-	for i := 0; i <= len(data)-period; i++ {
-		slice := data[i : i+period]
-		sum := 0.0
-		for _, val := range slice {
-			sum += val
-		}
-		mean := sum / float64(period)
-		varianceSum := 0.0
-		for _, val := range slice {
-			varianceSum += math.Pow(val-mean, 2)
-		}
-		variance := varianceSum / float64(period)
-		stdDevs[i] = math.Sqrt(variance)
-	}
-	return stdDevs
-}
-
-func max(vars ...int) int {
-	maxVal := 0
-	if len(vars) > 0 {
-		maxVal = vars[0]
-	}
-	for _, i := range vars {
-		if i > maxVal {
-			maxVal = i
-		}
+		Price:      currentPrice,
+		Reason:     fmt.Sprintf("buy score %g, sell score %g out of %g with profile %s", buyScore, sellScore, totalPossibleScore, profile.Name),
+		Profile:    profile.Name,
+		Breakdown:  breakdown,
 	}
-	return maxVal
 }
 
 // CalculatePositionSize returns the size of the position based on risk management.
@@ -1,92 +1,433 @@
 package service
 
 import (
-	"context"
+	"encoding/json"
+	"fmt"
 	"log"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gofiber/websocket/v2"
-	"github.com/ratheeshkumar25/forex_bot/backend/pkg/exchange"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/events"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/marketdata"
 )
 
-// PriceStreamer handles real-time price streaming via WebSocket
+// Channels of the price stream. Market channels carry a symbol's market data from the hub,
+// signals and workers carry public events of the event bus, and the private channels carry
+// the authenticated user's own orders and positions.
+const (
+	ChannelTicker    = "ticker"
+	ChannelTrade     = "trade"
+	ChannelKline     = "kline" // Subscribed with its interval, e.g. kline:5m
+	ChannelSignals   = "signals"
+	ChannelWorkers   = "workers"
+	ChannelOrders    = "orders"
+	ChannelPositions = "positions"
+)
+
+// Client operations
+const (
+	OpSubscribe   = "subscribe"
+	OpUnsubscribe = "unsubscribe"
+	OpAuth        = "auth"
+	OpPing        = "ping"
+)
+
+// Frame types sent to clients
+const (
+	FrameAck       = "ack"
+	FrameError     = "error"
+	FrameData      = "data"
+	FrameHeartbeat = "heartbeat"
+	FramePong      = "pong"
+)
+
+const (
+	heartbeatInterval = 30 * time.Second
+	// pongWait is how long a client may stay silent, counting pongs to the heartbeat pings, before it is dropped
+	pongWait  = 2*heartbeatInterval + 10*time.Second
+	writeWait = 10 * time.Second
+	// clientBuffer is the number of frames queued per client; a slow client misses frames beyond it
+	clientBuffer     = 256
+	maxSubscriptions = 50
+)
+
+// ClientMessage is a request sent by a client on the price stream.
+type ClientMessage struct {
+	Op      string `json:"op"`                // subscribe, unsubscribe, auth or ping
+	ID      string `json:"id,omitempty"`      // Echoed in the ack or error frame answering the request
+	Channel string `json:"channel,omitempty"` // e.g. ticker, kline:5m, orders
+	Symbol  string `json:"symbol,omitempty"`  // Required by market channels; optional filter on the others
+	Token   string `json:"token,omitempty"`   // JWT of the auth op
+}
+
+// Frame is a message sent to a client on the price stream.
+type Frame struct {
+	Type    string      `json:"type"` // ack, error, data, heartbeat or pong
+	ID      string      `json:"id,omitempty"`
+	Op      string      `json:"op,omitempty"`
+	Channel string      `json:"channel,omitempty"`
+	Symbol  string      `json:"symbol,omitempty"`
+	Event   string      `json:"event,omitempty"` // Event bus type of a data frame on an event channel, e.g. order.filled
+	Data    interface{} `json:"data,omitempty"`
+	Error   string      `json:"error,omitempty"`
+	Time    int64       `json:"time"` // Unix time in milliseconds
+}
+
+// Authenticator resolves a JWT to the ID of its user.
+type Authenticator func(token string) (int, error)
+
+// channelKey identifies a subscription of a client.
+type channelKey struct {
+	channel string
+	symbol  string
+}
+
+func (k channelKey) String() string {
+	if k.symbol == "" {
+		return k.channel
+	}
+	return k.channel + " " + k.symbol
+}
+
+// streamClient is a WebSocket connection with its subscriptions.
+type streamClient struct {
+	conn   *websocket.Conn
+	send   chan Frame
+	userID int // 0 until the client authenticates
+	// subs maps each subscription to the function closing it; pushed channels have none
+	subs map[channelKey]func()
+	mu   sync.Mutex
+}
+
+// push queues a frame for the client, dropping it if the client is not keeping up.
+func (c *streamClient) push(f Frame) {
+	if f.Time == 0 {
+		f.Time = time.Now().UnixMilli()
+	}
+	select {
+	case c.send <- f:
+	default:
+	}
+}
+
+func (c *streamClient) fail(id, format string, args ...interface{}) {
+	c.push(Frame{Type: FrameError, ID: id, Error: fmt.Sprintf(format, args...)})
+}
+
+// wants reports whether the client receives a pushed frame of channel on symbol for userID.
+func (c *streamClient) wants(channel, symbol string, userID int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if isPrivateChannel(channel) && (c.userID == 0 || c.userID != userID) {
+		return false
+	}
+	_, exact := c.subs[channelKey{channel, symbol}]
+	_, all := c.subs[channelKey{channel, ""}]
+	return exact || all
+}
+
+// eventChannels maps the types of the event bus to the channel they are pushed on.
+var eventChannels = map[string]string{
+	events.SignalCreated: ChannelSignals,
+	events.OrderPlaced:   ChannelOrders,
+	events.OrderFilled:   ChannelOrders,
+	events.OrderUpdated:  ChannelOrders,
+	events.TradeOpened:   ChannelPositions,
+	events.TradeUpdated:  ChannelPositions,
+	events.TradeClosed:   ChannelPositions,
+	events.WorkerStarted: ChannelWorkers,
+	events.WorkerStopped: ChannelWorkers,
+}
+
+// PriceStreamer serves the /api/ws/price WebSocket protocol. Each connection holds its own set of
+// subscriptions, and only receives the channels and symbols it subscribed to. Market channels are fed
+// by the market data hub, and the other channels by the event bus.
 type PriceStreamer struct {
-	exchanges map[string]exchange.Exchange
-	clients   map[*websocket.Conn]bool
-	mu        sync.RWMutex
+	hub     *marketdata.Hub
+	clients map[*streamClient]bool
+	mu      sync.RWMutex
 }
 
-// NewPriceStreamer creates a new price streamer
-func NewPriceStreamer(exchanges map[string]exchange.Exchange) *PriceStreamer {
-	return &PriceStreamer{
-		exchanges: exchanges,
-		clients:   make(map[*websocket.Conn]bool),
+// NewPriceStreamer creates a new price streamer fed by the market data hub and the event bus
+func NewPriceStreamer(hub *marketdata.Hub, bus *events.Bus) *PriceStreamer {
+	ps := &PriceStreamer{
+		hub:     hub,
+		clients: make(map[*streamClient]bool),
 	}
+	sub := bus.Subscribe()
+	go func() {
+		for e := range sub.C {
+			if channel, ok := eventChannels[e.Type]; ok {
+				ps.publish(channel, e)
+			}
+		}
+	}()
+	return ps
 }
 
-// AddClient adds a new WebSocket client
-func (ps *PriceStreamer) AddClient(conn *websocket.Conn) {
+// addClient adds a new WebSocket client
+func (ps *PriceStreamer) addClient(c *streamClient) {
 	ps.mu.Lock()
 	defer ps.mu.Unlock()
-	ps.clients[conn] = true
+	ps.clients[c] = true
 	log.Printf("New WebSocket client connected. Total clients: %d", len(ps.clients))
 }
 
-// RemoveClient removes a WebSocket client
-func (ps *PriceStreamer) RemoveClient(conn *websocket.Conn) {
+// removeClient removes a WebSocket client and closes its subscriptions
+func (ps *PriceStreamer) removeClient(c *streamClient) {
 	ps.mu.Lock()
-	defer ps.mu.Unlock()
-	delete(ps.clients, conn)
-	conn.Close()
-	log.Printf("WebSocket client disconnected. Total clients: %d", len(ps.clients))
+	delete(ps.clients, c)
+	total := len(ps.clients)
+	ps.mu.Unlock()
+
+	c.mu.Lock()
+	for key, closeSub := range c.subs {
+		if closeSub != nil {
+			closeSub()
+		}
+		delete(c.subs, key)
+	}
+	c.mu.Unlock()
+	log.Printf("WebSocket client disconnected. Total clients: %d", total)
 }
 
-// StartStreaming starts the price streaming for a symbol
-func (ps *PriceStreamer) StartStreaming(ctx context.Context, symbol string, exchangeName string) {
-	ex, ok := ps.exchanges[exchangeName]
-	if !ok {
-		log.Printf("Exchange %s not found", exchangeName)
-		return
+// publish sends an event on channel to every client subscribed to it for the event's symbol or for all symbols.
+// Events on private channels only go to the user they belong to.
+func (ps *PriceStreamer) publish(channel string, e events.Event) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	for c := range ps.clients {
+		if c.wants(channel, e.Symbol, e.UserID) {
+			c.push(Frame{Type: FrameData, Channel: channel, Symbol: e.Symbol, Event: e.Type, Data: e.Data, Time: e.Time.UnixMilli()})
+		}
 	}
+}
 
-	ticker := time.NewTicker(1 * time.Second) // Stream every second
-	defer ticker.Stop()
+// Serve runs the protocol on a connection until the client disconnects.
+// A token authenticates the client straight away, and a symbol subscribes it to that symbol's ticker,
+// as if the client had sent the auth and subscribe requests itself.
+func (ps *PriceStreamer) Serve(conn *websocket.Conn, authenticate Authenticator, token, symbol string) {
+	c := &streamClient{
+		conn: conn,
+		send: make(chan Frame, clientBuffer),
+		subs: make(map[channelKey]func()),
+	}
+	ps.addClient(c)
+	defer ps.removeClient(c)
+
+	done := make(chan struct{})
+	var writer sync.WaitGroup
+	writer.Add(1)
+	go func() {
+		defer writer.Done()
+		ps.write(c, done)
+	}()
+	defer func() {
+		close(done)
+		writer.Wait()
+		conn.Close()
+	}()
 
+	if token != "" {
+		ps.handle(c, authenticate, ClientMessage{Op: OpAuth, Token: token})
+	}
+	if symbol != "" {
+		ps.handle(c, authenticate, ClientMessage{Op: OpSubscribe, Channel: ChannelTicker, Symbol: symbol})
+	}
+
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		conn.SetReadDeadline(time.Now().Add(pongWait))
+		var msg ClientMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			c.fail("", "invalid message: %v", err)
+			continue
+		}
+		ps.handle(c, authenticate, msg)
+	}
+}
+
+// write sends the client's queued frames, and a heartbeat (a ping and a heartbeat frame) every heartbeatInterval.
+func (ps *PriceStreamer) write(c *streamClient, done <-chan struct{}) {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
 	for {
 		select {
-		case <-ticker.C:
-			price, err := ex.GetPrice(ctx, symbol)
-			if err != nil {
-				log.Printf("Error getting price for %s: %v", symbol, err)
-				continue
+		case f := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteJSON(f); err != nil {
+				log.Printf("Error sending message to client: %v", err)
+				// Closing the connection ends the read loop of Serve
+				c.conn.Close()
+				return
 			}
+		case now := <-ticker.C:
+			c.conn.SetWriteDeadline(now.Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.conn.Close()
+				return
+			}
+			c.push(Frame{Type: FrameHeartbeat, Time: now.UnixMilli()})
+		case <-done:
+			return
+		}
+	}
+}
 
-			ps.broadcastPrice(symbol, price)
-		case <-ctx.Done():
+// handle answers a client request with an ack or an error frame.
+func (ps *PriceStreamer) handle(c *streamClient, authenticate Authenticator, msg ClientMessage) {
+	switch msg.Op {
+	case OpPing:
+		c.push(Frame{Type: FramePong, ID: msg.ID})
+	case OpAuth:
+		userID, err := authenticate(msg.Token)
+		if err != nil {
+			c.fail(msg.ID, "authentication failed: %v", err)
+			return
+		}
+		c.mu.Lock()
+		current := c.userID
+		if current == 0 {
+			c.userID = userID
+		}
+		c.mu.Unlock()
+		if current != 0 && current != userID {
+			c.fail(msg.ID, "connection is already authenticated as another user")
+			return
+		}
+		c.push(Frame{Type: FrameAck, ID: msg.ID, Op: msg.Op})
+	case OpSubscribe:
+		key, err := parseChannel(msg.Channel, msg.Symbol)
+		if err != nil {
+			c.fail(msg.ID, "%v", err)
+			return
+		}
+		if err := ps.subscribe(c, key); err != nil {
+			c.fail(msg.ID, "%v", err)
+			return
+		}
+		c.push(Frame{Type: FrameAck, ID: msg.ID, Op: msg.Op, Channel: msg.Channel, Symbol: key.symbol})
+	case OpUnsubscribe:
+		key, err := parseChannel(msg.Channel, msg.Symbol)
+		if err != nil {
+			c.fail(msg.ID, "%v", err)
 			return
 		}
+		c.mu.Lock()
+		closeSub, ok := c.subs[key]
+		delete(c.subs, key)
+		c.mu.Unlock()
+		if !ok {
+			c.fail(msg.ID, "not subscribed to %s", key)
+			return
+		}
+		if closeSub != nil {
+			closeSub()
+		}
+		c.push(Frame{Type: FrameAck, ID: msg.ID, Op: msg.Op, Channel: msg.Channel, Symbol: key.symbol})
+	default:
+		c.fail(msg.ID, "unknown op %q", msg.Op)
 	}
 }
 
-// broadcastPrice sends price update to all connected clients
-func (ps *PriceStreamer) broadcastPrice(symbol string, price float64) {
-	ps.mu.RLock()
-	defer ps.mu.RUnlock()
+// subscribe adds a subscription to the client, subscribing to the hub for market channels.
+func (ps *PriceStreamer) subscribe(c *streamClient, key channelKey) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.subs[key]; ok {
+		return fmt.Errorf("already subscribed to %s", key)
+	}
+	if len(c.subs) >= maxSubscriptions {
+		return fmt.Errorf("at most %d subscriptions are allowed per connection", maxSubscriptions)
+	}
+	if isPrivateChannel(key.channel) && c.userID == 0 {
+		return fmt.Errorf("the %s channel requires authentication", key.channel)
+	}
 
-	message := map[string]interface{}{
-		"symbol":    symbol,
-		"price":     price,
-		"timestamp": time.Now().Unix(),
+	stream, market := marketStream(key)
+	if !market {
+		c.subs[key] = nil
+		return nil
+	}
+	sub, err := ps.hub.Subscribe(stream)
+	if err != nil {
+		return err
 	}
+	channel := key.channel
+	go func() {
+		for e := range sub.C {
+			c.push(Frame{Type: FrameData, Channel: channel, Symbol: key.symbol, Data: marketData(e), Time: e.Time.UnixMilli()})
+		}
+	}()
+	c.subs[key] = sub.Close
+	return nil
+}
 
-	for conn := range ps.clients {
-		if err := conn.WriteJSON(message); err != nil {
-			log.Printf("Error sending message to client: %v", err)
-			// Remove broken connection
-			go func(c *websocket.Conn) {
-				ps.RemoveClient(c)
-			}(conn)
+// parseChannel validates a channel and symbol of a request. Market channels need a symbol;
+// on the other channels an empty symbol subscribes to every symbol.
+func parseChannel(channel, symbol string) (channelKey, error) {
+	key := channelKey{channel: channel, symbol: strings.ToUpper(symbol)}
+	name, interval, _ := strings.Cut(channel, ":")
+	switch name {
+	case ChannelTicker, ChannelTrade, ChannelKline:
+		if key.symbol == "" {
+			return key, fmt.Errorf("the %s channel requires a symbol", name)
 		}
+		if (name == ChannelKline) != (interval != "") {
+			return key, fmt.Errorf("invalid channel %q: use kline:<interval>, e.g. kline:5m", channel)
+		}
+		return key, nil
+	case ChannelSignals, ChannelWorkers, ChannelOrders, ChannelPositions:
+		if interval != "" {
+			return key, fmt.Errorf("invalid channel %q", channel)
+		}
+		return key, nil
+	case "":
+		return key, fmt.Errorf("channel is required")
+	}
+	return key, fmt.Errorf("unknown channel %q", channel)
+}
+
+// marketStream returns the hub stream of a market channel.
+func marketStream(key channelKey) (marketdata.Stream, bool) {
+	name, interval, _ := strings.Cut(key.channel, ":")
+	switch name {
+	case ChannelTicker:
+		return marketdata.Stream{Symbol: key.symbol, Kind: marketdata.KindTicker}, true
+	case ChannelTrade:
+		return marketdata.Stream{Symbol: key.symbol, Kind: marketdata.KindTrade}, true
+	case ChannelKline:
+		return marketdata.Stream{Symbol: key.symbol, Kind: marketdata.KindKline, Interval: interval}, true
+	}
+	return marketdata.Stream{}, false
+}
+
+func isPrivateChannel(channel string) bool {
+	return channel == ChannelOrders || channel == ChannelPositions
+}
+
+// marketData returns the payload of a market data frame.
+func marketData(e marketdata.Event) map[string]interface{} {
+	data := map[string]interface{}{"price": e.Price}
+	if e.Ticker != nil {
+		data["open"] = e.Ticker.Open
+		data["high"] = e.Ticker.High
+		data["low"] = e.Ticker.Low
+		data["volume"] = e.Ticker.Volume
+		data["change_percent"] = e.Ticker.ChangePercent
+	}
+	if e.Candle != nil {
+		data["candle"] = e.Candle
+		data["closed"] = e.Closed
 	}
+	return data
 }
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/indicators"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+)
+
+// Indicator is an indicator that votes in the scoring of predictions. Its parameters, thresholds included,
+// are looked up by name: the defaults it is registered with, overridden by the scoring profile.
+type Indicator struct {
+	Description string             `json:"description"`
+	Defaults    map[string]float64 `json:"defaults"`
+	// Lookback returns the number of candles the indicator needs with params
+	Lookback func(params map[string]float64) int `json:"-"`
+	// Validate checks params, after the defaults have been applied; nil accepts any
+	Validate func(params map[string]float64) error `json:"-"`
+	// Vote returns the indicator's vote on candles, from -1 for sell to 1 for buy, the values it was taken on, and why
+	Vote func(candles []model.Candle, params map[string]float64) (vote float64, values map[string]float64, reason string) `json:"-"`
+}
+
+// defaultIndicators are the indicators every prediction service starts with
+func defaultIndicators() map[string]Indicator {
+	return map[string]Indicator{
+		"rsi": {
+			Description: "Relative strength index, with Wilder's smoothing: buys when oversold and sells when overbought",
+			Defaults:    map[string]float64{"period": 14, "oversold": 30, "overbought": 70},
+			Lookback:    func(p map[string]float64) int { return int(p["period"]) + 1 },
+			Validate: func(p map[string]float64) error {
+				if err := checkPeriods(p, "period"); err != nil {
+					return err
+				}
+				if p["oversold"] >= p["overbought"] {
+					return fmt.Errorf("oversold must be below overbought")
+				}
+				return nil
+			},
+			Vote: func(candles []model.Candle, p map[string]float64) (float64, map[string]float64, string) {
+				rsi := last(indicators.RSI(model.ClosePrices(candles), int(p["period"])))
+				values := map[string]float64{"rsi": rsi}
+				switch {
+				case rsi < p["oversold"]:
+					return 1, values, fmt.Sprintf("RSI %.2f is below %g (oversold)", rsi, p["oversold"])
+				case rsi > p["overbought"]:
+					return -1, values, fmt.Sprintf("RSI %.2f is above %g (overbought)", rsi, p["overbought"])
+				}
+				return 0, values, fmt.Sprintf("RSI %.2f is between %g and %g", rsi, p["oversold"], p["overbought"])
+			},
+		},
+		"macd": {
+			Description: "MACD: buys on a bullish crossover of the signal line and sells on a bearish one",
+			Defaults:    map[string]float64{"fast_period": 12, "slow_period": 26, "signal_period": 9},
+			Lookback:    func(p map[string]float64) int { return int(p["slow_period"] + p["signal_period"]) },
+			Validate: func(p map[string]float64) error {
+				if err := checkPeriods(p, "fast_period", "slow_period", "signal_period"); err != nil {
+					return err
+				}
+				if p["fast_period"] >= p["slow_period"] {
+					return fmt.Errorf("fast_period must be below slow_period")
+				}
+				return nil
+			},
+			Vote: func(candles []model.Candle, p map[string]float64) (float64, map[string]float64, string) {
+				series := indicators.MACD(model.ClosePrices(candles), int(p["fast_period"]), int(p["slow_period"]), int(p["signal_period"]))
+				if len(series) < 2 || math.IsNaN(series[len(series)-2].Signal) {
+					return 0, nil, "not enough data for a crossover"
+				}
+				macd, signal := series[len(series)-1].MACD, series[len(series)-1].Signal
+				prevMACD, prevSignal := series[len(series)-2].MACD, series[len(series)-2].Signal
+				values := map[string]float64{"macd": macd, "signal": signal}
+				switch {
+				case macd > signal && prevMACD <= prevSignal:
+					return 1, values, "MACD crossed above its signal line (bullish crossover)"
+				case macd < signal && prevMACD >= prevSignal:
+					return -1, values, "MACD crossed below its signal line (bearish crossover)"
+				}
+				return 0, values, "no MACD crossover"
+			},
+		},
+		"bollinger": {
+			Description: "Bollinger bands: buys below the lower band and sells above the upper band",
+			Defaults:    map[string]float64{"period": 20, "std_dev": 2},
+			Lookback:    func(p map[string]float64) int { return int(p["period"]) },
+			Validate: func(p map[string]float64) error {
+				if err := checkPeriods(p, "period"); err != nil {
+					return err
+				}
+				if p["std_dev"] <= 0 {
+					return fmt.Errorf("std_dev must be positive")
+				}
+				return nil
+			},
+			Vote: func(candles []model.Candle, p map[string]float64) (float64, map[string]float64, string) {
+				prices := model.ClosePrices(candles)
+				bands := indicators.Bollinger(prices, int(p["period"]), p["std_dev"])
+				band, price := bands[len(bands)-1], prices[len(prices)-1]
+				values := map[string]float64{"middle": band.Middle, "upper": band.Upper, "lower": band.Lower}
+				switch {
+				case price < values["lower"]:
+					return 1, values, fmt.Sprintf("price %.4f is below the lower band %.4f", price, values["lower"])
+				case price > values["upper"]:
+					return -1, values, fmt.Sprintf("price %.4f is above the upper band %.4f", price, values["upper"])
+				}
+				return 0, values, "price is inside the bands"
+			},
+		},
+		"ema_trend": {
+			Description: "EMA trend: buys while the fast EMA is above the slow EMA and sells while it is below",
+			Defaults:    map[string]float64{"fast_period": 9, "slow_period": 21},
+			Lookback:    func(p map[string]float64) int { return int(p["slow_period"]) },
+			Validate: func(p map[string]float64) error {
+				if err := checkPeriods(p, "fast_period", "slow_period"); err != nil {
+					return err
+				}
+				if p["fast_period"] >= p["slow_period"] {
+					return fmt.Errorf("fast_period must be below slow_period")
+				}
+				return nil
+			},
+			Vote: func(candles []model.Candle, p map[string]float64) (float64, map[string]float64, string) {
+				prices := model.ClosePrices(candles)
+				values := map[string]float64{
+					"fast": last(indicators.EMA(prices, int(p["fast_period"]))),
+					"slow": last(indicators.EMA(prices, int(p["slow_period"]))),
+				}
+				switch {
+				case values["fast"] > values["slow"]:
+					return 1, values, "fast EMA is above the slow EMA (uptrend)"
+				case values["fast"] < values["slow"]:
+					return -1, values, "fast EMA is below the slow EMA (downtrend)"
+				}
+				return 0, values, "fast and slow EMAs are equal"
+			},
+		},
+	}
+}
+
+// last returns the latest value of an indicator series. Callers make sure it is past its warm-up.
+func last(series []float64) float64 {
+	return series[len(series)-1]
+}
+
+// checkPeriods checks that the named parameters are whole numbers of at least 1.
+func checkPeriods(p map[string]float64, names ...string) error {
+	for _, name := range names {
+		if p[name] < 1 || p[name] != math.Trunc(p[name]) {
+			return fmt.Errorf("%s must be a whole number of at least 1", name)
+		}
+	}
+	return nil
+}
+
+// RegisterIndicator adds an indicator that scoring profiles can use, replacing any indicator of the same name.
+func (s *PredictionService) RegisterIndicator(name string, indicator Indicator) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.indicators[name] = indicator
+}
+
+// Indicators returns the registered indicators by name.
+func (s *PredictionService) Indicators() map[string]Indicator {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	indicators := make(map[string]Indicator, len(s.indicators))
+	for name, indicator := range s.indicators {
+		indicators[name] = indicator
+	}
+	return indicators
+}
+
+// DefaultProfile returns the built-in scoring profile: RSI and MACD worth a point each, and the Bollinger bands two.
+func (s *PredictionService) DefaultProfile() *model.ScoringProfile {
+	return &model.ScoringProfile{
+		Name: model.DefaultScoringProfile,
+		Indicators: []model.IndicatorWeight{
+			{Name: "rsi", Weight: 1},
+			{Name: "macd", Weight: 1},
+			{Name: "bollinger", Weight: 2},
+		},
+	}
+}
+
+// ValidateProfile checks that a scoring profile only uses registered indicators, once each, with known parameters and positive weights.
+func (s *PredictionService) ValidateProfile(profile *model.ScoringProfile) error {
+	if len(profile.Indicators) == 0 {
+		return fmt.Errorf("a scoring profile needs at least one indicator")
+	}
+	seen := make(map[string]bool)
+	for _, w := range profile.Indicators {
+		indicator, ok := s.indicator(w.Name)
+		if !ok {
+			return fmt.Errorf("unknown indicator %q", w.Name)
+		}
+		if seen[w.Name] {
+			return fmt.Errorf("indicator %q is listed twice", w.Name)
+		}
+		seen[w.Name] = true
+		if w.Weight <= 0 {
+			return fmt.Errorf("%s: weight must be positive", w.Name)
+		}
+		for key := range w.Params {
+			if _, ok := indicator.Defaults[key]; !ok {
+				return fmt.Errorf("%s: unknown parameter %q", w.Name, key)
+			}
+		}
+		if indicator.Validate != nil {
+			if err := indicator.Validate(indicatorParams(indicator, w)); err != nil {
+				return fmt.Errorf("%s: %w", w.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *PredictionService) indicator(name string) (Indicator, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	indicator, ok := s.indicators[name]
+	return indicator, ok
+}
+
+// indicatorParams returns the indicator's default parameters overridden by those of the profile.
+func indicatorParams(indicator Indicator, w model.IndicatorWeight) map[string]float64 {
+	params := make(map[string]float64, len(indicator.Defaults))
+	for key, value := range indicator.Defaults {
+		params[key] = value
+	}
+	for key, value := range w.Params {
+		params[key] = value
+	}
+	return params
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"log"
+	"math"
+	"time"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/config"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/repository"
+)
+
+const (
+	// Signals are followed on 1m candles, whatever timeframe they were generated on
+	signalEvaluationInterval = "1m"
+	signalEvaluationPeriod   = time.Minute
+)
+
+// SignalEvaluator follows stored signals on the candles after them and records their outcome: whether the price
+// reached their entry, then whether the take profit or the stop loss was hit first, how long it took, and how far
+// the price went for and against them.
+type SignalEvaluator struct {
+	horizon    time.Duration
+	fetcher    *FetcherService
+	signalRepo *repository.SignalRepository
+}
+
+// NewSignalEvaluator creates a signal evaluator; signals expire SIGNAL_EVALUATION_HORIZON after they are generated.
+func NewSignalEvaluator(cfg *config.Config, fetcher *FetcherService, signalRepo *repository.SignalRepository) *SignalEvaluator {
+	return &SignalEvaluator{horizon: cfg.SignalEvaluationHorizon, fetcher: fetcher, signalRepo: signalRepo}
+}
+
+// Start evaluates the pending signals every minute until ctx is cancelled.
+func (e *SignalEvaluator) Start(ctx context.Context) {
+	ticker := time.NewTicker(signalEvaluationPeriod)
+	defer ticker.Stop()
+	for {
+		e.evaluateAll(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (e *SignalEvaluator) evaluateAll(ctx context.Context) {
+	signals, err := e.signalRepo.GetPendingSignals()
+	if err != nil {
+		log.Printf("Error loading pending signals: %v", err)
+		return
+	}
+	for _, signal := range signals {
+		if ctx.Err() != nil {
+			return
+		}
+		now := time.Now()
+		var candles []model.Candle
+		if evaluable(signal) {
+			from, until := evaluationStart(signal), signal.CreatedAt.Add(e.horizon)
+			if until.After(now) {
+				until = now
+			}
+			if from.Before(until) {
+				if candles, err = e.fetcher.FetchCandleRange(ctx, signal.Symbol, signalEvaluationInterval, from, until); err != nil {
+					log.Printf("[%s] Error loading candles to evaluate signal %d: %v", signal.Symbol, signal.ID, err)
+					continue
+				}
+			}
+		}
+		if !evaluateSignal(signal, candles, e.horizon, now) {
+			continue
+		}
+		if err := e.signalRepo.UpdateSignalOutcome(signal); err != nil {
+			log.Printf("[%s] Error storing the evaluation of signal %d: %v", signal.Symbol, signal.ID, err)
+			continue
+		}
+		if signal.Outcome != model.SignalPending {
+			log.Printf("[%s] Signal %d (%s %s) ended with %s after %ds: %.2fR", signal.Symbol, signal.ID, signal.Strategy, signal.Type,
+				signal.Outcome, signal.TimeToOutcome, signal.RMultiple)
+		}
+	}
+}
+
+// evaluationStart is the time from which a signal's candles have not been evaluated yet.
+func evaluationStart(signal *model.Signal) time.Time {
+	if signal.EvaluatedUntil != nil {
+		return *signal.EvaluatedUntil
+	}
+	return signal.CreatedAt
+}
+
+// evaluable reports whether a signal has a take profit and a stop loss on either side of its price.
+func evaluable(signal *model.Signal) bool {
+	if signal.Price <= 0 || signal.TakeProfit <= 0 || signal.StopLoss <= 0 {
+		return false
+	}
+	if signal.Type == "SELL" {
+		return signal.TakeProfit < signal.Price && signal.StopLoss > signal.Price
+	}
+	return signal.TakeProfit > signal.Price && signal.StopLoss < signal.Price
+}
+
+// evaluateSignal advances a pending signal over the closed candles after it, sorted oldest to newest, and reports
+// whether it changed. Candles opening before the signal are skipped, so the candle it was generated in does not count.
+// The signal is entered once the price trades at its price, as a limit order there would fill: at or below it for a
+// buy, at or above it for a sell. Its levels are only followed from then on; in the candle of the entry only the stop
+// loss counts, since the order of prices within a candle is unknown. When one candle reaches both levels, the stop
+// loss is taken to have been hit first. A signal never entered within the horizon ends unfilled.
+func evaluateSignal(signal *model.Signal, candles []model.Candle, horizon time.Duration, now time.Time) bool {
+	if !evaluable(signal) {
+		signal.Outcome = model.SignalUnscorable
+		signal.OutcomeAt = &now
+		return true
+	}
+
+	long := signal.Type != "SELL"
+	entry := signal.Price
+	risk, reward := math.Abs(entry-signal.StopLoss), math.Abs(signal.TakeProfit-entry)
+	deadline := signal.CreatedAt.Add(horizon)
+	from := evaluationStart(signal)
+	changed := false
+	for _, c := range candles {
+		if c.OpenTime.Before(from) || !c.OpenTime.Before(deadline) || !c.CloseTime.Before(now) {
+			continue
+		}
+		changed = true
+		closeTime := c.CloseTime
+		signal.EvaluatedUntil = &closeTime
+		signal.OutcomePrice = c.Close
+
+		entered := signal.EntryAt != nil
+		if !entered {
+			if (long && c.Low > entry) || (!long && c.High < entry) {
+				continue
+			}
+			signal.EntryAt = &closeTime
+		}
+
+		favorable, adverse := c.High-entry, entry-c.Low
+		hitStop, hitTarget := c.Low <= signal.StopLoss, c.High >= signal.TakeProfit
+		if !long {
+			favorable, adverse = entry-c.Low, c.High-entry
+			hitStop, hitTarget = c.High >= signal.StopLoss, c.Low <= signal.TakeProfit
+		}
+		// Excursions stop at the levels; beyond them the signal is over
+		if entered {
+			signal.MaxFavorableExcursion = math.Max(signal.MaxFavorableExcursion, 100*math.Min(favorable, reward)/entry)
+		}
+		signal.MaxAdverseExcursion = math.Max(signal.MaxAdverseExcursion, 100*math.Min(adverse, risk)/entry)
+
+		switch {
+		case hitStop:
+			resolveSignal(signal, model.SignalStopLoss, signal.StopLoss, closeTime)
+			return true
+		case hitTarget && entered:
+			resolveSignal(signal, model.SignalTakeProfit, signal.TakeProfit, closeTime)
+			return true
+		}
+	}
+
+	if !now.Before(deadline) {
+		if signal.EntryAt == nil {
+			signal.Outcome = model.SignalUnfilled
+			signal.OutcomeAt = &deadline
+			signal.TimeToOutcome = int64(deadline.Sub(signal.CreatedAt).Seconds())
+			return true
+		}
+		if signal.OutcomePrice == 0 {
+			signal.OutcomePrice = entry
+		}
+		resolveSignal(signal, model.SignalExpired, signal.OutcomePrice, deadline)
+		return true
+	}
+	return changed
+}
+
+// resolveSignal records the outcome of a signal exiting at price.
+func resolveSignal(signal *model.Signal, outcome string, price float64, at time.Time) {
+	move := price - signal.Price
+	if signal.Type == "SELL" {
+		move = -move
+	}
+	signal.Outcome = outcome
+	signal.OutcomePrice = price
+	signal.OutcomeAt = &at
+	signal.TimeToOutcome = int64(at.Sub(signal.CreatedAt).Seconds())
+	signal.ReturnPercent = 100 * move / signal.Price
+	signal.RMultiple = move / math.Abs(signal.Price-signal.StopLoss)
+}
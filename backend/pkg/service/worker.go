@@ -7,27 +7,30 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ratheeshkumar25/forex_bot/backend/config"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/marketdata"
 	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
-	"github.com/ratheeshkumar25/forex_bot/backend/pkg/repository"
 )
 
 // WorkerService orchestrates the continuous analysis of market data.
 type WorkerService struct {
 	fetcherSvc *FetcherService
 	predSvc    *PredictionService
-	tradeRepo  *repository.TradeRepository
-	signalRepo *repository.SignalRepository
-	trades     []*model.Trade
-	mu         sync.Mutex
+	execSvc    *ExecutionService
+	hub        *marketdata.Hub
+	consensus  *ConsensusService
+	calibrator *CalibrationService
 }
 
 // NewWorkerService creates a new automated worker.
-func NewWorkerService(fetcher *FetcherService, predictor *PredictionService, tradeRepo *repository.TradeRepository, signalRepo *repository.SignalRepository) *WorkerService {
+func NewWorkerService(fetcher *FetcherService, predictor *PredictionService, executor *ExecutionService, hub *marketdata.Hub, consensus *ConsensusService, calibrator *CalibrationService) *WorkerService {
 	return &WorkerService{
 		fetcherSvc: fetcher,
 		predSvc:    predictor,
-		tradeRepo:  tradeRepo,
-		signalRepo: signalRepo,
+		execSvc:    executor,
+		hub:        hub,
+		consensus:  consensus,
+		calibrator: calibrator,
 	}
 }
 
@@ -35,10 +38,11 @@ func NewWorkerService(fetcher *FetcherService, predictor *PredictionService, tra
 type AnalysisResult struct {
 	Timeframe  string
 	Prediction model.Prediction
+	Candles    []model.Candle // The candles the prediction was made on
 	Error      error
 }
 
-var timeframes = []string{"1m", "5m", "1d"}
+var timeframes = config.WorkerTimeframes
 
 var timeframeIntervals = map[string]time.Duration{
 	"1m": 10 * time.Second,
@@ -46,6 +50,8 @@ var timeframeIntervals = map[string]time.Duration{
 	"1d": 1 * time.Hour, // or 24 * time.Hour for true daily
 }
 
+// Start analyses every timeframe of symbol until ctx is cancelled. A timeframe is analysed as soon as
+// the hub reports one of its candles closing, and otherwise at its regular interval.
 func (s *WorkerService) Start(ctx context.Context, symbol string) {
 	log.Printf("Starting automated analysis worker for %s...", symbol)
 	log.Println("--- Bot is now running. Press Ctrl+C to stop. ---")
@@ -57,10 +63,30 @@ func (s *WorkerService) Start(ctx context.Context, symbol string) {
 			defer wg.Done()
 			ticker := time.NewTicker(timeframeIntervals[tf])
 			defer ticker.Stop()
+
+			var closes <-chan marketdata.Event
+			if klines, err := s.hub.Subscribe(marketdata.Stream{Symbol: symbol, Kind: marketdata.KindKline, Interval: tf}); err != nil {
+				log.Printf("Worker %s [%s]: error subscribing to candles, analysing on the interval only: %v", symbol, tf, err)
+			} else {
+				defer klines.Close()
+				closes = klines.C
+			}
+			// A polling fallback that takes over the stream publishes the last closed candle again
+			var lastCandle time.Time
 			for {
 				select {
 				case <-ticker.C:
-					s.runAnalysisForTimeframe(symbol, tf)
+					s.runAnalysisForTimeframe(ctx, symbol, tf)
+				case e, ok := <-closes:
+					if !ok {
+						closes = nil
+						continue
+					}
+					if e.Closed && e.Candle.OpenTime.After(lastCandle) {
+						lastCandle = e.Candle.OpenTime
+						s.runAnalysisForTimeframe(ctx, symbol, tf)
+						ticker.Reset(timeframeIntervals[tf])
+					}
 				case <-ctx.Done():
 					return
 				}
@@ -70,8 +96,8 @@ func (s *WorkerService) Start(ctx context.Context, symbol string) {
 	wg.Wait()
 }
 
-// Add this helper function:
-func (s *WorkerService) runAnalysisForTimeframe(symbol, tf string) {
+// runAnalysisForTimeframe analyses one timeframe of symbol, records it in the consensus and executes it if permitted.
+func (s *WorkerService) runAnalysisForTimeframe(ctx context.Context, symbol, tf string) {
 	log.Printf("Running analysis for %s [%s]...", symbol, tf)
 	var wg sync.WaitGroup
 	results := make(chan AnalysisResult, 1)
@@ -91,7 +117,18 @@ func (s *WorkerService) runAnalysisForTimeframe(symbol, tf string) {
 			p.Price,
 			p.Confidence*100,
 		)
-		s.CheckTrades(p.Price)
+		s.consensus.Record(symbol, tf, p, result.Candles)
+		if c, ok := s.consensus.Consensus(symbol); ok {
+			log.Printf("  | all  -> Consensus: %-4s | Confidence: %.2f%% | %s", strings.ToUpper(c.Signal), c.Confidence*100, c.Reason)
+		}
+		if ok, reason := s.consensus.Permits(p); !ok {
+			if p.Signal != "hold" {
+				log.Printf("  | %-4s -> Not executing: %s", tf, reason)
+				s.execSvc.Record(tf, p)
+			}
+			continue
+		}
+		s.execute(ctx, tf, p)
 	}
 }
 
@@ -99,40 +136,20 @@ func (s *WorkerService) runAnalysisForTimeframe(symbol, tf string) {
 func (s *WorkerService) analyzeTimeframe(symbol, timeframe string, wg *sync.WaitGroup, results chan<- AnalysisResult) {
 	defer wg.Done()
 
-	klines, err := s.fetcherSvc.FetchKlines(symbol, timeframe)
+	candles, err := s.fetcherSvc.FetchCandles(symbol, timeframe, 100)
 	if err != nil {
 		results <- AnalysisResult{Timeframe: timeframe, Error: err}
 		return
 	}
 
-	params := s.predSvc.DefaultPredictionParams()
-	prediction := s.predSvc.AdvancedPredictBuySell(symbol, klines, params)
-	results <- AnalysisResult{Timeframe: timeframe, Prediction: prediction}
+	prediction := s.predSvc.AdvancedPredictBuySell(symbol, candles, s.predSvc.DefaultProfile())
+	s.calibrator.Calibrate(&prediction, timeframe)
+	results <- AnalysisResult{Timeframe: timeframe, Prediction: prediction, Candles: candles}
 }
 
-func (s *WorkerService) AddTrade(trade *model.Trade) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.trades = append(s.trades, trade)
-}
-
-func (s *WorkerService) CheckTrades(latestPrice float64) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	now := time.Now()
-	for _, trade := range s.trades {
-		if !trade.IsOpen {
-			continue
-		}
-		if latestPrice >= trade.TakeProfit {
-			trade.IsOpen = false
-			log.Printf("Trade for %s closed at TP: %.4f", trade.Symbol, latestPrice)
-		} else if latestPrice <= trade.StopLoss {
-			trade.IsOpen = false
-			log.Printf("Trade for %s closed at SL: %.4f", trade.Symbol, latestPrice)
-		} else if now.Sub(trade.OpenTime) >= trade.MaxDuration {
-			trade.IsOpen = false
-			log.Printf("Trade for %s closed by duration at: %.4f", trade.Symbol, latestPrice)
-		}
+// execute passes a prediction to the execution pipeline. The trades it opens are managed by the position manager.
+func (s *WorkerService) execute(ctx context.Context, tf string, p model.Prediction) {
+	if _, err := s.execSvc.Execute(ctx, tf, p); err != nil {
+		log.Printf("  | %-4s -> Execution error: %v", tf, err)
 	}
 }
@@ -1,58 +1,219 @@
 package strategy
 
 import (
-	"context"
+	"encoding/json"
 	"fmt"
+	"log"
+	"math"
 	"time"
 
 	"github.com/ratheeshkumar25/forex_bot/backend/pkg/exchange"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
 )
 
-// DCA implements Dollar-Cost Averaging strategy
+// DCA implements Dollar-Cost Averaging strategy.
+// It buys Amount on a fixed cadence or cron schedule until MaxBudget is spent. Optionally it adds
+// safety orders when the price dips below the last purchase, and sells the whole position once
+// the price reaches a take-profit above the averaged entry, starting a new cycle.
+// All orders are market orders: each purchase is spent, and counted against MaxBudget, before the next is placed,
+// and the take-profit sell never rests on the book while the plan places it again.
 type DCA struct {
-	Interval time.Duration // Interval between purchases
-	Amount   float64       // Amount to invest each time
+	Amount            float64 `json:"amount"`              // Quote amount of each scheduled purchase
+	Cadence           string  `json:"cadence"`             // Time between purchases, e.g. "24h"
+	Cron              string  `json:"cron"`                // Optional five-field cron schedule in UTC, used instead of Cadence
+	MaxBudget         float64 `json:"max_budget"`          // Total quote amount the plan may invest, 0 for no limit
+	DipPercent        float64 `json:"dip_percent"`         // Place a safety order when price falls this far below the last purchase, 0 to disable
+	SafetyMultiplier  float64 `json:"safety_multiplier"`   // Each safety order buys this multiple of the previous purchase amount
+	MaxSafetyOrders   int     `json:"max_safety_orders"`   // Safety orders allowed per cycle
+	TakeProfitPercent float64 `json:"take_profit_percent"` // Sell the position this far above the average entry price, 0 to disable
+
+	interval time.Duration
+	schedule *cronSchedule
+	state    dcaState
+}
+
+// dcaState is the persisted progress of a DCA plan.
+type dcaState struct {
+	NextBuy        time.Time `json:"next_buy"`        // Time of the next scheduled purchase
+	Spent          float64   `json:"spent"`           // Quote spent over the life of the plan including fees
+	Quantity       float64   `json:"quantity"`        // Base held in the current cycle
+	Cost           float64   `json:"cost"`            // Quote paid for Quantity including fees
+	LastBuyPrice   float64   `json:"last_buy_price"`  // Reference price for the next safety order
+	LastBuyAmount  float64   `json:"last_buy_amount"` // Quote amount of the last purchase, scaled by SafetyMultiplier
+	SafetyOrders   int       `json:"safety_orders"`   // Safety orders placed in the current cycle
+	Cycles         int       `json:"cycles"`          // Completed take-profit cycles
+	RealizedProfit float64   `json:"realized_profit"` // Profit of completed cycles net of fees
+	SellPlacedAt   time.Time `json:"sell_placed_at"`  // When the pending take-profit sell was placed
+}
+
+// dcaRetryDelay is how long the plan waits for the fill of a take-profit sell before placing it again,
+// in case the order was rejected or its fill was lost.
+const dcaRetryDelay = time.Minute
+
+// Name returns the registry name of the strategy
+func (d *DCA) Name() string {
+	return "dca"
 }
 
-// Execute runs the DCA logic
-func (d *DCA) Execute(ctx context.Context, ex exchange.Exchange, symbol string) error {
-	// Simple DCA: buy at regular intervals
-	ticker := time.NewTicker(d.Interval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-ticker.C:
-			currentPrice, err := ex.GetPrice(ctx, symbol)
-			if err != nil {
-				return err
-			}
-
-			quantity := d.Amount / currentPrice
-			err = ex.PlaceOrder(ctx, symbol, "BUY", quantity, currentPrice)
-			if err != nil {
-				return fmt.Errorf("failed to place DCA buy order: %w", err)
-			}
+// Init configures the DCA plan from JSON parameters
+func (d *DCA) Init(params json.RawMessage) error {
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, d); err != nil {
+			return fmt.Errorf("invalid DCA parameters: %w", err)
+		}
+	}
+	if d.Amount <= 0 {
+		return fmt.Errorf("DCA amount must be positive")
+	}
+
+	if d.Cron != "" {
+		schedule, err := parseCron(d.Cron)
+		if err != nil {
+			return err
+		}
+		if schedule.next(time.Now()).IsZero() {
+			return fmt.Errorf("cron expression %q never matches", d.Cron)
+		}
+		d.schedule = schedule
+	} else {
+		interval, err := time.ParseDuration(d.Cadence)
+		if err != nil || interval <= 0 {
+			return fmt.Errorf("DCA cadence must be a positive duration such as 24h")
+		}
+		d.interval = interval
+	}
+
+	if d.DipPercent < 0 || d.DipPercent >= 100 || d.TakeProfitPercent < 0 || d.MaxBudget < 0 {
+		return fmt.Errorf("DCA dip, take profit and budget must not be negative")
+	}
+	if d.DipPercent > 0 {
+		if d.SafetyMultiplier <= 0 {
+			d.SafetyMultiplier = 1
+		}
+		if d.MaxSafetyOrders <= 0 {
+			d.MaxSafetyOrders = 3
 		}
 	}
+	return nil
 }
 
-// GetProfitPrediction predicts profit for DCA strategy
-func (d *DCA) GetProfitPrediction(symbol string, investment float64, timeframe string) (float64, float64, error) {
-	// Simple prediction: assume average return over time
-	// Placeholder; real prediction would use historical data
-	avgReturn := 0.05 // 5% average return
-	totalProfit := investment * avgReturn
-	percentage := avgReturn * 100
+// State returns the schedule and position of the plan for persistence
+func (d *DCA) State() (json.RawMessage, error) {
+	return json.Marshal(d.state)
+}
 
-	if timeframe == "short" {
-		totalProfit *= 0.3 // Lower for short term
-		percentage *= 0.3
+// Restore loads a persisted plan so that the schedule continues where it stopped
+func (d *DCA) Restore(state json.RawMessage) error {
+	if err := json.Unmarshal(state, &d.state); err != nil {
+		return fmt.Errorf("invalid DCA state: %w", err)
 	}
+	return nil
+}
+
+// OnCandle evaluates the plan at the candle close
+func (d *DCA) OnCandle(candle model.Candle) []OrderIntent {
+	return d.update(candle.CloseTime, candle.Close)
+}
 
-	return totalProfit, percentage, nil
+// OnTick evaluates the plan at the current price
+func (d *DCA) OnTick(tick Tick) []OrderIntent {
+	return d.update(tick.Time, tick.Price)
+}
+
+// OnFill adds purchases to the position, and closes the cycle when the take-profit sell fills.
+// A take-profit sell that ended partly filled only reduces the position, and the rest is sold again.
+func (d *DCA) OnFill(fill Fill) []OrderIntent {
+	cost := fill.Price * fill.Quantity
+	if fill.Side == "BUY" {
+		d.state.Quantity += fill.Quantity
+		d.state.Cost += cost + fill.Fee
+		d.state.Spent += cost + fill.Fee
+		d.state.LastBuyPrice = fill.Price
+		return nil
+	}
+
+	if fill.Tag == "dca-take-profit" && d.state.Quantity > 0 {
+		sold := math.Min(fill.Quantity/d.state.Quantity, 1)
+		profit := cost - fill.Fee - d.state.Cost*sold
+		d.state.RealizedProfit += profit
+		if sold < 1-dustFraction {
+			log.Printf("DCA take profit: sold %.8f of %.8f at %.8f, profit %.8f; selling the rest again",
+				fill.Quantity, d.state.Quantity, fill.Price, profit)
+			d.state.Quantity -= fill.Quantity
+			d.state.Cost -= d.state.Cost * sold
+			d.state.SellPlacedAt = time.Time{}
+			return nil
+		}
+		d.state.Cycles++
+		log.Printf("DCA take profit: sold %.8f at %.8f, profit %.8f (total %.8f over %d cycles)",
+			fill.Quantity, fill.Price, profit, d.state.RealizedProfit, d.state.Cycles)
+
+		d.state.Quantity, d.state.Cost = 0, 0
+		d.state.LastBuyPrice, d.state.LastBuyAmount = 0, 0
+		d.state.SafetyOrders = 0
+		d.state.SellPlacedAt = time.Time{}
+	}
+	return nil
+}
+
+// update takes profit, places a safety order on a dip, or makes the scheduled purchase, in that order of priority
+func (d *DCA) update(now time.Time, price float64) []OrderIntent {
+	if price <= 0 {
+		return nil
+	}
+	if d.state.NextBuy.IsZero() {
+		// The first purchase happens at the first scheduled time after the plan starts.
+		d.state.NextBuy = d.nextBuy(now)
+		return nil
+	}
+
+	if d.TakeProfitPercent > 0 && d.state.Quantity > 0 {
+		target := d.state.Cost / d.state.Quantity * (1 + d.TakeProfitPercent/100)
+		if price >= target && now.Sub(d.state.SellPlacedAt) >= dcaRetryDelay {
+			d.state.SellPlacedAt = now
+			return []OrderIntent{{Side: "SELL", Type: exchange.OrderTypeMarket, Quantity: d.state.Quantity, Price: price, Tag: "dca-take-profit"}}
+		}
+	}
+
+	if d.DipPercent > 0 && d.state.LastBuyPrice > 0 && d.state.SafetyOrders < d.MaxSafetyOrders &&
+		price <= d.state.LastBuyPrice*(1-d.DipPercent/100) {
+		amount := d.budget(d.state.LastBuyAmount * d.SafetyMultiplier)
+		if amount > 0 {
+			d.state.SafetyOrders++
+			// Move the reference down now so the next dip is measured from here even before the fill
+			d.state.LastBuyPrice = price
+			d.state.LastBuyAmount = amount
+			return []OrderIntent{{Side: "BUY", Type: exchange.OrderTypeMarket, Quantity: amount / price, Price: price, Tag: fmt.Sprintf("dca-safety-%d", d.state.SafetyOrders)}}
+		}
+	}
+
+	if now.Before(d.state.NextBuy) {
+		return nil
+	}
+	// Missed purchases (for example while the server was down) are not made up; one purchase is made now.
+	d.state.NextBuy = d.nextBuy(now)
+	amount := d.budget(d.Amount)
+	if amount <= 0 {
+		return nil
+	}
+	d.state.LastBuyAmount = amount
+	return []OrderIntent{{Side: "BUY", Type: exchange.OrderTypeMarket, Quantity: amount / price, Price: price, Tag: "dca-buy"}}
+}
+
+// nextBuy returns the next scheduled purchase time after now
+func (d *DCA) nextBuy(now time.Time) time.Time {
+	if d.schedule != nil {
+		return d.schedule.next(now)
+	}
+	return now.Add(d.interval)
+}
+
+// budget limits a purchase amount to what is left of MaxBudget
+func (d *DCA) budget(amount float64) float64 {
+	if d.MaxBudget == 0 {
+		return amount
+	}
+	return math.Max(0, math.Min(amount, d.MaxBudget-d.state.Spent))
 }
 
 // GetSignals returns buy signals for DCA strategy
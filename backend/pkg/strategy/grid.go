@@ -1,60 +1,272 @@
 package strategy
 
 import (
-	"context"
+	"encoding/json"
 	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/ratheeshkumar25/forex_bot/backend/pkg/exchange"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
 )
 
-// GridStrategy implements grid trading strategy
+// Grid spacing modes
+const (
+	SpacingArithmetic = "arithmetic" // Equal price distance between levels
+	SpacingGeometric  = "geometric"  // Equal percentage distance between levels
+)
+
+// GridStrategy implements a stateful grid trading bot.
+// The range between LowerPrice and UpperPrice is divided into GridLevels cells. Each cell
+// buys at its lower level and, once that buy fills, sells the same quantity at its upper level.
+// When the sell fills the round trip is complete and the buy is placed again.
 type GridStrategy struct {
-	GridLevels int     // Number of grid levels
-	GridSize   float64 // Percentage size of each grid
+	LowerPrice      float64 `json:"lower_price"`      // Lower bound of the grid
+	UpperPrice      float64 `json:"upper_price"`      // Upper bound of the grid
+	GridLevels      int     `json:"grid_levels"`      // Number of grid cells between the bounds
+	Spacing         string  `json:"spacing"`          // SpacingArithmetic or SpacingGeometric
+	TotalInvestment float64 `json:"total_investment"` // Quote amount split evenly across the cells
+	GridSize        float64 `json:"grid_size"`        // Percentage size of each grid, used to derive the bounds when they are not set
+
+	state gridState
+}
+
+// GridCell is one buy/sell pair of the grid.
+type GridCell struct {
+	BuyPrice  float64 `json:"buy_price"`
+	SellPrice float64 `json:"sell_price"`
+	Quantity  float64 `json:"quantity"`
+	Status    string  `json:"status"`     // "idle", "buying" or "selling"
+	Held      float64 `json:"held"`       // Base bought by the filled buy and not sold yet, less than Quantity after a partial fill
+	EntryCost float64 `json:"entry_cost"` // Quote spent on the filled buy including fees, for the Held quantity
+}
+
+// GridRoundTrip is a completed buy and sell of one cell.
+type GridRoundTrip struct {
+	Cell      int       `json:"cell"`
+	BuyPrice  float64   `json:"buy_price"`
+	SellPrice float64   `json:"sell_price"`
+	Quantity  float64   `json:"quantity"`
+	Profit    float64   `json:"profit"` // Net of fees
+	ClosedAt  time.Time `json:"closed_at"`
+}
+
+// gridState is the persisted state of a running grid.
+type gridState struct {
+	Cells          []GridCell      `json:"cells"`
+	RoundTrips     []GridRoundTrip `json:"round_trips"`
+	RealizedProfit float64         `json:"realized_profit"`
+	resume         bool            // Open orders must be asked for again after a restore
+}
+
+// Grid cell statuses
+const (
+	cellIdle    = "idle"
+	cellBuying  = "buying"
+	cellSelling = "selling"
+)
+
+// maxRoundTrips caps the number of round trips kept in the persisted state.
+const maxRoundTrips = 500
+
+// Name returns the registry name of the strategy
+func (g *GridStrategy) Name() string {
+	return "grid"
+}
+
+// Init configures the grid from JSON parameters
+func (g *GridStrategy) Init(params json.RawMessage) error {
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, g); err != nil {
+			return fmt.Errorf("invalid grid parameters: %w", err)
+		}
+	}
+	if g.Spacing == "" {
+		g.Spacing = SpacingArithmetic
+	}
+	switch {
+	case g.GridLevels <= 0:
+		return fmt.Errorf("grid levels must be positive")
+	case g.TotalInvestment <= 0:
+		return fmt.Errorf("total investment must be positive")
+	case g.Spacing != SpacingArithmetic && g.Spacing != SpacingGeometric:
+		return fmt.Errorf("grid spacing must be %s or %s", SpacingArithmetic, SpacingGeometric)
+	case g.LowerPrice == 0 && g.UpperPrice == 0 && g.GridSize <= 0:
+		return fmt.Errorf("either grid bounds or a grid size is required")
+	case g.LowerPrice == 0 && g.UpperPrice == 0 && float64(g.GridLevels)*g.GridSize >= 100:
+		// The derived lower bound would be at or below zero
+		return fmt.Errorf("grid levels times grid size must be below 100%%")
+	case (g.LowerPrice != 0 || g.UpperPrice != 0) && (g.LowerPrice <= 0 || g.UpperPrice <= g.LowerPrice):
+		return fmt.Errorf("grid upper price must be above a positive lower price")
+	}
+	return nil
+}
+
+// State returns the grid cells and realised round trips for persistence
+func (g *GridStrategy) State() (json.RawMessage, error) {
+	return json.Marshal(g.state)
 }
 
-// Execute runs the grid trading logic
-func (g *GridStrategy) Execute(ctx context.Context, ex exchange.Exchange, symbol string) error {
-	// Simple grid logic: buy at lower levels, sell at higher levels
-	currentPrice, err := ex.GetPrice(ctx, symbol)
-	if err != nil {
-		return err
+// Restore loads persisted grid state; orders that were open are asked for again on the next event,
+// and the runner matches them to the ones still resting on the exchange
+func (g *GridStrategy) Restore(state json.RawMessage) error {
+	if err := json.Unmarshal(state, &g.state); err != nil {
+		return fmt.Errorf("invalid grid state: %w", err)
 	}
+	g.state.resume = len(g.state.Cells) > 0
+	return nil
+}
 
-	for i := 1; i <= g.GridLevels; i++ {
-		buyPrice := currentPrice * (1 - float64(i)*g.GridSize/100)
-		sellPrice := currentPrice * (1 + float64(i)*g.GridSize/100)
+// RealizedProfit returns the total profit of all completed round trips
+func (g *GridStrategy) RealizedProfit() float64 {
+	return g.state.RealizedProfit
+}
+
+// OnCandle arms the grid around the candle close
+func (g *GridStrategy) OnCandle(candle model.Candle) []OrderIntent {
+	return g.update(candle.Close)
+}
+
+// OnTick arms the grid around the current price
+func (g *GridStrategy) OnTick(tick Tick) []OrderIntent {
+	return g.update(tick.Price)
+}
+
+// OnFill moves a cell to its counter-order: a filled buy places the sell of what it bought one level up,
+// and a filled sell completes the round trip and places the buy again. A sell that ended partly filled
+// records the part sold as a round trip and places the sell of the rest again.
+func (g *GridStrategy) OnFill(fill Fill) []OrderIntent {
+	side, i, ok := parseGridTag(fill.Tag)
+	if !ok || i >= len(g.state.Cells) {
+		return nil
+	}
+	cell := &g.state.Cells[i]
+
+	switch {
+	case side == "BUY" && cell.Status == cellBuying:
+		cell.Status = cellSelling
+		cell.Held = fill.Quantity
+		cell.EntryCost = fill.Price*fill.Quantity + fill.Fee
+		return []OrderIntent{g.sellIntent(i)}
 
-		// Place buy order
-		err = ex.PlaceOrder(ctx, symbol, "BUY", 0.01, buyPrice) // Example quantity
-		if err != nil {
-			return fmt.Errorf("failed to place buy order: %w", err)
+	case side == "SELL" && cell.Status == cellSelling:
+		held := cell.held()
+		entryCost := cell.EntryCost * math.Min(fill.Quantity/held, 1)
+		trip := GridRoundTrip{
+			Cell:      i,
+			BuyPrice:  cell.BuyPrice,
+			SellPrice: fill.Price,
+			Quantity:  fill.Quantity,
+			Profit:    fill.Price*fill.Quantity - fill.Fee - entryCost,
+			ClosedAt:  fill.Time,
 		}
+		g.state.RoundTrips = append(g.state.RoundTrips, trip)
+		if len(g.state.RoundTrips) > maxRoundTrips {
+			g.state.RoundTrips = g.state.RoundTrips[len(g.state.RoundTrips)-maxRoundTrips:]
+		}
+		g.state.RealizedProfit += trip.Profit
+		log.Printf("Grid round trip on cell %d: bought %.8f at %.8f, sold at %.8f, profit %.8f (total %.8f)",
+			i, trip.Quantity, trip.BuyPrice, trip.SellPrice, trip.Profit, g.state.RealizedProfit)
 
-		// Place sell order
-		err = ex.PlaceOrder(ctx, symbol, "SELL", 0.01, sellPrice) // Example quantity
-		if err != nil {
-			return fmt.Errorf("failed to place sell order: %w", err)
+		if rest := held - fill.Quantity; rest > held*dustFraction {
+			cell.Held, cell.EntryCost = rest, cell.EntryCost-entryCost
+			return []OrderIntent{g.sellIntent(i)}
 		}
+		cell.Status = cellBuying
+		cell.Held, cell.EntryCost = 0, 0
+		return []OrderIntent{g.buyIntent(i)}
 	}
-
 	return nil
 }
 
-// GetProfitPrediction predicts profit for grid strategy
-func (g *GridStrategy) GetProfitPrediction(symbol string, investment float64, timeframe string) (float64, float64, error) {
-	// Simple prediction: assume average profit per grid level
-	// This is a placeholder; real prediction would use historical data, ML, etc.
-	avgProfitPerLevel := 0.02 // 2% per level
-	totalProfit := float64(g.GridLevels) * avgProfitPerLevel * investment
-	percentage := totalProfit / investment * 100
+// update lays out the grid on the first price seen and arms a buy in every idle cell below the price
+func (g *GridStrategy) update(price float64) []OrderIntent {
+	if price <= 0 {
+		return nil
+	}
+	if len(g.state.Cells) == 0 {
+		g.layout(price)
+	}
+
+	var intents []OrderIntent
+	for i := range g.state.Cells {
+		cell := &g.state.Cells[i]
+		switch {
+		case cell.Status == cellIdle && cell.BuyPrice < price:
+			cell.Status = cellBuying
+			intents = append(intents, g.buyIntent(i))
+		case g.state.resume && cell.Status == cellBuying:
+			intents = append(intents, g.buyIntent(i))
+		case g.state.resume && cell.Status == cellSelling:
+			intents = append(intents, g.sellIntent(i))
+		}
+	}
+	g.state.resume = false
+	return intents
+}
+
+// layout computes the grid levels and splits the investment evenly across the cells
+func (g *GridStrategy) layout(price float64) {
+	lower, upper, cells := g.LowerPrice, g.UpperPrice, g.GridLevels
+	if lower == 0 && upper == 0 {
+		// Without explicit bounds, GridLevels levels of GridSize percent on each side of the price
+		lower = price * (1 - float64(g.GridLevels)*g.GridSize/100)
+		upper = price * (1 + float64(g.GridLevels)*g.GridSize/100)
+		cells = 2 * g.GridLevels
+	}
+
+	levels := make([]float64, cells+1)
+	for i := range levels {
+		if g.Spacing == SpacingGeometric {
+			levels[i] = lower * math.Pow(upper/lower, float64(i)/float64(cells))
+		} else {
+			levels[i] = lower + (upper-lower)*float64(i)/float64(cells)
+		}
+	}
 
-	if timeframe == "short" {
-		totalProfit *= 0.5 // Adjust for short term
-		percentage *= 0.5
+	perCell := g.TotalInvestment / float64(cells)
+	g.state.Cells = make([]GridCell, cells)
+	for i := range g.state.Cells {
+		g.state.Cells[i] = GridCell{
+			BuyPrice:  levels[i],
+			SellPrice: levels[i+1],
+			Quantity:  perCell / levels[i],
+			Status:    cellIdle,
+		}
 	}
+}
+
+func (g *GridStrategy) buyIntent(i int) OrderIntent {
+	cell := g.state.Cells[i]
+	return OrderIntent{Side: "BUY", Quantity: cell.Quantity, Price: cell.BuyPrice, Tag: fmt.Sprintf("grid-buy-%d", i)}
+}
 
-	return totalProfit, percentage, nil
+func (g *GridStrategy) sellIntent(i int) OrderIntent {
+	cell := g.state.Cells[i]
+	return OrderIntent{Side: "SELL", Quantity: cell.held(), Price: cell.SellPrice, Tag: fmt.Sprintf("grid-sell-%d", i)}
+}
+
+// held returns the base the cell has to sell; states saved before Held was kept hold the full Quantity
+func (c GridCell) held() float64 {
+	if c.Held > 0 {
+		return c.Held
+	}
+	return c.Quantity
+}
+
+// parseGridTag splits a tag such as "grid-buy-3" into its side and cell index
+func parseGridTag(tag string) (side string, cell int, ok bool) {
+	parts := strings.Split(tag, "-")
+	if len(parts) != 3 || parts[0] != "grid" {
+		return "", 0, false
+	}
+	cell, err := strconv.Atoi(parts[2])
+	if err != nil || cell < 0 {
+		return "", 0, false
+	}
+	return strings.ToUpper(parts[1]), cell, true
 }
 
 // GetSignals returns buy/sell signals for grid strategy
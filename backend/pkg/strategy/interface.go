@@ -1,27 +1,89 @@
 package strategy
 
 import (
-	"context"
+	"encoding/json"
+	"time"
 
-	"github.com/ratheeshkumar25/forex_bot/backend/pkg/exchange"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
 )
 
 // Signal represents a trading signal
 type Signal struct {
-	Type          string  // "BUY" or "SELL"
-	Price         float64 // Price level for the signal
-	TakeProfit    float64 // Take profit price level
-	StopLoss      float64 // Stop loss price level
-	Timeframe     string  // "short" or "long"
+	Type       string  // "BUY" or "SELL"
+	Price      float64 // Price level for the signal
+	TakeProfit float64 // Take profit price level
+	StopLoss   float64 // Stop loss price level
+	Timeframe  string  // "short" or "long"
 }
 
-// Strategy defines the interface for trading strategies
+// Tick is a single live price observation for the strategy's symbol
+type Tick struct {
+	Price float64
+	Time  time.Time
+}
+
+// OrderTypeOCO asks for a one-cancels-the-other bracket: a limit order at Price and a stop-loss-limit
+// order triggered at StopPrice with limit StopLimitPrice. Whichever leg fills reports the intent's Tag.
+const OrderTypeOCO = "OCO"
+
+// OrderIntent is an order a strategy wants placed for its symbol
+type OrderIntent struct {
+	Side           string  // "BUY" or "SELL"
+	Quantity       float64 // Amount of the base asset
+	Price          float64 // Limit price; unused for market orders
+	Tag            string  // Strategy-defined label echoed back on the resulting Fill
+	Type           string  // An exchange order type or OrderTypeOCO; LIMIT when empty
+	TimeInForce    string  // GTC, IOC or FOK for limit orders; GTC when empty
+	StopPrice      float64 // Trigger price of stop orders and of the stop leg of an OCO
+	StopLimitPrice float64 // Limit price of the stop leg of an OCO
+}
+
+// Fill reports the execution of an order placed from an OrderIntent.
+// An order that ended partly filled, such as an expired IOC order, reports the part that filled.
+type Fill struct {
+	Tag      string // Tag of the originating OrderIntent
+	Side     string
+	Quantity float64 // Filled quantity, which may be less than the intent's
+	Price    float64 // Average execution price
+	Fee      float64
+	Time     time.Time
+}
+
+// dustFraction is the share of an order's quantity below which what a partial fill leaves over is ignored,
+// since such remainders are usually below the exchange's minimum order size.
+const dustFraction = 0.01
+
+// Strategy defines the event-driven interface for trading strategies.
+// A strategy is initialised with its parameters, then receives market data and order fills
+// and answers with the orders it wants placed. It never talks to an exchange itself, so the
+// same code runs in live trading, paper trading and backtesting.
 type Strategy interface {
-	Execute(ctx context.Context, exchange exchange.Exchange, symbol string) error
-	GetProfitPrediction(symbol string, investment float64, timeframe string) (float64, float64, error)
+	Name() string
+	// Init configures the strategy from JSON parameters; nil keeps the defaults.
+	Init(params json.RawMessage) error
+	// OnCandle is called with each closed candle.
+	OnCandle(candle model.Candle) []OrderIntent
+	// OnTick is called with live prices between candles.
+	OnTick(tick Tick) []OrderIntent
+	// OnFill is called when one of the strategy's orders has been executed.
+	OnFill(fill Fill) []OrderIntent
+}
+
+// SignalProvider is implemented by strategies that can suggest entry levels without trading
+type SignalProvider interface {
 	GetSignals(symbol string, currentPrice float64) ([]Signal, error)
 }
 
+// Stateful is implemented by strategies whose state must survive a restart.
+// The state is persisted after every event and restored before the strategy resumes trading.
+type Stateful interface {
+	State() (json.RawMessage, error)
+	Restore(state json.RawMessage) error
+}
+
+// Factory creates a new strategy instance with default parameters
+type Factory func() Strategy
+
 // GridStrategy is defined in grid.go
 
 // DCA is defined in dca.go
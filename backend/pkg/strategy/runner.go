@@ -0,0 +1,358 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/exchange"
+	"github.com/ratheeshkumar25/forex_bot/backend/pkg/model"
+)
+
+// trackedOrder is the tag of a placed order, and for an OCO leg the ID of the other leg,
+// which is dropped once either of them fills.
+type trackedOrder struct {
+	tag     string
+	sibling string
+}
+
+// Runner connects a Strategy to an exchange for one symbol. It passes candles, ticks
+// and fills to the strategy and places the orders the strategy asks for.
+// A Runner is used the same way by live bots, paper trading and the backtesting engine.
+type Runner struct {
+	strat  Strategy
+	ex     exchange.Exchange
+	symbol string
+
+	// pollFills is set for exchanges that do not report fills; their orders are tracked by ID and polled in Sync
+	pollFills bool
+
+	mu      sync.Mutex
+	pending map[string]trackedOrder // Placed orders awaiting a paper fill by client order ID
+	live    map[string]trackedOrder // Placed orders awaiting a fill by exchange order ID, when polling
+	fills   []Fill                  // Fills received but not yet passed to the strategy
+	resting []*model.Order          // Open orders found by Resume, matched to the intents of the next placement
+}
+
+// restingTolerance is how far a resting order's price may be from an intent's, relative to it, for the two to match.
+// The exchange rounds prices to the symbol's tick size, so they rarely match exactly.
+const restingTolerance = 0.0005
+
+// NewRunner creates a new runner for strat trading symbol on ex.
+// Fills on the paper exchange must be passed in with NotifyPaperFill; on other exchanges
+// the runner polls its open orders when Sync is called.
+func NewRunner(strat Strategy, ex exchange.Exchange, symbol string) *Runner {
+	_, paper := exchange.Unwrap(ex).(*exchange.PaperExchange)
+	return &Runner{
+		strat:     strat,
+		ex:        ex,
+		symbol:    symbol,
+		pollFills: !paper,
+		pending:   make(map[string]trackedOrder),
+		live:      make(map[string]trackedOrder),
+	}
+}
+
+// Strategy returns the strategy driven by the runner.
+func (r *Runner) Strategy() Strategy {
+	return r.strat
+}
+
+// Resume lists the open orders on the runner's symbol before a restored strategy re-arms its orders.
+// The next orders the strategy asks for are matched by side, type and price to these resting orders, which are
+// then tracked instead of being placed a second time. Resting orders left unmatched are not touched.
+func (r *Runner) Resume(ctx context.Context) error {
+	orders, err := r.ex.ListOpenOrders(ctx, r.symbol)
+	if err != nil {
+		return fmt.Errorf("failed to list open orders: %w", err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.resting = orders
+	return nil
+}
+
+// HandleCandle passes a closed candle to the strategy and places the resulting orders.
+func (r *Runner) HandleCandle(ctx context.Context, candle model.Candle) error {
+	return r.place(ctx, r.strat.OnCandle(candle))
+}
+
+// HandleTick passes a live price to the strategy and places the resulting orders.
+func (r *Runner) HandleTick(ctx context.Context, tick Tick) error {
+	return r.place(ctx, r.strat.OnTick(tick))
+}
+
+// NotifyPaperFill queues a fill reported by the paper exchange if it belongs to one of the runner's orders.
+// It is safe to call from a PaperExchange.OnFill callback; the fill reaches the strategy on the next Drain.
+func (r *Runner) NotifyPaperFill(f exchange.PaperFill) {
+	if f.Symbol != r.symbol {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	pending, ok := r.pending[f.ClientOrderID]
+	if !ok {
+		return
+	}
+	delete(r.pending, f.ClientOrderID)
+	delete(r.pending, pending.sibling)
+	r.fills = append(r.fills, Fill{
+		Tag:      pending.tag,
+		Side:     f.Side,
+		Quantity: f.Quantity,
+		Price:    f.Price,
+		Fee:      f.Fee,
+		Time:     f.Time,
+	})
+}
+
+// Sync polls the exchange for the state of the runner's open orders and queues a fill for each one that has ended
+// with any quantity filled, including orders cancelled or expired after a partial fill, such as IOC orders.
+// Orders that ended are dropped, as is the other leg of a filled OCO.
+// It does nothing on the paper exchange, whose fills arrive through NotifyPaperFill.
+func (r *Runner) Sync(ctx context.Context) error {
+	r.mu.Lock()
+	ids := make([]string, 0, len(r.live))
+	for id := range r.live {
+		ids = append(ids, id)
+	}
+	r.mu.Unlock()
+
+	var errs []error
+	for _, id := range ids {
+		if !r.isLive(id) {
+			continue // The other leg of an OCO that filled earlier in this pass
+		}
+		order, err := r.ex.GetOrder(ctx, r.symbol, id)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to get order %s: %w", id, err))
+			continue
+		}
+		if order.IsOpen() {
+			continue
+		}
+
+		r.mu.Lock()
+		tracked := r.live[id]
+		delete(r.live, id)
+		_, siblingLive := r.live[tracked.sibling]
+		if order.FilledQuantity > 0 {
+			r.fills = append(r.fills, r.orderFill(tracked.tag, order))
+			delete(r.live, tracked.sibling)
+		}
+		r.mu.Unlock()
+		// An OCO leg ends unfilled when the other leg fills, which is reported when that leg is polled
+		if order.Status != model.OrderFilled && !siblingLive {
+			errs = append(errs, fmt.Errorf("order %s (%s) was %s on the exchange after filling %.8f of %.8f",
+				id, tracked.tag, order.Status, order.FilledQuantity, order.Quantity))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// orderFill converts a filled order into the Fill passed to the strategy.
+// Fees charged in the base asset are converted to the quote asset; fees in other assets are left out.
+func (r *Runner) orderFill(tag string, order *model.Order) Fill {
+	fee := 0.0
+	if base, quote, err := exchange.SplitSymbol(order.Symbol); err == nil {
+		switch order.FeeAsset {
+		case quote:
+			fee = order.Fee
+		case base:
+			fee = order.Fee * order.AvgPrice
+		}
+	}
+	return Fill{
+		Tag:      tag,
+		Side:     order.Side,
+		Quantity: order.FilledQuantity,
+		Price:    order.AvgPrice,
+		Fee:      fee,
+		Time:     order.UpdatedAt,
+	}
+}
+
+// Drain passes queued fills to the strategy and places the resulting orders,
+// until no fills remain. Orders that fill immediately are drained in the same call.
+func (r *Runner) Drain(ctx context.Context) error {
+	var errs []error
+	for {
+		r.mu.Lock()
+		fills := r.fills
+		r.fills = nil
+		r.mu.Unlock()
+		if len(fills) == 0 {
+			return errors.Join(errs...)
+		}
+
+		for _, f := range fills {
+			if err := r.place(ctx, r.strat.OnFill(f)); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+}
+
+// place submits order intents to the exchange, continuing past individual failures.
+func (r *Runner) place(ctx context.Context, intents []OrderIntent) error {
+	if len(intents) == 0 {
+		return nil
+	}
+	r.mu.Lock()
+	resting := r.resting
+	r.resting = nil
+	r.mu.Unlock()
+
+	var errs []error
+	for _, intent := range intents {
+		if strings.EqualFold(intent.Type, OrderTypeOCO) {
+			if err := r.placeOCO(ctx, intent); err != nil {
+				errs = append(errs, fmt.Errorf("failed to place %s OCO %s at %.8f/%.8f: %w", intent.Side, intent.Tag, intent.Price, intent.StopPrice, err))
+			}
+			continue
+		}
+		if order := takeResting(&resting, intent); order != nil {
+			r.adopt(intent.Tag, order)
+			continue
+		}
+
+		req := exchange.OrderRequest{
+			Symbol:        r.symbol,
+			Side:          intent.Side,
+			Type:          intent.Type,
+			Quantity:      intent.Quantity,
+			Price:         intent.Price,
+			StopPrice:     intent.StopPrice,
+			TimeInForce:   intent.TimeInForce,
+			ClientOrderID: exchange.NewClientOrderID(),
+		}
+		// Register before placing, since a marketable order can fill during PlaceOrder.
+		r.register(intent.Tag, req.ClientOrderID, "")
+
+		order, err := r.ex.PlaceOrder(ctx, req)
+		if err != nil {
+			r.forget(req.ClientOrderID)
+			errs = append(errs, fmt.Errorf("failed to place %s order %s at %.8f: %w", intent.Side, intent.Tag, intent.Price, err))
+			continue
+		}
+		if r.pollFills {
+			r.track(intent.Tag, order)
+		} else if !order.IsOpen() && order.Status != model.OrderFilled {
+			r.forget(req.ClientOrderID)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// takeResting removes the resting order that matches an intent from resting and returns it, or nil if none does.
+// Legs of OCOs are never matched, since they protect positions the strategy does not own.
+func takeResting(resting *[]*model.Order, intent OrderIntent) *model.Order {
+	orderType := strings.ToUpper(intent.Type)
+	if orderType == "" {
+		orderType = exchange.OrderTypeLimit
+	}
+	if intent.Price <= 0 || orderType == exchange.OrderTypeMarket {
+		return nil
+	}
+
+	best, bestDiff := -1, restingTolerance
+	for i, order := range *resting {
+		if order.OrderListID != "" || !strings.EqualFold(order.Side, intent.Side) || order.Type != orderType {
+			continue
+		}
+		if diff := math.Abs(order.Price-intent.Price) / intent.Price; diff <= bestDiff {
+			best, bestDiff = i, diff
+		}
+	}
+	if best < 0 {
+		return nil
+	}
+	order := (*resting)[best]
+	*resting = append((*resting)[:best], (*resting)[best+1:]...)
+	return order
+}
+
+// adopt tracks an order found resting on the exchange under tag, as if the runner had placed it.
+func (r *Runner) adopt(tag string, order *model.Order) {
+	if r.pollFills {
+		r.track(tag, order)
+		return
+	}
+	r.register(tag, order.ClientOrderID, "")
+}
+
+// placeOCO submits an OCO intent as a bracket whose legs both report the intent's tag.
+func (r *Runner) placeOCO(ctx context.Context, intent OrderIntent) error {
+	req := exchange.OCORequest{
+		Symbol:             r.symbol,
+		Side:               intent.Side,
+		Quantity:           intent.Quantity,
+		Price:              intent.Price,
+		StopPrice:          intent.StopPrice,
+		StopLimitPrice:     intent.StopLimitPrice,
+		LimitClientOrderID: exchange.NewClientOrderID(),
+		StopClientOrderID:  exchange.NewClientOrderID(),
+	}
+	r.register(intent.Tag, req.LimitClientOrderID, req.StopClientOrderID)
+	r.register(intent.Tag, req.StopClientOrderID, req.LimitClientOrderID)
+
+	orders, err := r.ex.PlaceOCO(ctx, req)
+	if err != nil {
+		r.forget(req.LimitClientOrderID)
+		r.forget(req.StopClientOrderID)
+		return err
+	}
+	if r.pollFills {
+		r.track(intent.Tag, orders...)
+	}
+	return nil
+}
+
+// register records the tag of an order about to be placed, so a paper fill can be traced back to it.
+func (r *Runner) register(tag, clientOrderID, sibling string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pending[clientOrderID] = trackedOrder{tag: tag, sibling: sibling}
+}
+
+// track moves placed orders from the pending tags to the orders polled by Sync,
+// queueing a fill straight away if one ended on placement with any quantity filled. Two orders are the legs of an OCO.
+func (r *Runner) track(tag string, orders ...*model.Order) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, order := range orders {
+		delete(r.pending, order.ClientOrderID)
+	}
+	for _, order := range orders {
+		if !order.IsOpen() && order.FilledQuantity > 0 {
+			r.fills = append(r.fills, r.orderFill(tag, order))
+			return
+		}
+	}
+	for i, order := range orders {
+		tracked := trackedOrder{tag: tag}
+		if len(orders) == 2 {
+			tracked.sibling = orders[1-i].ExchangeOrderID
+		}
+		r.live[order.ExchangeOrderID] = tracked
+	}
+}
+
+// isLive reports whether an order is still polled by Sync.
+func (r *Runner) isLive(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.live[id]
+	return ok
+}
+
+// forget removes the pending tag of an order that was never placed or that ended without filling.
+func (r *Runner) forget(clientOrderID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.pending, clientOrderID)
+}
@@ -221,11 +221,19 @@ document.addEventListener('DOMContentLoaded', function() {
 
     // Function to start WebSocket connection
     function startWebSocket(symbol, strategy) {
-        const wsUrl = `ws://localhost:8080/ws/price?symbol=${symbol}`;
+        const wsUrl = `ws://${window.location.host}/api/ws/price`;
         wsConnection = new WebSocket(wsUrl);
 
         wsConnection.onopen = function(event) {
             console.log('WebSocket connection opened');
+            wsConnection.send(JSON.stringify({ op: 'subscribe', id: 'ticker', channel: 'ticker', symbol: symbol }));
+            wsConnection.send(JSON.stringify({ op: 'subscribe', id: 'signals', channel: 'signals', symbol: symbol }));
+            // Own orders and positions are pushed once the connection is authenticated
+            if (token) {
+                wsConnection.send(JSON.stringify({ op: 'auth', id: 'auth', token: token }));
+                wsConnection.send(JSON.stringify({ op: 'subscribe', id: 'orders', channel: 'orders' }));
+                wsConnection.send(JSON.stringify({ op: 'subscribe', id: 'positions', channel: 'positions' }));
+            }
             // Add signals if strategy is selected
             if (strategy) {
                 addSignalDatasets(symbol, strategy);
@@ -234,11 +242,19 @@ document.addEventListener('DOMContentLoaded', function() {
 
         wsConnection.onmessage = function(event) {
             try {
-                const data = JSON.parse(event.data);
+                const frame = JSON.parse(event.data);
 
-                if (data.error) {
-                    throw new Error(data.error);
+                if (frame.type === 'error') {
+                    throw new Error(frame.error);
+                }
+                if (frame.type === 'data' && frame.event) {
+                    console.log(`Event ${frame.event}:`, frame.data);
+                    return;
+                }
+                if (frame.type !== 'data' || frame.channel !== 'ticker') {
+                    return;
                 }
+                const data = frame.data;
 
                 const now = new Date().toLocaleTimeString();
                 timeLabels.push(now);
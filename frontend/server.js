@@ -14,7 +14,7 @@ app.set('view engine', 'ejs');
 app.set('views', path.join(__dirname, 'views'));
 
 // Proxy middleware
-app.use('/api', createProxyMiddleware({ target: API_BASE_URL, changeOrigin: true, logLevel: 'debug' }));
+app.use('/api', createProxyMiddleware({ target: API_BASE_URL, changeOrigin: true, ws: true, logLevel: 'debug' }));
 
 // Routes
 app.get('/', (req, res) => {